@@ -24,6 +24,7 @@ import (
 	"strings"
 
 	"github.com/gravitational/shared-workflows/bot/internal/github"
+	"github.com/gravitational/shared-workflows/bot/internal/review"
 	"github.com/gravitational/trace"
 )
 
@@ -41,7 +42,7 @@ func (b *Bot) Assign(ctx context.Context) error {
 		return trace.Wrap(err)
 	}
 
-	reviewers, err := b.getReviewers(ctx, files)
+	reviewers, err := b.getReviewers(ctx, files, nil)
 	if err != nil {
 		return trace.Wrap(err)
 	}
@@ -61,7 +62,10 @@ func (b *Bot) Assign(ctx context.Context) error {
 	return nil
 }
 
-func (b *Bot) getReviewers(ctx context.Context, files []github.PullRequestFile) ([]string, error) {
+// getReviewers returns the reviewers to assign to the PR. Reviewers in avoid
+// are treated as the most loaded ones, so they are only picked if there is
+// no one else.
+func (b *Bot) getReviewers(ctx context.Context, files []github.PullRequestFile, avoid []string) ([]string, error) {
 	// If a backport PR was found, assign original reviewers. Otherwise fall
 	// through to normal assignment logic.
 	if isBackport(b.c.Environment.UnsafeBase) {
@@ -72,8 +76,62 @@ func (b *Bot) getReviewers(ctx context.Context, files []github.PullRequestFile)
 		log.Printf("Assign: Found backport PR, but failed to find original reviewers: %v. Falling through to normal assignment logic.", err)
 	}
 
-	changes := classifyChanges(b.c, files)
-	return b.c.Review.Get(b.c.Environment, changes, files), nil
+	sizes := b.loadSizeConfig(ctx, b.c.Environment.UnsafeBase)
+	changes := classifyChanges(b.c, files, sizes)
+
+	assignments, err := b.reviewAssignments(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	var load review.Load
+	if b.c.Review.LoadBalance() {
+		var err error
+		load, err = b.reviewerLoad(ctx)
+		if err != nil {
+			log.Printf("Assign: Failed to find reviewer load: %v. Falling back to random assignment.", err)
+		}
+	}
+	if len(avoid) > 0 {
+		if load == nil {
+			load = make(review.Load)
+		}
+		for _, reviewer := range avoid {
+			load[reviewer] += avoidLoad
+		}
+	}
+
+	return assignments.Get(b.c.Environment, changes, files, load), nil
+}
+
+// avoidLoad is added to the load of reviewers that should be avoided.
+const avoidLoad = 1 << 20
+
+// reviewerLoad returns the number of outstanding review requests each
+// reviewer has across all other open PRs in the repository. The requests are
+// read from the list of PRs, so finding the load takes a request per page of
+// PRs instead of one per PR.
+func (b *Bot) reviewerLoad(ctx context.Context) (review.Load, error) {
+	pulls, err := b.c.GitHub.ListPullRequests(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		"open")
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	load := make(review.Load)
+	for _, pull := range pulls {
+		if pull.Number == b.c.Environment.Number {
+			continue
+		}
+		for _, reviewer := range pull.RequestedReviewers {
+			load[reviewer]++
+		}
+	}
+
+	log.Printf("Assign: Found outstanding review requests across %v open PRs: %v.", len(pulls), load)
+	return load, nil
 }
 
 func (b *Bot) backportReviewers(ctx context.Context) ([]string, error) {
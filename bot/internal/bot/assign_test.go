@@ -174,3 +174,89 @@ func TestBackportReviewers(t *testing.T) {
 		})
 	}
 }
+
+// TestReviewerLoad checks that outstanding review requests are counted across
+// open PRs, excluding the PR being assigned.
+func TestReviewerLoad(t *testing.T) {
+	b := &Bot{
+		c: &Config{
+			Environment: &env.Environment{
+				Organization: "foo",
+				Repository:   "bar",
+				Number:       3,
+			},
+			GitHub: &fakeGithub{
+				pulls: []github.PullRequest{
+					{Number: 1, RequestedReviewers: []string{"alice", "bob"}},
+					{Number: 2, RequestedReviewers: []string{"alice"}},
+					{Number: 3, RequestedReviewers: []string{"alice", "bob", "carol"}},
+				},
+			},
+		},
+	}
+
+	load, err := b.reviewerLoad(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, review.Load{"alice": 2, "bob": 1}, load)
+}
+
+// TestReviewAssignmentsCodeOwners checks that code owners are read from the
+// base of the PR, not from its head.
+func TestReviewAssignmentsCodeOwners(t *testing.T) {
+	r, err := review.New(&review.Config{
+		CoreReviewers: map[string]review.Reviewer{
+			"alice": {Owner: true},
+			"bob":   {Owner: true},
+			"carol": {},
+			"dave":  {},
+		},
+		CloudReviewers:    map[string]review.Reviewer{},
+		CodeReviewersOmit: map[string]bool{},
+		DocsReviewers:     map[string]review.Reviewer{},
+		DocsReviewersOmit: map[string]bool{},
+		Admins:            []string{"admin"},
+	})
+	require.NoError(t, err)
+
+	e := &env.Environment{
+		Organization: "foo",
+		Repository:   env.TeleportRepo,
+		Number:       1,
+		Author:       "carol",
+	}
+	b := &Bot{
+		c: &Config{
+			Environment: e,
+			Review:      r,
+			GitHub: &fakeGithub{
+				pull: github.PullRequest{
+					UnsafeBase: github.Branch{Ref: "master", SHA: "base"},
+					UnsafeHead: github.Branch{Ref: "carol/auth", SHA: "head"},
+				},
+				contents: map[string][]byte{
+					"base:.github/CODEOWNERS": []byte("lib/auth/ @bob\n"),
+					"head:.github/CODEOWNERS": []byte("lib/auth/ @alice\n"),
+				},
+			},
+			CodeOwnersPath: ".github/CODEOWNERS",
+		},
+	}
+
+	assignments, err := b.reviewAssignments(context.Background())
+	require.NoError(t, err)
+
+	files := []github.PullRequestFile{{Name: "lib/auth/auth.go"}}
+	changes := env.Changes{Code: true, ApproverCount: env.DefaultApproverCount}
+	reviews := []github.Review{
+		{Author: "alice", State: review.Approved},
+		{Author: "dave", State: review.Approved},
+	}
+	err = assignments.CheckInternal(e, reviews, changes, files)
+	require.ErrorContains(t, err, "one of [bob] for lib/auth/auth.go")
+
+	// Without a CODEOWNERS file, code owners aren't required.
+	b.c.CodeOwnersPath = ".github/MISSING"
+	assignments, err = b.reviewAssignments(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, assignments.CheckInternal(e, reviews, changes, files))
+}
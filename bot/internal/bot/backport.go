@@ -19,9 +19,12 @@ package bot
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"log"
 	"net/url"
+	"os"
 	"os/exec"
 	"path"
 	"regexp"
@@ -66,20 +69,66 @@ func (b *Bot) Backport(ctx context.Context) error {
 		return nil
 	}
 
+	return trace.Wrap(b.backport(ctx, pull, branches, backportOptions{
+		draftOnConflict:     b.c.DraftBackportOnConflict,
+		waitForDependencies: b.c.WaitForBackportDependencies,
+	}))
+}
+
+// backportOptions control how backport branches are created.
+type backportOptions struct {
+	// retry force pushes over an existing backport branch, for example one
+	// holding the conflicts of a previous attempt. Branches with commits
+	// that weren't made by the bot are never overwritten.
+	retry bool
+	// draftOnConflict commits conflict markers and pushes the backport
+	// branch when cherry-picking fails, so a draft PR can be opened.
+	draftOnConflict bool
+	// waitForDependencies skips backports that depend on open backports of
+	// PRs referenced by the commits, instead of stacking them.
+	waitForDependencies bool
+	// onto is the backport branch a backport is stacked on instead of the
+	// release branch.
+	onto string
+}
+
+// backport creates backport branches of the Pull Request for each of the
+// branches and leaves a comment with links to create the backport Pull
+// Requests.
+func (b *Bot) backport(ctx context.Context, pull github.PullRequest, branches []string, opts backportOptions) error {
 	log.Printf("target branches: %v", strings.Join(branches, ", "))
 
-	// Get workflow logs URL, will be attached to any backport failure.
-	u, err := b.workflowLogsURL(ctx,
-		b.c.Environment.Organization,
-		b.c.Environment.Repository,
-		b.c.Environment.RunID)
-	if err != nil {
-		return trace.Wrap(err)
+	// Get workflow logs URL, will be attached to any backport failure. When
+	// the bot doesn't run in a workflow, failures link to the PR instead.
+	var err error
+	u := b.pullRequestURL(b.c.Environment.Number)
+	if b.c.Environment.RunID != 0 {
+		u, err = b.workflowLogsURL(ctx,
+			b.c.Environment.Organization,
+			b.c.Environment.Repository,
+			b.c.Environment.RunID)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+
+	// Backports of referenced PRs that are still open have to be merged
+	// first, the backports depend on them.
+	var open map[string][]openBackport
+	referenced := referencedPulls(pull.UnsafeCommitMessages, b.c.Environment.Number)
+	if len(referenced) > 0 {
+		open, err = b.openBackports(ctx)
+		if err != nil {
+			return trace.Wrap(err)
+		}
 	}
 
 	var rows []row
 
 	g := git
+	if b.c.GitToken != nil {
+		g = withGitToken(gitWithEnv, b.c.GitToken)
+	}
 	if b.c.Git != nil {
 		g = b.c.Git
 	}
@@ -89,6 +138,36 @@ func (b *Bot) Backport(ctx context.Context) error {
 	for _, base := range branches {
 		head := b.backportBranchName(base)
 
+		title := fmt.Sprintf("[%v] %v", strings.Trim(base, "branch/"), pull.UnsafeTitle)
+		labels := []string{NoChangelogLabel}
+		bodyText := fmt.Sprintf("Backport #%v to %v", b.c.Environment.Number, base)
+		if entries := b.getChangelogEntries(pull.UnsafeBody); len(entries) > 0 && !slices.Contains(pull.UnsafeLabels, NoChangelogLabel) {
+			bodyText += "\n\n"
+			labels = labels[:0]
+			for _, entry := range entries {
+				bodyText += fmt.Sprintf("%v\n", entry)
+			}
+		}
+
+		deps := findDependencies(open[base], referenced, opts.waitForDependencies)
+		if deps.Waiting {
+			log.Printf("Backport to %v waits for %v open backports.", base, len(deps.Chains))
+			rows = append(rows, row{
+				Branch:       base,
+				Waiting:      true,
+				Dependencies: deps.Chains,
+			})
+			continue
+		}
+
+		// Stacked backports are opened against the backport they depend on.
+		branchOpts := opts
+		branchOpts.onto = deps.onto()
+		target := base
+		if branchOpts.onto != "" {
+			target = branchOpts.onto
+		}
+
 		// Create and push git branch for backport to GitHub.
 		err := b.createBackportBranch(ctx,
 			b.c.Environment.Organization,
@@ -98,40 +177,56 @@ func (b *Bot) Backport(ctx context.Context) error {
 			pull,
 			head,
 			g,
+			branchOpts,
 		)
-		if err != nil {
+		var conflict *conflictError
+		switch {
+		case errors.As(err, &conflict):
+			log.Printf("Failed to create backport branch, %v.", conflict)
+			r := b.conflictRow(ctx, base, target, head, title, bodyText, conflict, u)
+			r.Dependencies = deps.Chains
+			rows = append(rows, r)
+			continue
+		case err != nil:
 			log.Printf("Failed to create backport branch:\n%v\n", trace.DebugReport(err))
 			rows = append(rows, row{
-				Branch: base,
-				Failed: true,
-				Link:   u,
+				Branch:       base,
+				Failed:       true,
+				Link:         u,
+				Dependencies: deps.Chains,
 			})
 			continue
 		}
 
-		labels := []string{NoChangelogLabel}
-		bodyText := fmt.Sprintf("Backport #%v to %v", b.c.Environment.Number, base)
-		if entries := b.getChangelogEntries(pull.UnsafeBody); len(entries) > 0 && !slices.Contains(pull.UnsafeLabels, NoChangelogLabel) {
-			bodyText += "\n\n"
-			labels = labels[:0]
-			for _, entry := range entries {
-				bodyText += fmt.Sprintf("%s%s\n", ChangelogPrefix, entry)
+		// A previous attempt may have opened a draft PR from the same
+		// branch, which now holds the retried backport.
+		if opts.retry {
+			if number, ok := b.findPullRequest(ctx, head); ok {
+				rows = append(rows, row{
+					Branch:       base,
+					Updated:      true,
+					Link:         b.pullRequestURL(number),
+					Dependencies: deps.Chains,
+				})
+				continue
 			}
 		}
 
 		rows = append(rows, row{
-			Branch: base,
-			Failed: false,
+			Branch:       base,
+			Failed:       false,
+			Dependencies: deps.Chains,
 			Link: url.URL{
 				Scheme: "https",
 				Host:   "github.com",
-				// Both base and head are safe to put into the URL: base has
-				// had the "branchPattern" regexp run against it and head is
-				// formed from base so an attacker can not control the path.
-				Path: path.Join(b.c.Environment.Organization, b.c.Environment.Repository, "compare", fmt.Sprintf("%v...%v", base, head)),
+				// Both target and head are safe to put into the URL: base has
+				// had the "branchPattern" regexp run against it, a stacked
+				// target is formed from validated parts and head is formed
+				// from base so an attacker can not control the path.
+				Path: path.Join(b.c.Environment.Organization, b.c.Environment.Repository, "compare", fmt.Sprintf("%v...%v", target, head)),
 				RawQuery: url.Values{
 					"expand": []string{"1"},
-					"title":  []string{fmt.Sprintf("[%v] %v", strings.Trim(base, "branch/"), pull.UnsafeTitle)},
+					"title":  []string{title},
 					"body":   []string{bodyText},
 					"labels": labels,
 				}.Encode(),
@@ -170,7 +265,8 @@ func (b *Bot) BackportLocal(ctx context.Context, branch string) error {
 		branch,
 		pull,
 		b.backportBranchName(branch),
-		gitDryRun)
+		gitDryRun,
+		backportOptions{})
 	if err != nil {
 		return trace.Wrap(err)
 	}
@@ -211,51 +307,233 @@ func findBranches(labels []string) []string {
 //
 // TODO(russjones): Refactor to use go-git (so similar git library) instead of
 // executing git from disk.
-func (b *Bot) createBackportBranch(ctx context.Context, organization string, repository string, number int, base string, pull github.PullRequest, newHead string, git func(...string) error) error {
+func (b *Bot) createBackportBranch(ctx context.Context, organization string, repository string, number int, base string, pull github.PullRequest, newHead string, git func(...string) (string, error), opts backportOptions) error {
 	log.Println("--> Backporting to", base, "<--")
 
-	if err := git("config", "--global", "user.name", "github-actions"); err != nil {
+	if _, err := git("config", "--global", "user.name", "github-actions"); err != nil {
 		log.Printf("Failed to set user.name: %v.", err)
 	}
-	if err := git("config", "--global", "user.email", "github-actions@goteleport.com"); err != nil {
+	if _, err := git("config", "--global", "user.email", backportCommitterEmail); err != nil {
 		log.Printf("Failed to set user.email: %v.", err)
 	}
 
+	// Stacked backports start from the backport they depend on.
+	start := base
+	if opts.onto != "" {
+		start = opts.onto
+	}
+
 	// Fetch the refs for the base branch and the Github PR.
-	if err := git("fetch", "origin", base, fmt.Sprintf("pull/%d/head", number)); err != nil {
+	if _, err := git("fetch", "origin", start, fmt.Sprintf("pull/%d/head", number)); err != nil {
 		return trace.Wrap(err)
 	}
 
+	if opts.retry {
+		if err := checkRetryBranch(git, start, newHead); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+
 	// Checkout the base branch.
-	if err := git("checkout", base); err != nil {
+	if _, err := git("checkout", start); err != nil {
 		return trace.Wrap(err)
 	}
 
 	// Checkout the new backport branch.
-	if err := git("checkout", "-b", newHead); err != nil {
+	if _, err := git("checkout", "-b", newHead); err != nil {
 		return trace.Wrap(err)
 	}
 
+	push := []string{"push", "origin", newHead}
+	if opts.retry {
+		push = []string{"push", "--force", "origin", newHead}
+	}
+
 	// Cherry-pick all commits from the PR to the backport branch.
-	for _, commit := range pull.Commits {
-		if err := git("cherry-pick", commit); err != nil {
-			// If cherry-pick fails with conflict, abort it, otherwise we
-			// won't be able to switch branch for the next backport.
-			if errAbrt := git("cherry-pick", "--abort"); errAbrt != nil {
-				return trace.NewAggregate(err, errAbrt)
+	for i, commit := range pull.Commits {
+		out, err := git("cherry-pick", commit)
+		if err == nil {
+			continue
+		}
+
+		conflict := &conflictError{commit: commit, output: out, remaining: pull.Commits[i+1:]}
+		if files, errDiff := git("diff", "--name-only", "--diff-filter=U"); errDiff == nil {
+			conflict.files = strings.Fields(files)
+		}
+		if len(conflict.files) > 0 && opts.draftOnConflict {
+			// Commit the conflict markers as they are so they can be
+			// resolved in a draft PR.
+			if err := commitConflicts(git, commit, push); err != nil {
+				return trace.Wrap(err)
 			}
-			return trace.Wrap(err)
+			conflict.pushed = true
+			return trace.Wrap(conflict)
+		}
+
+		// If cherry-pick fails with conflict, abort it, otherwise we
+		// won't be able to switch branch for the next backport.
+		if _, errAbrt := git("cherry-pick", "--abort"); errAbrt != nil {
+			return trace.NewAggregate(err, errAbrt)
 		}
+		if len(conflict.files) > 0 {
+			return trace.Wrap(conflict)
+		}
+		return trace.Wrap(err)
 	}
 
 	// Push the backport branch to Github.
-	if err := git("push", "origin", newHead); err != nil {
+	if _, err := git(push...); err != nil {
 		return trace.Wrap(err)
 	}
 
 	return nil
 }
 
+// commitConflicts commits the files of a failed cherry-pick, including
+// conflict markers, and pushes the backport branch.
+func commitConflicts(git func(...string) (string, error), commit string, push []string) error {
+	if _, err := git("add", "--all"); err != nil {
+		return trace.Wrap(err)
+	}
+	if _, err := git("commit", "--no-verify", "--message", fmt.Sprintf("Conflicts cherry-picking %v", commit)); err != nil {
+		return trace.Wrap(err)
+	}
+	_, err := git(push...)
+	return trace.Wrap(err)
+}
+
+// backportCommitterEmail is the email the bot commits backports with.
+const backportCommitterEmail = "github-actions@goteleport.com"
+
+// checkRetryBranch checks that the backport branch of a previous attempt
+// only has commits made by the bot, so a retry doesn't overwrite fixes
+// pushed to it, for example conflicts resolved in a draft PR.
+func checkRetryBranch(git func(...string) (string, error), start, head string) error {
+	// The branch doesn't exist if the previous attempt failed before
+	// pushing it.
+	if _, err := git("fetch", "origin", head); err != nil {
+		log.Printf("No backport branch %v to retry over: %v.", head, err)
+		return nil
+	}
+	committers, err := git("log", "--format=%ce", "FETCH_HEAD", "^origin/"+start)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	for _, committer := range strings.Fields(committers) {
+		if committer != backportCommitterEmail {
+			return trace.BadParameter("%v has commits from %v that a retry would overwrite, finish the backport in its PR or delete the branch first", head, committer)
+		}
+	}
+	return nil
+}
+
+// conflictError is returned when a commit can not be cherry-picked onto the
+// backport branch because of merge conflicts.
+type conflictError struct {
+	// commit is the commit that failed to cherry-pick.
+	commit string
+	// files are the files with conflicts.
+	files []string
+	// output is the output of the failed cherry-pick.
+	output string
+	// pushed is true if the conflicts were committed and pushed.
+	pushed bool
+	// remaining are the commits after commit that weren't cherry-picked.
+	remaining []string
+}
+
+// Error returns the commit and files that conflict.
+func (e *conflictError) Error() string {
+	return fmt.Sprintf("cherry-picking %v conflicts in %v", e.commit, strings.Join(e.files, ", "))
+}
+
+// conflictRow returns the backport result for a branch with conflicts. If
+// the conflicts were pushed, a draft PR is opened against target to resolve
+// them.
+func (b *Bot) conflictRow(ctx context.Context, base, target, head, title, body string, conflict *conflictError, logs url.URL) row {
+	r := row{
+		Branch:    base,
+		Failed:    true,
+		Link:      logs,
+		Commit:    conflict.commit,
+		Conflicts: conflict.files,
+		Output:    truncateOutput(conflict.output),
+	}
+	if !conflict.pushed {
+		return r
+	}
+	r.Remaining = conflict.remaining
+
+	body += "\n\nThis backport has conflicts that have to be resolved before merging."
+	if len(conflict.remaining) > 0 {
+		body += " The following commits weren't cherry-picked yet and have to be added after resolving them:\n"
+		for _, commit := range conflict.remaining {
+			body += fmt.Sprintf("\n- %v", commit)
+		}
+	}
+
+	// A retry updates the draft PR of a previous attempt.
+	number, ok := b.findPullRequest(ctx, head)
+	if !ok {
+		var err error
+		number, err = b.c.GitHub.CreatePullRequest(ctx,
+			b.c.Environment.Organization,
+			b.c.Environment.Repository,
+			title+" (conflicts)",
+			head,
+			target,
+			body,
+			true)
+		if err != nil {
+			log.Printf("Failed to open draft backport PR: %v.", err)
+			return r
+		}
+	}
+	r.Draft = true
+	r.Link = b.pullRequestURL(number)
+	return r
+}
+
+// findPullRequest returns the number of the open Pull Request from head.
+func (b *Bot) findPullRequest(ctx context.Context, head string) (int, bool) {
+	pulls, err := b.c.GitHub.ListPullRequests(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		"open")
+	if err != nil {
+		log.Printf("Failed to list open pull requests: %v.", err)
+		return 0, false
+	}
+	for _, pull := range pulls {
+		if pull.UnsafeHead.Ref == head {
+			return pull.Number, true
+		}
+	}
+	return 0, false
+}
+
+// pullRequestURL returns the URL of a Pull Request in the repository.
+func (b *Bot) pullRequestURL(number int) url.URL {
+	return url.URL{
+		Scheme: "https",
+		Host:   "github.com",
+		Path:   path.Join(b.c.Environment.Organization, b.c.Environment.Repository, "pull", strconv.Itoa(number)),
+	}
+}
+
+// maxOutputLines is the number of lines of git output included in the
+// backport comment.
+const maxOutputLines = 30
+
+// truncateOutput keeps the last maxOutputLines lines of output.
+func truncateOutput(output string) string {
+	lines := strings.Split(output, "\n")
+	if len(lines) <= maxOutputLines {
+		return output
+	}
+	return "...\n" + strings.Join(lines[len(lines)-maxOutputLines:], "\n")
+}
+
 // updatePullRequest will leave a comment on the Pull Request with the status
 // of backports.
 func (b *Bot) updatePullRequest(ctx context.Context, organization string, repository string, number int, d data) error {
@@ -295,21 +573,50 @@ func (b *Bot) workflowLogsURL(ctx context.Context, organization string, reposito
 	}, nil
 }
 
-// git will execute the "git" program on disk.
-func git(args ...string) error {
+// git will execute the "git" program on disk and return its output.
+func git(args ...string) (string, error) {
+	return gitWithEnv(nil, args...)
+}
+
+// gitWithEnv runs git with env added to the environment of the bot.
+func gitWithEnv(env []string, args ...string) (string, error) {
 	log.Println("Running:", "git", strings.Join(args, " "))
 	cmd := exec.Command("git", args...)
+	cmd.Env = append(os.Environ(), env...)
 	out, err := cmd.CombinedOutput()
+	output := string(bytes.TrimSpace(out))
 	if err != nil {
-		return trace.BadParameter("git failed: %s", string(bytes.TrimSpace(out)))
+		return output, trace.BadParameter("git failed: %s", output)
+	}
+	return output, nil
+}
+
+// withGitToken returns a git function that authenticates to GitHub with a
+// fresh token from token. The token is passed in the environment of the git
+// command rather than as an argument so it isn't logged, and replaces the
+// credentials that actions/checkout stores in the git config.
+func withGitToken(git func(env []string, args ...string) (string, error), token func() (string, error)) func(...string) (string, error) {
+	return func(args ...string) (string, error) {
+		t, err := token()
+		if err != nil {
+			return "", trace.Wrap(err, "getting git token")
+		}
+		const key = "http.https://github.com/.extraheader"
+		basic := base64.StdEncoding.EncodeToString([]byte("x-access-token:" + t))
+		return git([]string{
+			"GIT_CONFIG_COUNT=2",
+			"GIT_CONFIG_KEY_0=" + key,
+			"GIT_CONFIG_VALUE_0=",
+			"GIT_CONFIG_KEY_1=" + key,
+			"GIT_CONFIG_VALUE_1=AUTHORIZATION: basic " + basic,
+		}, args...)
 	}
-	return nil
 }
 
 // gitDryRun logs "git" commands in the console.
-func gitDryRun(args ...string) error {
+func gitDryRun(args ...string) (string, error) {
 	log.Println("Running: git", strings.Join(args, " "))
-	return nil
+	return "", nil
 }
 
 // data is injected into the template to render outcome of all backport
@@ -323,16 +630,54 @@ type data struct {
 	Rows []row
 }
 
+// Dependent returns the backports that depend on other open backports.
+func (d data) Dependent() []row {
+	var rows []row
+	for _, r := range d.Rows {
+		if len(r.Dependencies) > 0 {
+			rows = append(rows, r)
+		}
+	}
+	return rows
+}
+
 // row represents a single backport attempt.
 type row struct {
 	// Failed is used to indicate if this backport failed.
 	Failed bool
 
+	// Draft is true if a draft Pull Request with conflicts was opened.
+	Draft bool
+
+	// Updated is true if an existing backport Pull Request was updated.
+	Updated bool
+
 	// Branch is the name of the backport branch.
 	Branch string
 
 	// Link is a URL pointing to the created backport Pull Request.
 	Link url.URL
+
+	// Commit is the commit that failed to cherry-pick.
+	Commit string
+
+	// Conflicts are the files with conflicts.
+	Conflicts []string
+
+	// Remaining are the commits missing from a draft Pull Request because
+	// they come after Commit.
+	Remaining []string
+
+	// Output is the output of the failed cherry-pick.
+	Output string
+
+	// Waiting is true if the backport waits for its dependencies to be
+	// merged.
+	Waiting bool
+
+	// Dependencies are the chains of open backports the backport depends
+	// on.
+	Dependencies []backportChain
 }
 
 // table is a template that is written to the origin GitHub Pull Request with
@@ -343,8 +688,36 @@ const table = `
 | Branch | Result |
 |--------|--------|
 {{- range .Rows}}
-| {{.Branch}} | {{if .Failed}}[Failed]({{.Link}}){{else}}[Create PR]({{.Link}}){{end}} |
+| {{.Branch}} | {{if .Waiting}}Waiting for dependencies{{else if .Draft}}[Draft PR with conflicts]({{.Link}}){{else if .Failed}}[Failed]({{.Link}}){{else if .Updated}}[Updated PR]({{.Link}}){{else}}[Create PR]({{.Link}}){{end}} |
+{{- end}}
+{{- with .Dependent}}
+
+Backports stacked on or waiting for open backports of PRs referenced by the commits:
+{{range .}}{{$row := .}}{{range .Dependencies}}
+- {{$row.Branch}}: this backport{{range .}} → #{{.Number}} (backport of #{{.Original}}){{end}} → {{$row.Branch}}
+{{- end}}{{if .Waiting}}
+  Comment ` + "`/retry-backport {{.Branch}}`" + ` once the dependencies are merged.{{end}}{{end}}
+{{- end}}
+{{- range .Rows}}{{if .Conflicts}}
+
+<details><summary>Conflicts backporting to {{.Branch}}</summary>
+
+Cherry-picking {{.Commit}} conflicts in:
+{{range .Conflicts}}
+- {{.}}
+{{- end}}
+
+` + "```" + `
+{{.Output}}
+` + "```" + `
+{{with .Remaining}}
+The draft PR stops at the conflicting commit, cherry-pick these commits after resolving the conflicts:
+{{range .}}
+- {{.}}
 {{- end}}
+{{end}}
+{{if .Draft}}Resolve the conflicts in the draft PR, or on {{.Branch}} and comment ` + "`/retry-backport {{.Branch}}`" + ` to start over. Retries are refused once the draft PR has commits of its own.{{else}}Resolve the conflicts on {{.Branch}}, then comment ` + "`/retry-backport {{.Branch}}`" + ` to try again.{{end}}
+</details>{{end}}{{end}}
 `
 
 // branchPattern defines valid backport branch names.
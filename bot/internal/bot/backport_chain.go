@@ -0,0 +1,177 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bot
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"github.com/gravitational/trace"
+)
+
+var (
+	// backportHeadPattern matches the branches created by the bot for
+	// backports, capturing the number of the backported PR and the release
+	// branch.
+	backportHeadPattern = regexp.MustCompile(`^` + regexp.QuoteMeta(botBackportBranchPrefix) + `-(\d+)-(.+)$`)
+
+	// pullReferencePattern matches references to PRs in commit messages,
+	// for example "Fix the thing (#1234)".
+	pullReferencePattern = regexp.MustCompile(`(?:^|[\s(])#(\d+)\b`)
+)
+
+// openBackport is an open backport PR created by the bot.
+type openBackport struct {
+	// Number is the number of the backport PR.
+	Number int
+	// Original is the number of the backported PR.
+	Original int
+	// head is the backport branch.
+	head string
+	// base is the branch the backport PR is opened against, either the
+	// release branch or the backport branch it is stacked on.
+	base string
+}
+
+// backportChain is a chain of open backports to a release branch, each one
+// stacked on the next. The last backport is opened against the release
+// branch.
+type backportChain []openBackport
+
+// backportDependencies are the open backports a backport to a branch
+// depends on.
+type backportDependencies struct {
+	// Chains are the chains of open backports ending in a backport of a PR
+	// referenced by the commits being backported.
+	Chains []backportChain
+	// Waiting is true if the backport waits for the dependencies to be
+	// merged instead of being stacked on them.
+	Waiting bool
+}
+
+// onto returns the backport branch to stack the backport on, or an empty
+// string if the backport is created from the release branch.
+func (d backportDependencies) onto() string {
+	if d.Waiting || len(d.Chains) == 0 {
+		return ""
+	}
+	return d.Chains[0][0].head
+}
+
+// referencedPulls returns the PRs referenced by commit messages, except for
+// the PR the commits belong to.
+func referencedPulls(messages []string, number int) []int {
+	var numbers []int
+	for _, message := range messages {
+		for _, match := range pullReferencePattern.FindAllStringSubmatch(message, -1) {
+			n, err := strconv.Atoi(match[1])
+			if err != nil || n == number || slices.Contains(numbers, n) {
+				continue
+			}
+			numbers = append(numbers, n)
+		}
+	}
+	return numbers
+}
+
+// openBackports returns the open backport PRs created by the bot, keyed by
+// the release branch they backport to.
+func (b *Bot) openBackports(ctx context.Context) (map[string][]openBackport, error) {
+	pulls, err := b.c.GitHub.ListPullRequests(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		"open")
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	backports := make(map[string][]openBackport)
+	for _, pull := range pulls {
+		// Backports are only stacked on branches pushed by the bot.
+		if pull.Fork {
+			continue
+		}
+		match := backportHeadPattern.FindStringSubmatch(pull.UnsafeHead.Ref)
+		if match == nil || !branchPattern.MatchString(match[2]) {
+			continue
+		}
+		original, err := strconv.Atoi(match[1])
+		if err != nil {
+			continue
+		}
+		backports[match[2]] = append(backports[match[2]], openBackport{
+			Number:   pull.Number,
+			Original: original,
+			// The head is formed from validated parts so it is safe to
+			// use in git commands and URLs.
+			head: fmt.Sprintf("%s-%v-%v", botBackportBranchPrefix, original, match[2]),
+			base: pull.UnsafeBase.Ref,
+		})
+	}
+	return backports, nil
+}
+
+// findDependencies returns the chains of open backports to one release
+// branch that end in a backport of a referenced PR. The backport can only
+// be stacked if there is a single chain, otherwise it has to wait for the
+// dependencies to be merged.
+func findDependencies(open []openBackport, referenced []int, wait bool) backportDependencies {
+	byHead := make(map[string]openBackport, len(open))
+	for _, backport := range open {
+		byHead[backport.head] = backport
+	}
+
+	// Backports of referenced PRs, along with the backports they are
+	// stacked on.
+	var deps []openBackport
+	for _, backport := range open {
+		if !slices.Contains(referenced, backport.Original) {
+			continue
+		}
+		for ok := true; ok; backport, ok = byHead[backport.base] {
+			if slices.Contains(deps, backport) {
+				break
+			}
+			deps = append(deps, backport)
+		}
+	}
+
+	// Every dependency that no other dependency is stacked on starts a
+	// chain.
+	var chains []backportChain
+	for _, tip := range deps {
+		if slices.ContainsFunc(deps, func(d openBackport) bool { return d.base == tip.head }) {
+			continue
+		}
+		chain := backportChain{tip}
+		for next, ok := byHead[tip.base]; ok && !slices.Contains(chain, next); next, ok = byHead[next.base] {
+			chain = append(chain, next)
+		}
+		chains = append(chains, chain)
+	}
+	slices.SortFunc(chains, func(a, b backportChain) int {
+		return a[0].Number - b[0].Number
+	})
+
+	return backportDependencies{
+		Chains:  chains,
+		Waiting: len(chains) > 0 && (wait || len(chains) > 1),
+	}
+}
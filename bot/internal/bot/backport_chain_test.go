@@ -0,0 +1,155 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/shared-workflows/bot/internal/env"
+	"github.com/gravitational/shared-workflows/bot/internal/github"
+)
+
+func TestReferencedPulls(t *testing.T) {
+	messages := []string{
+		"Add the thing (#40)",
+		"Fix the thing\n\nFollow up to #38 and #40, see issue#12",
+		"Backport of #42",
+	}
+	require.Equal(t, []int{40, 38}, referencedPulls(messages, 42))
+}
+
+func TestFindDependencies(t *testing.T) {
+	// #101 backports #40 and is stacked on #99, which backports #38.
+	open := []openBackport{
+		{Number: 99, Original: 38, head: "bot/backport-38-branch/v7", base: "branch/v7"},
+		{Number: 101, Original: 40, head: "bot/backport-40-branch/v7", base: "bot/backport-38-branch/v7"},
+		{Number: 103, Original: 41, head: "bot/backport-41-branch/v7", base: "branch/v7"},
+	}
+
+	tests := []struct {
+		desc       string
+		referenced []int
+		wait       bool
+		chains     []backportChain
+		waiting    bool
+		onto       string
+	}{
+		{
+			desc:       "no references",
+			referenced: nil,
+		},
+		{
+			desc:       "reference without open backport",
+			referenced: []int{12},
+		},
+		{
+			desc:       "stacked on chain",
+			referenced: []int{40},
+			chains:     []backportChain{{open[1], open[0]}},
+			onto:       "bot/backport-40-branch/v7",
+		},
+		{
+			desc:       "referenced backport and its base are one chain",
+			referenced: []int{38, 40},
+			chains:     []backportChain{{open[1], open[0]}},
+			onto:       "bot/backport-40-branch/v7",
+		},
+		{
+			desc:       "waits for separate chains",
+			referenced: []int{40, 41},
+			chains:     []backportChain{{open[1], open[0]}, {open[2]}},
+			waiting:    true,
+		},
+		{
+			desc:       "waits when configured",
+			referenced: []int{41},
+			wait:       true,
+			chains:     []backportChain{{open[2]}},
+			waiting:    true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			deps := findDependencies(open, test.referenced, test.wait)
+			require.Equal(t, test.chains, deps.Chains)
+			require.Equal(t, test.waiting, deps.Waiting)
+			require.Equal(t, test.onto, deps.onto())
+		})
+	}
+}
+
+func TestBackportDependencies(t *testing.T) {
+	gh := &fakeGithub{
+		pull: github.PullRequest{
+			Commits:              []string{"abc123"},
+			UnsafeCommitMessages: []string{"Use the new API from #40\n\nFix flake from #41"},
+			UnsafeTitle:          "Best PR",
+		},
+		pulls: []github.PullRequest{
+			{Number: 101, UnsafeHead: github.Branch{Ref: "bot/backport-40-branch/v7"}, UnsafeBase: github.Branch{Ref: "branch/v7"}},
+			{Number: 102, UnsafeHead: github.Branch{Ref: "bot/backport-40-branch/v6"}, UnsafeBase: github.Branch{Ref: "branch/v6"}},
+			{Number: 103, UnsafeHead: github.Branch{Ref: "bot/backport-41-branch/v6"}, UnsafeBase: github.Branch{Ref: "branch/v6"}},
+			// Branches from forks are never stacked on.
+			{Number: 104, UnsafeHead: github.Branch{Ref: "bot/backport-40-branch/v5"}, UnsafeBase: github.Branch{Ref: "branch/v5"}, Fork: true},
+		},
+		jobs: []github.Job{{Name: "Job1", ID: 1}},
+	}
+
+	var calls [][]string
+	b := &Bot{
+		c: &Config{
+			Environment: &env.Environment{
+				Organization: "foo",
+				Author:       "dev",
+				Repository:   "bar",
+				Number:       42,
+			},
+			GitHub: gh,
+			Git: func(args ...string) (string, error) {
+				calls = append(calls, args)
+				return "", nil
+			},
+		},
+	}
+
+	err := b.backport(context.Background(), gh.pull, []string{"branch/v7", "branch/v6", "branch/v5"}, backportOptions{})
+	require.NoError(t, err)
+	require.Contains(t, calls, []string{"checkout", "bot/backport-40-branch/v7"})
+	require.Contains(t, calls, []string{"checkout", "branch/v5"})
+	require.NotContains(t, calls, []string{"checkout", "branch/v6"})
+
+	require.Len(t, gh.comments, 1)
+	require.Equal(t, `
+@dev See the table below for backport results.
+
+| Branch | Result |
+|--------|--------|
+| branch/v7 | [Create PR](https://github.com/foo/bar/compare/bot/backport-40-branch/v7...bot/backport-42-branch/v7?body=Backport+%2342+to+branch%2Fv7&expand=1&labels=no-changelog&title=%5Bv7%5D+Best+PR) |
+| branch/v6 | Waiting for dependencies |
+| branch/v5 | [Create PR](https://github.com/foo/bar/compare/branch/v5...bot/backport-42-branch/v5?body=Backport+%2342+to+branch%2Fv5&expand=1&labels=no-changelog&title=%5Bv5%5D+Best+PR) |
+
+Backports stacked on or waiting for open backports of PRs referenced by the commits:
+
+- branch/v7: this backport → #101 (backport of #40) → branch/v7
+- branch/v6: this backport → #102 (backport of #40) → branch/v6
+- branch/v6: this backport → #103 (backport of #41) → branch/v6
+  Comment `+"`/retry-backport branch/v6`"+` once the dependencies are merged.
+`, gh.comments[0].Body)
+}
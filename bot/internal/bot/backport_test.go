@@ -18,8 +18,13 @@ package bot
 
 import (
 	"context"
+	"encoding/base64"
+	"os"
+	"strconv"
+	"strings"
 	"testing"
 
+	"github.com/gravitational/trace"
 	"github.com/stretchr/testify/require"
 
 	"github.com/gravitational/shared-workflows/bot/internal/env"
@@ -147,3 +152,180 @@ func TestBackport(t *testing.T) {
 		})
 	}
 }
+
+// conflictingGit returns a git func that fails to cherry-pick and reports
+// conflicts in lib/auth/auth.go.
+func conflictingGit(calls *[][]string) func(...string) (string, error) {
+	return func(args ...string) (string, error) {
+		*calls = append(*calls, args)
+		switch {
+		case args[0] == "cherry-pick" && len(args) == 2 && args[1] != "--abort":
+			return "CONFLICT (content): Merge conflict in lib/auth/auth.go", trace.BadParameter("git failed")
+		case args[0] == "diff":
+			return "lib/auth/auth.go", nil
+		}
+		return "", nil
+	}
+}
+
+func TestBackportConflicts(t *testing.T) {
+	tests := []struct {
+		desc  string
+		opts  backportOptions
+		pulls []github.PullRequest
+		// committers are the committers of the existing backport branch.
+		committers string
+		// refused is true if the retry must not overwrite the branch.
+		refused bool
+		result  string
+		lastGit []string
+		// pullCount is the number of open PRs after the backport.
+		pullCount int
+	}{
+		{
+			desc:    "abort",
+			result:  "| branch/v7 | [Failed](https://github.com/foo/bar/runs/1?check_suite_focus=true) |",
+			lastGit: []string{"cherry-pick", "--abort"},
+		},
+		{
+			desc:      "draft",
+			opts:      backportOptions{draftOnConflict: true},
+			result:    "| branch/v7 | [Draft PR with conflicts](https://github.com/foo/bar/pull/100) |",
+			lastGit:   []string{"push", "origin", "bot/backport-42-branch/v7"},
+			pullCount: 1,
+		},
+		{
+			desc: "retry updates existing draft",
+			opts: backportOptions{retry: true, draftOnConflict: true},
+			pulls: []github.PullRequest{
+				{Number: 7, UnsafeHead: github.Branch{Ref: "bot/backport-42-branch/v7"}},
+			},
+			committers: backportCommitterEmail + "\n" + backportCommitterEmail,
+			result:     "| branch/v7 | [Draft PR with conflicts](https://github.com/foo/bar/pull/7) |",
+			lastGit:    []string{"push", "--force", "origin", "bot/backport-42-branch/v7"},
+			pullCount:  1,
+		},
+		{
+			desc: "retry keeps fixes pushed to the draft",
+			opts: backportOptions{retry: true, draftOnConflict: true},
+			pulls: []github.PullRequest{
+				{Number: 7, UnsafeHead: github.Branch{Ref: "bot/backport-42-branch/v7"}},
+			},
+			committers: backportCommitterEmail + "\ndev@example.com",
+			refused:    true,
+			result:     "| branch/v7 | [Failed](https://github.com/foo/bar/runs/1?check_suite_focus=true) |",
+			lastGit:    []string{"log", "--format=%ce", "FETCH_HEAD", "^origin/branch/v7"},
+			pullCount:  1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			gh := &fakeGithub{
+				pull: github.PullRequest{
+					Commits:     []string{"abc123", "def456"},
+					UnsafeTitle: "Best PR",
+				},
+				pulls: test.pulls,
+				jobs:  []github.Job{{Name: "Job1", ID: 1}},
+			}
+			var calls [][]string
+			b := &Bot{
+				c: &Config{
+					Environment: &env.Environment{
+						Organization: "foo",
+						Author:       "dev",
+						Repository:   "bar",
+						Number:       42,
+						RunID:        7,
+					},
+					GitHub: gh,
+					Git: func(args ...string) (string, error) {
+						if args[0] == "log" {
+							calls = append(calls, args)
+							return test.committers, nil
+						}
+						return conflictingGit(&calls)(args...)
+					},
+				},
+			}
+
+			err := b.backport(context.Background(), gh.pull, []string{"branch/v7"}, test.opts)
+			require.NoError(t, err)
+			require.Equal(t, test.lastGit, calls[len(calls)-1])
+
+			require.Len(t, gh.comments, 1)
+			body := gh.comments[0].Body
+			require.Contains(t, body, test.result)
+			require.Len(t, gh.pulls, test.pullCount)
+			if test.refused {
+				require.NotContains(t, body, "Cherry-picking")
+				return
+			}
+			require.Contains(t, body, "Cherry-picking abc123 conflicts in:\n\n- lib/auth/auth.go")
+			require.Contains(t, body, "CONFLICT (content): Merge conflict in lib/auth/auth.go")
+			require.Contains(t, body, "`/retry-backport branch/v7`")
+			// Drafts stop at the conflict and list the commits after it.
+			if test.opts.draftOnConflict {
+				require.Contains(t, body, "cherry-pick these commits after resolving the conflicts:\n\n- def456")
+				if len(test.pulls) == 0 {
+					require.Contains(t, gh.pulls[0].UnsafeBody, "- def456")
+				}
+			} else {
+				require.NotContains(t, body, "def456")
+			}
+		})
+	}
+}
+
+func TestTruncateOutput(t *testing.T) {
+	require.Equal(t, "a\nb", truncateOutput("a\nb"))
+
+	lines := make([]string, maxOutputLines+5)
+	for i := range lines {
+		lines[i] = strconv.Itoa(i)
+	}
+	out := truncateOutput(strings.Join(lines, "\n"))
+	require.True(t, strings.HasPrefix(out, "...\n5\n"))
+	require.True(t, strings.HasSuffix(out, "\n"+strconv.Itoa(len(lines)-1)))
+}
+
+func TestWithGitToken(t *testing.T) {
+	for _, k := range []string{"GIT_CONFIG_COUNT", "GIT_CONFIG_KEY_0", "GIT_CONFIG_VALUE_0", "GIT_CONFIG_KEY_1", "GIT_CONFIG_VALUE_1"} {
+		t.Setenv(k, "")
+	}
+
+	tokens := []string{"tok1", "tok2"}
+	token := func() (string, error) {
+		t := tokens[0]
+		tokens = tokens[1:]
+		return t, nil
+	}
+	var headers []string
+	g := withGitToken(func(env []string, args ...string) (string, error) {
+		require.Contains(t, env, "GIT_CONFIG_COUNT=2")
+		require.Contains(t, env, "GIT_CONFIG_VALUE_0=")
+		require.Empty(t, os.Getenv("GIT_CONFIG_COUNT"), "the environment of the bot must not change")
+		for _, kv := range env {
+			if header, ok := strings.CutPrefix(kv, "GIT_CONFIG_VALUE_1="); ok {
+				headers = append(headers, header)
+			}
+		}
+		return strings.Join(args, " "), nil
+	}, token)
+
+	// Every command uses a fresh token.
+	for range 2 {
+		out, err := g("push", "origin", "branch")
+		require.NoError(t, err)
+		require.Equal(t, "push origin branch", out)
+	}
+	require.Equal(t, []string{
+		"AUTHORIZATION: basic " + base64.StdEncoding.EncodeToString([]byte("x-access-token:tok1")),
+		"AUTHORIZATION: basic " + base64.StdEncoding.EncodeToString([]byte("x-access-token:tok2")),
+	}, headers)
+
+	_, err := withGitToken(gitWithEnv, func() (string, error) {
+		return "", trace.BadParameter("no token")
+	})("status")
+	require.Error(t, err)
+}
@@ -2,15 +2,17 @@ package bot
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log"
-	"os"
+	"maps"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
 	"unicode/utf8"
 
@@ -21,34 +23,176 @@ const (
 	// skipBloatCheckPrefix the comment prefix to use in order to
 	// skip particular artifacts from the bloat check.
 	skipBloatCheckPrefix = "/excludebloat"
-	// warnThreshold is the amount of MB that a binary may increase and
-	// only a warning is logged
-	warnThreshold = 1
-	// errorThreshold is the amount of MB that a binary cannot exceed without
-	// failing the check.
-	errorThreshold = 3
+	// maxBreakdownRows is the number of sections and modules with the
+	// largest change listed for each artifact.
+	maxBreakdownRows = 10
 )
 
+// defaultBloatThresholds are used for artifacts without thresholds in the
+// bloat config.
+var defaultBloatThresholds = BloatThresholds{
+	// Warn is the amount that a binary may increase and only a warning is
+	// logged.
+	Warn: &Threshold{Bytes: 1 << 20},
+	// Error is the amount that a binary cannot exceed without failing the
+	// check.
+	Error: &Threshold{Bytes: 3 << 20},
+}
+
+// BloatConfig configures the thresholds of the bloat check.
+type BloatConfig struct {
+	// Default are the thresholds of artifacts without thresholds of their
+	// own. Unset thresholds use the built-in defaults.
+	Default BloatThresholds `json:"default"`
+	// Artifacts are the thresholds by artifact name. Unset thresholds use
+	// Default.
+	Artifacts map[string]BloatThresholds `json:"artifacts,omitempty"`
+}
+
+// BloatThresholds are the increases in size of an artifact that are
+// reported as a warning or fail the check.
+type BloatThresholds struct {
+	// Warn is the increase that is reported as a warning.
+	Warn *Threshold `json:"warn,omitempty"`
+	// Error is the increase that fails the check.
+	Error *Threshold `json:"error,omitempty"`
+}
+
+// Threshold is an increase in size, either in bytes or as a percentage of
+// the base size. In JSON it is either a number of bytes or a string like
+// "512KB", "1.5MB" or "2%".
+type Threshold struct {
+	// Bytes is the increase in bytes.
+	Bytes int64
+	// Percent is the increase as a percentage of the base size, used
+	// instead of Bytes when set.
+	Percent float64
+}
+
+// ParseBloatConfig parses a JSON bloat config.
+func ParseBloatConfig(data []byte) (*BloatConfig, error) {
+	var c BloatConfig
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &c, nil
+}
+
+// thresholds returns the thresholds of an artifact.
+func (c *BloatConfig) thresholds(artifact string) BloatThresholds {
+	t := defaultBloatThresholds
+	if c == nil {
+		return t
+	}
+	for _, override := range []BloatThresholds{c.Default, c.Artifacts[artifact]} {
+		if override.Warn != nil {
+			t.Warn = override.Warn
+		}
+		if override.Error != nil {
+			t.Error = override.Error
+		}
+	}
+	return t
+}
+
+// exceeded returns true if diff exceeds the threshold for an artifact with
+// the base size.
+func (t Threshold) exceeded(base, diff int64) bool {
+	if t.Percent > 0 {
+		return base > 0 && float64(diff)*100 > t.Percent*float64(base)
+	}
+	return diff > t.Bytes
+}
+
+// String returns the threshold in the format it is parsed from.
+func (t Threshold) String() string {
+	if t.Percent > 0 {
+		return strconv.FormatFloat(t.Percent, 'f', -1, 64) + "%"
+	}
+	return strconv.FormatInt(t.Bytes, 10)
+}
+
+// sizeUnits are the units accepted in thresholds, "B" last so "MB" isn't
+// parsed as "B".
+var sizeUnits = []struct {
+	suffix string
+	bytes  float64
+}{
+	{"KB", 1 << 10},
+	{"MB", 1 << 20},
+	{"GB", 1 << 30},
+	{"B", 1},
+}
+
+// UnmarshalJSON parses a threshold from a number of bytes or a string with a
+// unit or percent sign.
+func (t *Threshold) UnmarshalJSON(data []byte) error {
+	var bytes int64
+	if err := json.Unmarshal(data, &bytes); err == nil {
+		*t = Threshold{Bytes: bytes}
+		return trace.Wrap(t.check())
+	}
+
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return trace.BadParameter("threshold must be a number of bytes or a string, got %s", data)
+	}
+	value = strings.ToUpper(strings.TrimSpace(value))
+
+	if number, ok := strings.CutSuffix(value, "%"); ok {
+		percent, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+		if err != nil {
+			return trace.BadParameter("invalid threshold %q", value)
+		}
+		*t = Threshold{Percent: percent}
+		return trace.Wrap(t.check())
+	}
+
+	multiplier := float64(1)
+	for _, unit := range sizeUnits {
+		if number, ok := strings.CutSuffix(value, unit.suffix); ok {
+			value, multiplier = strings.TrimSpace(number), unit.bytes
+			break
+		}
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return trace.BadParameter("invalid threshold %q", value)
+	}
+	*t = Threshold{Bytes: int64(number * multiplier)}
+	return trace.Wrap(t.check())
+}
+
+// check validates the threshold.
+func (t Threshold) check() error {
+	if t.Bytes < 0 || t.Percent < 0 {
+		return trace.BadParameter("threshold %v must not be negative", t)
+	}
+	return nil
+}
+
 // CalculateBinarySizes determines the size of provided artifacts and outputs a map of
-// artifacts to size in JSON like the following. The sizes emitted are in MB.
+// artifacts to size in JSON like the following. The sizes emitted are in bytes. ELF
+// binaries also include their size by section and, for Go binaries that aren't
+// stripped, by module.
 //
 //	{
 //	    "one": 123,
 //	    "two": 456,
-//	    "three": 789
+//	    "three": {"size": 789, "sections": {".text": 456}, "modules": {"std": 123}}
 //	}
 func (b *Bot) CalculateBinarySizes(ctx context.Context, build string, artifacts []string, out io.Writer) error {
-	stats := make(map[string]int64, len(artifacts))
+	stats := make(map[string]artifactStats, len(artifacts))
 	for _, artifact := range artifacts {
 		if ctx.Err() != nil {
 			return trace.Wrap(ctx.Err())
 		}
 
-		info, err := os.Stat(filepath.Join(build, artifact))
+		artifactStats, err := readArtifactStats(filepath.Join(build, artifact))
 		if err != nil {
 			return trace.Wrap(err)
 		}
-		stats[artifact] = info.Size()
+		stats[artifact] = artifactStats
 	}
 
 	return trace.Wrap(json.NewEncoder(out).Encode(stats))
@@ -57,25 +201,26 @@ func (b *Bot) CalculateBinarySizes(ctx context.Context, build string, artifacts
 // BloatCheck determines if any of the provided artifacts have increased by comparing
 // the built artifacts from the current branch against the artifact sizes of the base
 // branch. An error is returned if the artifacts in the current directory exceed the
-// artifact size present in the base statistics by more than the errorThreshold. The
-// baseStats should in form of the JSON map emitted from CalculateBinarySizes.
+// artifact size present in the base statistics by more than the error threshold of
+// the artifact. The baseStats should in form of the JSON map emitted from
+// CalculateBinarySizes.
 func (b *Bot) BloatCheck(ctx context.Context, baseStats, current string, artifacts []string, out io.Writer) error {
-	var stats map[string]int64
+	var stats map[string]artifactStats
 	if err := json.Unmarshal([]byte(baseStats), &stats); err != nil {
 		return trace.Wrap(err)
 	}
 
-	log.Printf("Base stats provided: %v", stats)
+	log.Printf("Base stats provided for %v artifacts", len(stats))
 
 	skip, err := b.skipItems(ctx, skipBloatCheckPrefix)
 	if err != nil {
 		return trace.Wrap(err)
 	}
 
-	baseLookup := func(artifact string) (int64, error) {
+	baseLookup := func(artifact string) (artifactStats, error) {
 		size, ok := stats[artifact]
 		if !ok {
-			return 0, trace.NotFound("no size provided %s found", artifact)
+			return artifactStats{}, trace.NotFound("no size provided %s found", artifact)
 		}
 
 		return size, nil
@@ -83,6 +228,7 @@ func (b *Bot) BloatCheck(ctx context.Context, baseStats, current string, artifac
 
 	var failure bool
 	output := make(map[string]result, len(artifacts))
+	var breakdowns []breakdown
 	for _, artifact := range artifacts {
 		select {
 		case <-ctx.Done():
@@ -95,31 +241,41 @@ func (b *Bot) BloatCheck(ctx context.Context, baseStats, current string, artifac
 			return err
 		}
 
-		log.Printf("artifact %s has a current size of %d", artifact, stats.currentSize)
+		log.Printf("artifact %s has a current size of %d bytes", artifact, stats.current.Size)
 
+		thresholds := b.c.Bloat.thresholds(artifact)
 		status := "✅"
 		if slices.Contains(skip, artifact) {
 			status += " skipped by admin"
 		} else {
-			if stats.diff > int64(warnThreshold) {
+			if thresholds.Warn.exceeded(stats.base.Size, stats.diff) {
 				status = "⚠️"
 			}
-			if stats.diff > int64(errorThreshold) {
+			if thresholds.Error.exceeded(stats.base.Size, stats.diff) {
 				status = "❌"
 				failure = true
 			}
 		}
 
 		output[artifact] = result{
-			baseSize:    fmt.Sprintf("%dMB", stats.baseSize),
-			currentSize: fmt.Sprintf("%dMB", stats.currentSize),
-			change:      fmt.Sprintf("%dMB %s", stats.diff, status),
+			baseSize:    formatSize(stats.base.Size),
+			currentSize: formatSize(stats.current.Size),
+			change:      fmt.Sprintf("%s %s", formatChange(stats.base.Size, stats.diff), status),
 		}
+		breakdowns = append(breakdowns,
+			breakdown{artifact: artifact, kind: "Section", base: stats.base.Sections, current: stats.current.Sections},
+			breakdown{artifact: artifact, kind: "Module", base: stats.base.Modules, current: stats.current.Modules},
+		)
 	}
 
 	if err := renderMarkdownTable(out, output); err != nil {
 		return err
 	}
+	for _, breakdown := range breakdowns {
+		if err := breakdown.render(out); err != nil {
+			return trace.Wrap(err)
+		}
+	}
 
 	if failure {
 		return errors.New("binary bloat detected - at least one binary increased by more than the allowed threshold")
@@ -135,79 +291,160 @@ type result struct {
 }
 
 func renderMarkdownTable(w io.Writer, data map[string]result) error {
-	titles := []string{"Binary", "Base Size", "Current Size", "Change"}
+	artifacts := slices.Sorted(maps.Keys(data))
+	rows := make([][]string, 0, len(artifacts))
+	for _, artifact := range artifacts {
+		column := data[artifact]
+		rows = append(rows, []string{artifact, column.baseSize, column.currentSize, column.change})
+	}
 
-	// get the initial padding from the titles
-	padding := map[string]int{}
-	for _, v := range titles {
-		padding[v] = len(v)
+	// write the heading and title
+	buf := bytes.NewBufferString("# Bloat Check Results\n")
+	writeTable(buf, []string{"Binary", "Base Size", "Current Size", "Change"}, rows)
+
+	_, err := w.Write(buf.Bytes())
+	return trace.Wrap(err)
+}
+
+// writeTable writes a Markdown table with columns padded to the same width.
+func writeTable(buf *bytes.Buffer, titles []string, rows [][]string) {
+	// get the initial padding from the titles, then the largest item in
+	// each column to determine the actual padding
+	padding := make([]int, len(titles))
+	for i, title := range titles {
+		padding[i] = utf8.RuneCountInString(title)
+	}
+	for _, row := range rows {
+		for i, column := range row {
+			padding[i] = max(padding[i], utf8.RuneCountInString(column))
+		}
 	}
 
-	// get the largest item from the title or items in the column to determine
-	// the actual padding
-	for k, column := range data {
-		padding["Binary"] = max(padding["Binary"], len(k))
-		padding["Base Size"] = max(padding["Base Size"], len(column.baseSize))
-		padding["Current Size"] = max(padding["Current Size"], len(column.currentSize))
-		padding["Change"] = max(padding["Change"], utf8.RuneCountInString(column.change))
+	writeRow := func(row []string, pad string) {
+		for i, column := range row {
+			buf.WriteString("|" + pad + column + strings.Repeat(pad, padding[i]-utf8.RuneCountInString(column)) + pad)
+		}
+		buf.WriteString("|\n")
 	}
 
-	format := strings.Repeat("| %%-%ds ", len(padding)) + "|\n"
-	paddings := []interface{}{
-		padding["Binary"],
-		padding["Base Size"],
-		padding["Current Size"],
-		padding["Change"],
+	writeRow(titles, " ")
+	// write the delimiter
+	writeRow(make([]string, len(titles)), "-")
+	// write the rows
+	for _, row := range rows {
+		writeRow(row, " ")
 	}
-	format = fmt.Sprintf(format, paddings...)
+}
 
-	// write the heading and title
-	buf := bytes.NewBufferString("# Bloat Check Results\n")
-	row := []any{"Binary", "Base Size", "Current Size", "Change"}
-	buf.WriteString(fmt.Sprintf(format, row...))
+// breakdown is the change in size of the parts of an artifact, either its
+// ELF sections or Go modules.
+type breakdown struct {
+	artifact string
+	// kind is the name of the parts, "Section" or "Module".
+	kind    string
+	base    map[string]int64
+	current map[string]int64
+}
 
-	// write the delimiter
-	row = []interface{}{"", "", "", ""}
-	buf.WriteString(strings.Replace(fmt.Sprintf(format, row...), " ", "-", -1))
+// render writes the parts with the largest change, if both base and current
+// have a breakdown and anything changed.
+func (b breakdown) render(w io.Writer) error {
+	if len(b.base) == 0 || len(b.current) == 0 {
+		return nil
+	}
 
-	// write the rows
-	for k, column := range data {
-		row := []interface{}{k, column.baseSize, column.currentSize, column.change}
-		buf.WriteString(fmt.Sprintf(format, row...))
+	names := slices.Collect(maps.Keys(b.current))
+	for name := range b.base {
+		if _, ok := b.current[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	// Sort by name first, so parts with the same change keep their order
+	// between runs.
+	slices.Sort(names)
+	names = slices.DeleteFunc(names, func(name string) bool {
+		return b.current[name] == b.base[name]
+	})
+	if len(names) == 0 {
+		return nil
+	}
+	// Largest changes first, either growth or shrinkage.
+	slices.SortStableFunc(names, func(x, y string) int {
+		return cmp.Compare(abs(b.current[y]-b.base[y]), abs(b.current[x]-b.base[x]))
+	})
+	if len(names) > maxBreakdownRows {
+		names = names[:maxBreakdownRows]
+	}
+
+	rows := make([][]string, 0, len(names))
+	for _, name := range names {
+		rows = append(rows, []string{
+			name,
+			formatSize(b.base[name]),
+			formatSize(b.current[name]),
+			formatChange(b.base[name], b.current[name]-b.base[name]),
+		})
 	}
 
+	buf := bytes.NewBufferString(fmt.Sprintf("\n## %s by %s\n", b.artifact, strings.ToLower(b.kind)))
+	writeTable(buf, []string{b.kind, "Base Size", "Current Size", "Change"}, rows)
 	_, err := w.Write(buf.Bytes())
 	return trace.Wrap(err)
 }
 
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// formatSize formats a size in bytes with two decimals in the largest unit
+// up to MB that keeps it above one.
+func formatSize(size int64) string {
+	switch {
+	case abs(size) >= 1<<20:
+		return fmt.Sprintf("%.2fMB", float64(size)/(1<<20))
+	case abs(size) >= 1<<10:
+		return fmt.Sprintf("%.2fKB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%dB", size)
+	}
+}
+
+// formatChange formats the exact change in bytes and the change relative
+// to the base size.
+func formatChange(base, diff int64) string {
+	if base == 0 {
+		return fmt.Sprintf("%+d bytes", diff)
+	}
+	return fmt.Sprintf("%+d bytes (%+.2f%%)", diff, float64(diff)*100/float64(base))
+}
+
 type stats struct {
-	baseSize    int64
-	currentSize int64
-	diff        int64
+	base    artifactStats
+	current artifactStats
+	diff    int64
 }
 
 // baseSizeFn is an abstraction that allows the base artifact
 // size to be retrieved from a variety of locations.
-type baseSizeFn = func(artifact string) (int64, error)
+type baseSizeFn = func(artifact string) (artifactStats, error)
 
 func calculateChange(base baseSizeFn, current, binary string) (stats, error) {
-	baseSize, err := base(binary)
+	baseStats, err := base(binary)
 	if err != nil {
 		return stats{}, trace.Wrap(err)
 	}
 
-	currentInfo, err := os.Stat(filepath.Join(current, binary))
+	currentStats, err := readArtifactStats(filepath.Join(current, binary))
 	if err != nil {
 		return stats{}, trace.Wrap(err)
 	}
 
-	// convert from bytes to MB for easier to read output
-	baseMB := baseSize / (1 << 20)
-	currentMB := currentInfo.Size() / (1 << 20)
-
 	return stats{
-		baseSize:    baseMB,
-		currentSize: currentMB,
-		diff:        currentMB - baseMB,
+		base:    baseStats,
+		current: currentStats,
+		diff:    currentStats.Size - baseStats.Size,
 	}, nil
 }
@@ -0,0 +1,214 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bot
+
+import (
+	"archive/zip"
+	"bytes"
+	"cmp"
+	"context"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/shared-workflows/bot/internal/github"
+	"github.com/gravitational/shared-workflows/bot/internal/s3"
+)
+
+// shaPlaceholder is replaced with the base commit SHA in S3 keys.
+const shaPlaceholder = "{sha}"
+
+// shaPattern matches full git commit SHAs.
+var shaPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// LoadBaseStats loads the base artifact stats emitted by CalculateBinarySizes
+// from a source, which is one of:
+//
+//	file:///path/to/sizes.json
+//	s3://bucket/prefix/{sha}.json
+//	artifact://workflow.yaml/artifact-name?file=sizes.json
+//
+// S3 keys without a {sha} placeholder have "/<sha>.json" appended, where sha
+// is the base commit of the PR. Credentials, region and endpoint of
+// S3-compatible services are read from the standard AWS environment
+// variables and config files. Artifacts are read from the latest successful run of the
+// workflow on the base branch, the file can be omitted if the artifact holds
+// a single JSON file.
+func (b *Bot) LoadBaseStats(ctx context.Context, source string) (string, error) {
+	u, err := url.Parse(source)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+
+	var data []byte
+	switch u.Scheme {
+	case "file":
+		data, err = os.ReadFile(u.Path)
+	case "s3":
+		data, err = b.loadS3BaseStats(ctx, u.Host, strings.TrimPrefix(u.Path, "/"))
+	case "artifact":
+		data, err = b.loadArtifactBaseStats(ctx, u.Host, strings.Trim(u.Path, "/"), u.Query().Get("file"))
+	default:
+		return "", trace.BadParameter("unsupported base source %q, expected file, s3 or artifact", source)
+	}
+	if err != nil {
+		return "", trace.Wrap(err, "loading base stats from %v", source)
+	}
+	return string(data), nil
+}
+
+// loadS3BaseStats reads the stats from an S3 object keyed by the base commit
+// of the PR.
+func (b *Bot) loadS3BaseStats(ctx context.Context, bucket string, key string) ([]byte, error) {
+	base, err := b.pullBase(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if !shaPattern.MatchString(base.SHA) {
+		return nil, trace.BadParameter("invalid base commit %q", base.SHA)
+	}
+	if strings.Contains(key, shaPlaceholder) {
+		key = strings.ReplaceAll(key, shaPlaceholder, base.SHA)
+	} else {
+		key = path.Join(key, base.SHA+".json")
+	}
+
+	client, err := s3.New(ctx, cmp.Or(b.c.S3, &s3.Config{}))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	log.Printf("Loading base stats from s3://%v/%v.", bucket, key)
+	data, err := client.GetObject(ctx, bucket, key)
+	return data, trace.Wrap(err)
+}
+
+// loadArtifactBaseStats reads the stats from an artifact of the latest
+// successful run of a workflow on the base branch.
+func (b *Bot) loadArtifactBaseStats(ctx context.Context, workflow string, name string, file string) ([]byte, error) {
+	if name == "" {
+		return nil, trace.BadParameter("missing artifact name")
+	}
+	base, err := b.pullBase(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	workflows, err := b.c.GitHub.ListWorkflows(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var workflowID int64
+	for _, w := range workflows {
+		if path.Base(w.Path) == workflow {
+			workflowID = w.ID
+		}
+	}
+	if workflowID == 0 {
+		return nil, trace.NotFound("workflow %v not found", workflow)
+	}
+
+	run, err := b.c.GitHub.GetLatestWorkflowRun(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		base.Ref,
+		workflowID,
+		"success")
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	artifacts, err := b.c.GitHub.ListWorkflowRunArtifacts(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		run.ID)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var artifact *github.Artifact
+	for _, a := range artifacts {
+		if a.Name == name && !a.Expired {
+			artifact = &a
+		}
+	}
+	if artifact == nil {
+		return nil, trace.NotFound("artifact %v not found in run %v", name, run.ID)
+	}
+
+	log.Printf("Loading base stats from artifact %v of run %v.", name, run.ID)
+	archive, err := b.c.GitHub.DownloadArtifact(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		artifact.ID)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return readArchiveFile(archive, file)
+}
+
+// readArchiveFile returns a file from a zip archive. When name is empty, the
+// archive must contain a single JSON file.
+func readArchiveFile(archive []byte, name string) ([]byte, error) {
+	r, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	var match *zip.File
+	for _, f := range r.File {
+		switch {
+		case name != "" && f.Name == name:
+			match = f
+		case name == "" && path.Ext(f.Name) == ".json":
+			if match != nil {
+				return nil, trace.BadParameter("artifact contains several JSON files, select one with ?file=")
+			}
+			match = f
+		}
+	}
+	if match == nil {
+		return nil, trace.NotFound("file %q not found in artifact", name)
+	}
+
+	rc, err := match.Open()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	return data, trace.Wrap(err)
+}
+
+// pullBase returns the base branch of the PR.
+func (b *Bot) pullBase(ctx context.Context) (github.Branch, error) {
+	pull, err := b.c.GitHub.GetPullRequest(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		b.c.Environment.Number)
+	if err != nil {
+		return github.Branch{}, trace.Wrap(err)
+	}
+	return pull.UnsafeBase, nil
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bot
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/shared-workflows/bot/internal/env"
+	"github.com/gravitational/shared-workflows/bot/internal/github"
+	"github.com/gravitational/shared-workflows/bot/internal/s3"
+)
+
+const baseSHA = "0123456789abcdef0123456789abcdef01234567"
+
+func zipArchive(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestLoadBaseStats(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sizes.json"), []byte(`{"file": 1}`), 0o600))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sizes/teleport/" + baseSHA + ".json":
+			w.Write([]byte(`{"keyed": 1}`))
+		case "/sizes/placeholder-" + baseSHA + ".json":
+			w.Write([]byte(`{"placeholder": 1}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "aws-config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "aws-credentials"))
+	t.Setenv("AWS_ACCESS_KEY_ID", "id")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	gh := &fakeGithub{
+		pull: github.PullRequest{
+			UnsafeBase: github.Branch{Ref: "master", SHA: baseSHA},
+		},
+		workflows: []github.Workflow{{ID: 3, Path: ".github/workflows/bloat.yaml"}},
+		runs: []github.Run{
+			{ID: 10, CreatedAt: time.Now().Add(-time.Hour)},
+			{ID: 11, CreatedAt: time.Now()},
+		},
+		artifacts: []github.Artifact{
+			{ID: 1, Name: "binary-sizes", Expired: true},
+			{ID: 2, Name: "binary-sizes"},
+			{ID: 3, Name: "multiple"},
+		},
+		artifactData: map[int64][]byte{
+			2: zipArchive(t, map[string]string{"sizes.json": `{"artifact": 1}`, "README": "sizes"}),
+			3: zipArchive(t, map[string]string{"a.json": `{"a": 1}`, "b.json": `{"b": 1}`}),
+		},
+	}
+	b := &Bot{
+		c: &Config{
+			Environment: &env.Environment{Organization: "gravitational", Repository: "teleport", Number: 1},
+			GitHub:      gh,
+			S3:          &s3.Config{Endpoint: srv.URL},
+		},
+	}
+
+	tests := []struct {
+		source    string
+		expected  string
+		assertErr require.ErrorAssertionFunc
+	}{
+		{source: "file://" + filepath.Join(dir, "sizes.json"), expected: `{"file": 1}`, assertErr: require.NoError},
+		{source: "file://" + filepath.Join(dir, "missing.json"), assertErr: require.Error},
+		{source: "s3://sizes/teleport", expected: `{"keyed": 1}`, assertErr: require.NoError},
+		{source: "s3://sizes/placeholder-{sha}.json", expected: `{"placeholder": 1}`, assertErr: require.NoError},
+		{source: "s3://sizes/missing", assertErr: require.Error},
+		{source: "artifact://bloat.yaml/binary-sizes", expected: `{"artifact": 1}`, assertErr: require.NoError},
+		{source: "artifact://bloat.yaml/multiple?file=b.json", expected: `{"b": 1}`, assertErr: require.NoError},
+		{source: "artifact://bloat.yaml/multiple", assertErr: require.Error},
+		{source: "artifact://missing.yaml/binary-sizes", assertErr: require.Error},
+		{source: "https://example.com/sizes.json", assertErr: require.Error},
+	}
+	for _, test := range tests {
+		t.Run(test.source, func(t *testing.T) {
+			stats, err := b.LoadBaseStats(context.Background(), test.source)
+			test.assertErr(t, err)
+			require.Equal(t, test.expected, stats)
+		})
+	}
+}
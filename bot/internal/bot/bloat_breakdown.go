@@ -0,0 +1,171 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bot
+
+import (
+	"bytes"
+	"debug/buildinfo"
+	"debug/elf"
+	"encoding/json"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/gravitational/trace"
+)
+
+const (
+	// stdModule groups the symbols of the Go standard library.
+	stdModule = "std"
+	// otherModule groups symbols that don't belong to any known module.
+	otherModule = "other"
+)
+
+// artifactStats are the size of an artifact in bytes and, for ELF binaries,
+// a breakdown of the size by section and, for Go binaries with a symbol
+// table, by Go module.
+type artifactStats struct {
+	// Size is the size of the artifact in bytes.
+	Size int64 `json:"size"`
+	// Sections are the sizes of the ELF sections by name.
+	Sections map[string]int64 `json:"sections,omitempty"`
+	// Modules are the sizes of the symbols by Go module path.
+	Modules map[string]int64 `json:"modules,omitempty"`
+}
+
+// MarshalJSON encodes artifacts without a breakdown as a plain size, which
+// is the format used before breakdowns existed.
+func (s artifactStats) MarshalJSON() ([]byte, error) {
+	if len(s.Sections) == 0 && len(s.Modules) == 0 {
+		return json.Marshal(s.Size)
+	}
+	type plain artifactStats
+	return json.Marshal(plain(s))
+}
+
+// UnmarshalJSON decodes either a plain size or stats with a breakdown.
+func (s *artifactStats) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] != '{' {
+		*s = artifactStats{}
+		return trace.Wrap(json.Unmarshal(trimmed, &s.Size))
+	}
+	type plain artifactStats
+	return trace.Wrap(json.Unmarshal(data, (*plain)(s)))
+}
+
+// readArtifactStats returns the size of the artifact at path, along with a
+// breakdown if it is an ELF binary.
+func readArtifactStats(path string) (artifactStats, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return artifactStats{}, trace.Wrap(err)
+	}
+	stats := artifactStats{Size: info.Size()}
+
+	f, err := elf.Open(path)
+	if err != nil {
+		// Not an ELF binary, only the size is known.
+		return stats, nil
+	}
+	defer f.Close()
+
+	stats.Sections = elfSections(f)
+	modules, err := goModules(path, f)
+	if err != nil {
+		return artifactStats{}, trace.Wrap(err)
+	}
+	stats.Modules = modules
+	return stats, nil
+}
+
+// elfSections returns the sizes of the sections that take space in the
+// file.
+func elfSections(f *elf.File) map[string]int64 {
+	sections := make(map[string]int64)
+	for _, section := range f.Sections {
+		if section.Type == elf.SHT_NULL || section.Type == elf.SHT_NOBITS || section.Name == "" {
+			continue
+		}
+		sections[section.Name] += int64(section.Size)
+	}
+	return sections
+}
+
+// goModules returns the size of the symbols of a Go binary by module. Nil
+// is returned for binaries without build info or symbol table, for example
+// stripped binaries.
+func goModules(path string, f *elf.File) (map[string]int64, error) {
+	info, err := buildinfo.ReadFile(path)
+	if err != nil {
+		return nil, nil
+	}
+	symbols, err := f.Symbols()
+	if errors.Is(err, elf.ErrNoSymbols) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	// Longer paths first so nested modules win over their parents.
+	modules := []string{info.Main.Path}
+	for _, dep := range info.Deps {
+		modules = append(modules, dep.Path)
+	}
+	slices.SortFunc(modules, func(a, b string) int {
+		return len(b) - len(a)
+	})
+
+	sizes := make(map[string]int64)
+	for _, symbol := range symbols {
+		typ := elf.ST_TYPE(symbol.Info)
+		if symbol.Size == 0 || (typ != elf.STT_FUNC && typ != elf.STT_OBJECT) {
+			continue
+		}
+		sizes[symbolModule(symbol.Name, info.Main.Path, modules)] += int64(symbol.Size)
+	}
+	return sizes, nil
+}
+
+// symbolModule returns the module a Go symbol belongs to. Symbols of the
+// main package belong to the main module.
+func symbolModule(name string, main string, modules []string) string {
+	// Type parameters of generic functions can contain other package paths.
+	name, _, _ = strings.Cut(name, "[")
+	slash := strings.LastIndex(name, "/")
+	dot := strings.Index(name[slash+1:], ".")
+	if dot < 0 {
+		// Not a Go symbol, for example one from cgo.
+		return otherModule
+	}
+	pkg := name[:slash+1+dot]
+	if pkg == "main" && main != "" {
+		return main
+	}
+
+	for _, module := range modules {
+		if module != "" && (pkg == module || strings.HasPrefix(pkg, module+"/")) {
+			return module
+		}
+	}
+	// Standard library packages don't have a dot in the first element.
+	if first, _, _ := strings.Cut(pkg, "/"); !strings.Contains(first, ".") && !strings.Contains(first, ":") {
+		return stdModule
+	}
+	return otherModule
+}
@@ -31,9 +31,7 @@ func TestBloatCheck(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	const baseStats = `{"one": 1,"two": 1,"three": 1}`
-
-	current := t.TempDir()
+	const baseStats = `{"one": 1048576,"two": 1048576,"three": 1048576}`
 
 	cases := []struct {
 		name            string
@@ -54,11 +52,13 @@ func TestBloatCheck(t *testing.T) {
 			},
 			errAssertion: require.NoError,
 			outAssertion: func(t *testing.T, out string) {
-				require.Equal(t, `        | Binary | Base Size | Current Size | Change                 |
-        |--------|-----------|--------------|------------------------|
-        | two    | 1MB       | 2MB          | 1MB ✅                  |
-        | three  | 1MB       | 5MB          | 4MB ✅ skipped by admin |
-        | one    | 1MB       | 1MB          | 0MB ✅                  |`, out)
+				require.Equal(t, `# Bloat Check Results
+| Binary | Base Size | Current Size | Change                                       |
+|--------|-----------|--------------|----------------------------------------------|
+| one    | 1.00MB    | 1.00MB       | +0 bytes (+0.00%) ✅                          |
+| three  | 1.00MB    | 5.00MB       | +4194304 bytes (+400.00%) ✅ skipped by admin |
+| two    | 1.00MB    | 2.00MB       | +1048576 bytes (+100.00%) ✅                  |
+`, out)
 			},
 		},
 		{
@@ -73,11 +73,13 @@ func TestBloatCheck(t *testing.T) {
 			},
 			errAssertion: require.Error,
 			outAssertion: func(t *testing.T, out string) {
-				require.Equal(t, `        | Binary | Base Size | Current Size | Change |
-        |--------|-----------|--------------|--------|
-        | one    | 1MB       | 1MB          | 0MB ✅  |
-        | two    | 1MB       | 2MB          | 1MB ✅  |
-        | three  | 1MB       | 5MB          | 4MB ❌  |`, out)
+				require.Equal(t, `# Bloat Check Results
+| Binary | Base Size | Current Size | Change                      |
+|--------|-----------|--------------|-----------------------------|
+| one    | 1.00MB    | 1.00MB       | +0 bytes (+0.00%) ✅         |
+| three  | 1.00MB    | 5.00MB       | +4194304 bytes (+400.00%) ❌ |
+| two    | 1.00MB    | 2.00MB       | +1048576 bytes (+100.00%) ✅ |
+`, out)
 			},
 		},
 		{
@@ -100,11 +102,13 @@ func TestBloatCheck(t *testing.T) {
 			},
 			errAssertion: require.NoError,
 			outAssertion: func(t *testing.T, out string) {
-				require.Equal(t, `        | Binary | Base Size | Current Size | Change |
-        |--------|-----------|--------------|--------|
-        | one    | 1MB       | 1MB          | 0MB ✅  |
-        | two    | 1MB       | 1MB          | 0MB ✅  |
-        | three  | 1MB       | 1MB          | 0MB ✅  |`, out)
+				require.Equal(t, `# Bloat Check Results
+| Binary | Base Size | Current Size | Change              |
+|--------|-----------|--------------|---------------------|
+| one    | 1.00MB    | 1.00MB       | +0 bytes (+0.00%) ✅ |
+| three  | 1.00MB    | 1.00MB       | +0 bytes (+0.00%) ✅ |
+| two    | 1.00MB    | 1.00MB       | +0 bytes (+0.00%) ✅ |
+`, out)
 			},
 		},
 	}
@@ -119,11 +123,13 @@ func TestBloatCheck(t *testing.T) {
 				},
 			}
 
+			current := t.TempDir()
 			test.createArtifacts(t, current)
 
 			// Validate that only the entries excluded by admins exist in the output
 			var out bytes.Buffer
 			test.errAssertion(t, b.BloatCheck(context.Background(), baseStats, current, []string{"one", "two", "three"}, &out))
+			test.outAssertion(t, out.String())
 		})
 	}
 }
@@ -151,3 +157,134 @@ func TestCalculateBinarySizes(t *testing.T) {
 
 	require.Equal(t, expected, stats)
 }
+
+func TestBloatThresholds(t *testing.T) {
+	config, err := ParseBloatConfig([]byte(`{
+		"default": {"warn": "512KB"},
+		"artifacts": {
+			"tsh": {"warn": "1%", "error": "2.5%"},
+			"tbot": {"error": 1000}
+		}
+	}`))
+	require.NoError(t, err)
+
+	require.Equal(t, BloatThresholds{
+		Warn:  &Threshold{Bytes: 512 << 10},
+		Error: &Threshold{Bytes: 3 << 20},
+	}, config.thresholds("teleport"))
+	require.Equal(t, BloatThresholds{
+		Warn:  &Threshold{Percent: 1},
+		Error: &Threshold{Percent: 2.5},
+	}, config.thresholds("tsh"))
+	require.Equal(t, BloatThresholds{
+		Warn:  &Threshold{Bytes: 512 << 10},
+		Error: &Threshold{Bytes: 1000},
+	}, config.thresholds("tbot"))
+
+	var nilConfig *BloatConfig
+	require.Equal(t, defaultBloatThresholds, nilConfig.thresholds("tsh"))
+
+	// Percentages are relative to the base size.
+	tsh := config.thresholds("tsh")
+	require.False(t, tsh.Warn.exceeded(100<<20, 1<<20))
+	require.True(t, tsh.Warn.exceeded(100<<20, 1<<20+1))
+	require.False(t, tsh.Error.exceeded(100<<20, 2<<20))
+
+	for _, invalid := range []string{`"1TB"`, `"-1MB"`, `"%"`, `true`} {
+		_, err := ParseBloatConfig([]byte(`{"default": {"warn": ` + invalid + `}}`))
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestBloatCheckThresholds(t *testing.T) {
+	current := t.TempDir()
+	createFileWithSize(t, filepath.Join(current, "tsh"), 101)
+
+	config, err := ParseBloatConfig([]byte(`{"artifacts": {"tsh": {"warn": "0.5%", "error": "2%"}}}`))
+	require.NoError(t, err)
+	b := &Bot{
+		c: &Config{
+			Environment: &env.Environment{Number: 1},
+			GitHub:      &fakeGithub{},
+			Bloat:       config,
+		},
+	}
+
+	// A 1MB growth on a 100MB binary is below the default thresholds but
+	// above the configured warning.
+	var out bytes.Buffer
+	require.NoError(t, b.BloatCheck(context.Background(), `{"tsh": 104857600}`, current, []string{"tsh"}, &out))
+	require.Contains(t, out.String(), "| tsh    | 100.00MB  | 101.00MB     | +1048576 bytes (+1.00%) ⚠️ |")
+}
+
+func TestArtifactStatsJSON(t *testing.T) {
+	var stats map[string]artifactStats
+	require.NoError(t, json.Unmarshal([]byte(`{"one": 1, "two": {"size": 2, "sections": {".text": 1}}}`), &stats))
+	require.Equal(t, map[string]artifactStats{
+		"one": {Size: 1},
+		"two": {Size: 2, Sections: map[string]int64{".text": 1}},
+	}, stats)
+
+	data, err := json.Marshal(stats)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"one": 1, "two": {"size": 2, "sections": {".text": 1}}}`, string(data))
+}
+
+func TestReadArtifactStats(t *testing.T) {
+	// The test binary is a Go ELF binary with a symbol table.
+	executable, err := os.Executable()
+	require.NoError(t, err)
+	stats, err := readArtifactStats(executable)
+	if err == nil && len(stats.Sections) == 0 {
+		t.Skip("test binary is not an ELF binary")
+	}
+	require.NoError(t, err)
+	require.Positive(t, stats.Size)
+	require.Positive(t, stats.Sections[".text"])
+	// Test binaries may be linked without a symbol table.
+	if stats.Modules != nil {
+		require.Positive(t, stats.Modules[stdModule])
+		require.Positive(t, stats.Modules["github.com/stretchr/testify"])
+	}
+}
+
+func TestSymbolModule(t *testing.T) {
+	modules := []string{"github.com/gravitational/teleport/api", "github.com/gravitational/teleport"}
+	for symbol, module := range map[string]string{
+		"runtime.main":                 stdModule,
+		"crypto/tls.(*Conn).Handshake": stdModule,
+		"main.main":                    "github.com/gravitational/teleport",
+		"github.com/gravitational/teleport/lib/auth.(*Server).Login":    "github.com/gravitational/teleport",
+		"github.com/gravitational/teleport/api/types.(*UserV2).GetName": "github.com/gravitational/teleport/api",
+		"slices.Sort[github.com/gravitational/teleport/lib.Items]":      stdModule,
+		"github.com/gravitational/trace.Wrap":                           otherModule,
+		"type:.eq.github.com/gravitational/teleport/lib/auth.Server":    otherModule,
+		"x_cgo_init": otherModule,
+	} {
+		require.Equal(t, module, symbolModule(symbol, "github.com/gravitational/teleport", modules), symbol)
+	}
+}
+
+func TestBreakdownRender(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, breakdown{
+		artifact: "tsh",
+		kind:     "Section",
+		base:     map[string]int64{".text": 1000, ".rodata": 500, ".data": 10, ".old": 5, ".gone": 5},
+		current:  map[string]int64{".text": 1500, ".rodata": 400, ".data": 10},
+	}.render(&out))
+	require.Equal(t, `
+## tsh by section
+| Section | Base Size | Current Size | Change               |
+|---------|-----------|--------------|----------------------|
+| .text   | 1000B     | 1.46KB       | +500 bytes (+50.00%) |
+| .rodata | 500B      | 400B         | -100 bytes (-20.00%) |
+| .gone   | 5B        | 0B           | -5 bytes (-100.00%)  |
+| .old    | 5B        | 0B           | -5 bytes (-100.00%)  |
+`, out.String())
+
+	// Nothing is rendered without a base breakdown.
+	out.Reset()
+	require.NoError(t, breakdown{artifact: "tsh", kind: "Module", current: map[string]int64{"std": 1}}.render(&out))
+	require.Empty(t, out.String())
+}
@@ -18,14 +18,15 @@ package bot
 
 import (
 	"context"
-	"path/filepath"
+	"log"
 	"regexp"
-	"slices"
 	"strings"
+	"time"
 
 	"github.com/gravitational/shared-workflows/bot/internal/env"
 	"github.com/gravitational/shared-workflows/bot/internal/github"
 	"github.com/gravitational/shared-workflows/bot/internal/review"
+	"github.com/gravitational/shared-workflows/bot/internal/s3"
 	"github.com/gravitational/trace"
 )
 
@@ -63,15 +64,42 @@ type Client interface {
 	// ListPullRequests returns a list of Pull Requests.
 	ListPullRequests(ctx context.Context, organization string, repository string, state string) ([]github.PullRequest, error)
 
+	// ListPullRequestsCreatedSince returns the Pull Requests in any state
+	// created at or after since, newest first.
+	ListPullRequestsCreatedSince(ctx context.Context, organization string, repository string, since time.Time) ([]github.PullRequest, error)
+
 	// ListFiles is used to list all the files within a Pull Request.
 	ListFiles(ctx context.Context, organization string, repository string, number int) ([]github.PullRequestFile, error)
 
 	// AddLabels will add labels to an Issue or Pull Request.
 	AddLabels(ctx context.Context, organization string, repository string, number int, labels []string) error
 
+	// RemoveLabel will remove a label from an Issue or Pull Request.
+	RemoveLabel(ctx context.Context, organization string, repository string, number int, label string) error
+
+	// GetContents returns the contents of a file at a git reference.
+	GetContents(ctx context.Context, organization string, repository string, path string, ref string) ([]byte, error)
+
+	// GetFileSize returns the size in bytes of a file at a git reference.
+	GetFileSize(ctx context.Context, organization string, repository string, path string, ref string) (int, error)
+
+	// ListDirectory returns the names of the entries of a directory at a git
+	// reference.
+	ListDirectory(ctx context.Context, organization string, repository string, path string, ref string) ([]string, error)
+
 	// CreateComment will leave a comment on an Issue or Pull Request.
 	CreateComment(ctx context.Context, organization string, repository string, number int, comment string) error
 
+	// EditComment will replace the body of an existing comment.
+	EditComment(ctx context.Context, organization string, repository string, id int64, comment string) error
+
+	// CreateCommentReaction will add a reaction to a comment.
+	CreateCommentReaction(ctx context.Context, organization string, repository string, id int64, reaction string) error
+
+	// ListTimeline returns the events of the timeline of an Issue or Pull
+	// Request.
+	ListTimeline(ctx context.Context, organization string, repository string, number int) ([]github.TimelineEvent, error)
+
 	// ListComments will list all comments on an Issue or Pull Request.
 	ListComments(ctx context.Context, organization string, repository string, number int) ([]github.Comment, error)
 
@@ -81,6 +109,19 @@ type Client interface {
 	// ListWorkflowRuns is used to list all workflow runs for an ID.
 	ListWorkflowRuns(ctx context.Context, organization string, repository string, branch string, workflowID int64) ([]github.Run, error)
 
+	// GetLatestWorkflowRun returns the most recent run of a workflow on a
+	// branch with the given status.
+	GetLatestWorkflowRun(ctx context.Context, organization string, repository string, branch string, workflowID int64, status string) (github.Run, error)
+
+	// ListWorkflowRunArtifacts lists the artifacts uploaded by a workflow run.
+	ListWorkflowRunArtifacts(ctx context.Context, organization string, repository string, runID int64) ([]github.Artifact, error)
+
+	// DownloadArtifact returns the zip archive of an artifact.
+	DownloadArtifact(ctx context.Context, organization string, repository string, artifactID int64) ([]byte, error)
+
+	// RerunWorkflowRun re-runs a workflow run.
+	RerunWorkflowRun(ctx context.Context, organization string, repository string, runID int64) error
+
 	// ListWorkflowJobs lists all jobs for a workflow run.
 	ListWorkflowJobs(ctx context.Context, organization string, repository string, runID int64) ([]github.Job, error)
 
@@ -90,11 +131,17 @@ type Client interface {
 	// IsOrgMember checks whether [user] is a member of GitHub orgainzation [org].
 	IsOrgMember(ctx context.Context, user string, org string) (bool, error)
 
+	// GetDefaultBranch returns the name of the default branch of a repository.
+	GetDefaultBranch(ctx context.Context, organization string, repository string) (string, error)
+
 	// GetRef returns a Reference representing the provided ref name.
 	GetRef(ctx context.Context, organization string, repository string, ref string) (github.Reference, error)
 
 	// ListCommitFiles returns all filenames recursively from the tree at a given commit SHA whose prefix matches pathPrefix.
 	ListCommitFiles(ctx context.Context, organization string, repository string, sha string, path string) ([]string, error)
+
+	// CreateStatus sets the commit status with the given context on a commit.
+	CreateStatus(ctx context.Context, organization string, repository string, sha string, state string, context string, description string) error
 }
 
 // Config contains configuration for the bot.
@@ -108,8 +155,60 @@ type Config struct {
 	// Review is used to get code and docs reviewers.
 	Review *review.Assignments
 
-	// Git is used to run git commands, uses dry run in tests.
-	Git func(...string) error
+	// Git is used to run git commands and returns their output, uses dry run
+	// in tests. GitToken isn't used with it.
+	Git func(...string) (string, error)
+
+	// GitToken returns a token that git authenticates with when fetching
+	// from and pushing to GitHub. It is called before every git command so
+	// short-lived tokens, such as the installation tokens of GitHub Apps,
+	// are refreshed during long workflows. When nil, git uses the
+	// credentials of the checkout.
+	GitToken func() (string, error)
+
+	// DraftBackportOnConflict opens draft backport PRs with the conflict
+	// markers committed when a backport fails with conflicts.
+	DraftBackportOnConflict bool
+
+	// WaitForBackportDependencies skips backports that depend on open
+	// backports of PRs referenced by the commits, instead of stacking them.
+	WaitForBackportDependencies bool
+
+	// Bloat configures the thresholds of the bloat check, nil uses the
+	// defaults.
+	Bloat *BloatConfig
+
+	// S3 configures the client used to load base stats for the bloat check
+	// and the flaky test quarantine from S3, nil uses the AWS defaults.
+	S3 *s3.Config
+
+	// Login is the GitHub login the bot comments as, defaults to the login
+	// of GitHub Actions workflows. The comments the bot keeps up to date
+	// are only looked for among its own.
+	Login string
+
+	// LabelConfigPath is the path of the labeler config in the repository,
+	// defaults to .github/labeler.yaml.
+	LabelConfigPath string
+
+	// CodeOwnersPath is the path of a CODEOWNERS file in the repository used
+	// to find preferred reviewers and required approvers. It is read from
+	// the base of each PR. When empty, code owners aren't used.
+	CodeOwnersPath string
+
+	// ReportCheckStatus reports the result of Check as a commit status on
+	// the head of the PR, for when the bot doesn't run in a GitHub Actions
+	// workflow whose result is the status. Stale check workflow runs are
+	// then not dismissed and /retry-check runs Check directly.
+	ReportCheckStatus bool
+
+	// FlakeQuarantine is the location of the flaky test quarantine list,
+	// either a path in the repository or a file:// or s3:// URL. Defaults to
+	// .github/flaky-tests.yaml in the repository.
+	FlakeQuarantine string
+
+	// Now returns the current time, defaults to time.Now.
+	Now func() time.Time
 }
 
 // CheckAndSetDefaults checks and sets defaults.
@@ -120,6 +219,9 @@ func (c *Config) CheckAndSetDefaults() error {
 	if c.Environment == nil {
 		return trace.BadParameter("missing parameter Environment")
 	}
+	if c.Now == nil {
+		c.Now = time.Now
+	}
 
 	return nil
 }
@@ -140,19 +242,66 @@ func New(c *Config) (*Bot, error) {
 	}, nil
 }
 
+// now returns the current time.
+func (b *Bot) now() time.Time {
+	if b.c.Now == nil {
+		return time.Now()
+	}
+	return b.c.Now()
+}
+
+// reviewAssignments returns the reviewer assignments of the PR, with the
+// code owners of its base when CodeOwnersPath is set.
+func (b *Bot) reviewAssignments(ctx context.Context) (*review.Assignments, error) {
+	if b.c.CodeOwnersPath == "" {
+		return b.c.Review, nil
+	}
+	pull, err := b.c.GitHub.GetPullRequest(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		b.c.Environment.Number)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return b.withCodeOwners(ctx, b.c.Review, pull.UnsafeBase.SHA)
+}
+
+// withCodeOwners returns assignments with the code owners of the CODEOWNERS
+// file at ref, the base of a PR. The file is never read from the head of the
+// PR, which could change its own code owners. Without a CODEOWNERS file at
+// ref, code owners aren't used.
+func (b *Bot) withCodeOwners(ctx context.Context, assignments *review.Assignments, ref string) (*review.Assignments, error) {
+	data, err := b.c.GitHub.GetContents(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		b.c.CodeOwnersPath,
+		ref)
+	switch {
+	case trace.IsNotFound(err):
+		log.Printf("No %v found at %v, not using code owners.", b.c.CodeOwnersPath, ref)
+		return assignments, nil
+	case err != nil:
+		return nil, trace.Wrap(err)
+	}
+	assignments, err = assignments.WithCodeOwners(string(data))
+	if err != nil {
+		return nil, trace.Wrap(err, "parsing %v", b.c.CodeOwnersPath)
+	}
+	return assignments, nil
+}
+
 // classifyChanges determines whether the PR contains code changes
 // and/or docs changes.
-func classifyChanges(c *Config, files []github.PullRequestFile) env.Changes {
+func classifyChanges(c *Config, files []github.PullRequestFile, sizes *sizeConfig) env.Changes {
 	ch := env.Changes{
-		Large:   !c.Environment.IsCloudDeployBranch() && xlargeRequiresAdminApproval(files),
+		Large:   !c.Environment.IsCloudDeployBranch() && xlargeRequiresAdminApproval(files, sizes),
 		Release: isReleasePR(c.Environment, files),
-		ApproverCount: approverCount(
-			review.SingleApproverAuthors(c.Environment.Repository),
-			review.SingleApproverPaths(c.Environment.Repository),
-			c.Environment.Author,
-			files,
-		),
 	}
+	policies := review.DefaultApprovalPolicies(c.Environment.Repository)
+	if c.Review != nil {
+		policies = c.Review.ApprovalPolicies(c.Environment.Repository)
+	}
+	ch.ApproverCount = approverCount(policies, c.Environment.Author, files)
 	switch c.Environment.Repository {
 	case env.TeleportRepo:
 		for _, file := range files {
@@ -169,37 +318,22 @@ func classifyChanges(c *Config, files []github.PullRequestFile) env.Changes {
 	return ch
 }
 
-// approverCount returns the number of required approvers for the PR by comparing the files included
-// in the PR against a set of paths that only require a single approver and the PR author against
-// a set of authors that only require a single approver. 1 is returned when all of the
-// files match a single approver path or the PR author matches one of the authors, otherwise
+// approverCount returns the number of required approvers for the PR by
+// evaluating the approval policies against the PR author and files. The last
+// matching policy that sets an approver count wins, otherwise
 // env.DefaultApproverCount is returned.
-func approverCount(authors, paths []string, author string, files []github.PullRequestFile) int {
+func approverCount(policies []review.ApprovalPolicy, author string, files []github.PullRequestFile) int {
 	if len(files) == 0 {
 		return env.DefaultApproverCount
 	}
 
-	// check if pr author only requires a single approval
-	if slices.Contains(authors, author) {
-		return 1
-	}
-
-	// check if pr files only require a single approval
-	if len(paths) == 0 {
-		return env.DefaultApproverCount
-	}
-	for _, file := range files {
-		if !slices.ContainsFunc(paths, func(path string) bool {
-			wildcardMatch, err := filepath.Match(path, file.Name)
-			if err == nil && wildcardMatch {
-				return true
-			}
-			return strings.HasPrefix(file.Name, path)
-		}) {
-			return env.DefaultApproverCount
+	count := env.DefaultApproverCount
+	for _, policy := range policies {
+		if policy.ApproverCount > 0 && policy.Matches(author, files) {
+			count = policy.ApproverCount
 		}
 	}
-	return 1
+	return count
 }
 
 // isReleasePR applies a number of heuristics to the PR changeset to determine
@@ -236,8 +370,8 @@ var releasePRFiles = github.PullRequestFiles{
 	{Name: "integrations/kube-agent-updater/version.go"},
 }
 
-func xlargeRequiresAdminApproval(files []github.PullRequestFile) bool {
-	return prSize(files) == xlarge
+func xlargeRequiresAdminApproval(files []github.PullRequestFile, sizes *sizeConfig) bool {
+	return prSize(files, sizes) == xlarge
 }
 
 type sizeLabel string
@@ -249,22 +383,28 @@ const (
 	xlarge sizeLabel = "size/xl"
 )
 
-func prSize(files []github.PullRequestFile) sizeLabel {
+// sizeLabels are the size labels from smallest to largest.
+var sizeLabels = []sizeLabel{small, medium, large, xlarge}
+
+// prSize returns the size bucket of the changes, a nil sizes uses the
+// default thresholds.
+func prSize(files []github.PullRequestFile, sizes *sizeConfig) sizeLabel {
 	var additions, deletions int
 	for _, f := range files {
-		if skipFileForSizeCheck(f.Name) {
+		if skipFileForSizeCheck(f.Name) || sizes.isGenerated(f.Name) {
 			continue
 		}
 		additions += f.Additions
 		deletions += f.Deletions
 	}
 	delta := additions - deletions
+	thresholds := sizes.thresholds()
 	switch {
-	case delta < 100:
+	case delta < thresholds.Medium:
 		return small
-	case delta < 600:
+	case delta < thresholds.Large:
 		return medium
-	case delta < 1500:
+	case delta < thresholds.XLarge:
 		return large
 	default:
 		return xlarge
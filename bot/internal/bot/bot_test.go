@@ -18,7 +18,10 @@ package bot
 
 import (
 	"context"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -26,6 +29,7 @@ import (
 	"github.com/gravitational/shared-workflows/bot/internal/env"
 	"github.com/gravitational/shared-workflows/bot/internal/github"
 	"github.com/gravitational/shared-workflows/bot/internal/review"
+	"github.com/gravitational/trace"
 )
 
 // TestClassifyChanges checks that PR contents are correctly parsed for docs and
@@ -74,7 +78,7 @@ func TestClassifyChanges(t *testing.T) {
 	c := &Config{Environment: &env.Environment{Repository: env.TeleportRepo}}
 	for _, test := range tests {
 		t.Run(test.desc, func(t *testing.T) {
-			changes := classifyChanges(c, test.files)
+			changes := classifyChanges(c, test.files, nil)
 			require.Equal(t, changes.Docs, test.docs)
 			require.Equal(t, changes.Code, test.code)
 		})
@@ -209,7 +213,7 @@ func TestXLargePRs(t *testing.T) {
 	}
 	for _, test := range tests {
 		t.Run(test.desc, func(t *testing.T) {
-			require.Equal(t, test.isXL, xlargeRequiresAdminApproval(test.files))
+			require.Equal(t, test.isXL, xlargeRequiresAdminApproval(test.files, nil))
 		})
 	}
 }
@@ -437,29 +441,62 @@ func TestApproverCount(t *testing.T) {
 	}
 	for _, test := range cases {
 		t.Run(test.desc, func(t *testing.T) {
-			got := approverCount(test.authors, test.paths, test.author, test.files)
+			var policies []review.ApprovalPolicy
+			if len(test.paths) > 0 {
+				policies = append(policies, review.ApprovalPolicy{Paths: test.paths, ApproverCount: 1})
+			}
+			if len(test.authors) > 0 {
+				policies = append(policies, review.ApprovalPolicy{Authors: test.authors, ApproverCount: 1})
+			}
+			got := approverCount(policies, test.author, test.files)
 			require.Equal(t, test.expect, got)
 		})
 	}
 }
 
 type fakeGithub struct {
-	files       []github.PullRequestFile
-	pull        github.PullRequest
-	jobs        []github.Job
-	reviewers   []string
-	reviews     []github.Review
-	orgMembers  map[string]struct{}
-	ref         github.Reference
-	commitFiles []string
-	comments    []github.Comment
+	files         []github.PullRequestFile
+	pull          github.PullRequest
+	pulls         []github.PullRequest
+	jobs          []github.Job
+	reviewers     []string
+	pullReviewers map[int][]string
+	reviews       []github.Review
+	orgMembers    map[string]struct{}
+	defaultBranch string
+	ref           github.Reference
+	commitFiles   []string
+	commitErr     error
+	comments      []github.Comment
+	timeline      []github.TimelineEvent
+	labels        []string
+	reactions     map[int64][]string
+	requested     []string
+	dismissed     []string
+	workflows     []github.Workflow
+	runs          []github.Run
+	reruns        []int64
+	artifacts     []github.Artifact
+	artifactData  map[int64][]byte
+	removed       []string
+	contents      map[string][]byte
+	statuses      []fakeStatus
+	// pullRequests counts the calls to GetPullRequest.
+	pullRequests int
+}
+
+// fakeStatus is a commit status set with CreateStatus.
+type fakeStatus struct {
+	sha, state, context, description string
 }
 
 func (f *fakeGithub) RequestReviewers(ctx context.Context, organization string, repository string, number int, reviewers []string) error {
+	f.requested = append(f.requested, reviewers...)
 	return nil
 }
 
 func (f *fakeGithub) DismissReviewers(ctx context.Context, organization string, repository string, number int, reviewers []string) error {
+	f.dismissed = append(f.dismissed, reviewers...)
 	return nil
 }
 
@@ -468,10 +505,14 @@ func (f *fakeGithub) ListReviews(ctx context.Context, organization string, repos
 }
 
 func (f *fakeGithub) ListReviewers(ctx context.Context, organization string, repository string, number int) ([]string, error) {
+	if reviewers, ok := f.pullReviewers[number]; ok {
+		return reviewers, nil
+	}
 	return f.reviewers, nil
 }
 
 func (f *fakeGithub) GetPullRequest(ctx context.Context, organization string, repository string, number int) (github.PullRequest, error) {
+	f.pullRequests++
 	return f.pull, nil
 }
 
@@ -480,7 +521,17 @@ func (f *fakeGithub) GetPullRequestWithCommits(ctx context.Context, organization
 }
 
 func (f *fakeGithub) ListPullRequests(ctx context.Context, organization string, repository string, state string) ([]github.PullRequest, error) {
-	return nil, nil
+	return f.pulls, nil
+}
+
+func (f *fakeGithub) ListPullRequestsCreatedSince(ctx context.Context, organization string, repository string, since time.Time) ([]github.PullRequest, error) {
+	var pulls []github.PullRequest
+	for _, pull := range f.pulls {
+		if !pull.CreatedAt.Before(since) {
+			pulls = append(pulls, pull)
+		}
+	}
+	return pulls, nil
 }
 
 func (f *fakeGithub) ListFiles(ctx context.Context, organization string, repository string, number int) ([]github.PullRequestFile, error) {
@@ -488,15 +539,83 @@ func (f *fakeGithub) ListFiles(ctx context.Context, organization string, reposit
 }
 
 func (f *fakeGithub) AddLabels(ctx context.Context, organization string, repository string, number int, labels []string) error {
+	f.labels = append(f.labels, labels...)
+	return nil
+}
+
+func (f *fakeGithub) RemoveLabel(ctx context.Context, organization string, repository string, number int, label string) error {
+	f.removed = append(f.removed, label)
 	return nil
 }
 
+// GetContents returns the content stored under "ref:path", or under path for
+// any ref.
+func (f *fakeGithub) GetContents(ctx context.Context, organization string, repository string, path string, ref string) ([]byte, error) {
+	if content, ok := f.contents[ref+":"+path]; ok {
+		return content, nil
+	}
+	content, ok := f.contents[path]
+	if !ok {
+		return nil, trace.NotFound("%v not found", path)
+	}
+	return content, nil
+}
+
+func (f *fakeGithub) GetFileSize(ctx context.Context, organization string, repository string, path string, ref string) (int, error) {
+	content, err := f.GetContents(ctx, organization, repository, path, ref)
+	return len(content), err
+}
+
+func (f *fakeGithub) ListDirectory(ctx context.Context, organization string, repository string, path string, ref string) ([]string, error) {
+	var names []string
+	for name := range f.contents {
+		if entry, ok := strings.CutPrefix(name, path+"/"); ok && !strings.Contains(entry, "/") {
+			names = append(names, entry)
+		}
+	}
+	if len(names) == 0 {
+		return nil, trace.NotFound("%v not found", path)
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
 func (f *fakeGithub) ListWorkflows(ctx context.Context, organization string, repository string) ([]github.Workflow, error) {
-	return nil, nil
+	return f.workflows, nil
 }
 
 func (f *fakeGithub) ListWorkflowRuns(ctx context.Context, organization string, repository string, branch string, workflowID int64) ([]github.Run, error) {
-	return nil, nil
+	return f.runs, nil
+}
+
+func (f *fakeGithub) GetLatestWorkflowRun(ctx context.Context, organization string, repository string, branch string, workflowID int64, status string) (github.Run, error) {
+	if len(f.runs) == 0 {
+		return github.Run{}, trace.NotFound("no runs")
+	}
+	latest := f.runs[0]
+	for _, run := range f.runs {
+		if run.CreatedAt.After(latest.CreatedAt) {
+			latest = run
+		}
+	}
+	return latest, nil
+}
+
+func (f *fakeGithub) ListWorkflowRunArtifacts(ctx context.Context, organization string, repository string, runID int64) ([]github.Artifact, error) {
+	return f.artifacts, nil
+}
+
+func (f *fakeGithub) DownloadArtifact(ctx context.Context, organization string, repository string, artifactID int64) ([]byte, error) {
+	data, ok := f.artifactData[artifactID]
+	if !ok {
+		return nil, trace.NotFound("artifact %v not found", artifactID)
+	}
+	return data, nil
+}
+
+func (f *fakeGithub) RerunWorkflowRun(ctx context.Context, organization string, repository string, runID int64) error {
+	f.reruns = append(f.reruns, runID)
+	return nil
 }
 
 func (f *fakeGithub) ListWorkflowJobs(ctx context.Context, organization string, repository string, runID int64) ([]github.Job, error) {
@@ -514,18 +633,54 @@ func (f *fakeGithub) IsOrgMember(ctx context.Context, user string, org string) (
 
 func (f *fakeGithub) CreateComment(ctx context.Context, organization string, repository string, number int, comment string) error {
 	f.comments = append(f.comments, github.Comment{
-		Body: comment,
+		ID:     int64(len(f.comments) + 1),
+		Author: defaultLogin,
+		Body:   comment,
 	})
 
 	return nil
 }
 
+func (f *fakeGithub) EditComment(ctx context.Context, organization string, repository string, id int64, comment string) error {
+	for i := range f.comments {
+		if f.comments[i].ID == id {
+			f.comments[i].Body = comment
+			return nil
+		}
+	}
+	return trace.NotFound("comment %v not found", id)
+}
+
+func (f *fakeGithub) CreateCommentReaction(ctx context.Context, organization string, repository string, id int64, reaction string) error {
+	if f.reactions == nil {
+		f.reactions = make(map[int64][]string)
+	}
+	f.reactions[id] = append(f.reactions[id], reaction)
+	return nil
+}
+
 func (f *fakeGithub) ListComments(ctx context.Context, organization string, repository string, number int) ([]github.Comment, error) {
 	return f.comments, nil
 }
 
+func (f *fakeGithub) ListTimeline(ctx context.Context, organization string, repository string, number int) ([]github.TimelineEvent, error) {
+	return f.timeline, nil
+}
+
 func (f *fakeGithub) CreatePullRequest(ctx context.Context, organization string, repository string, title string, head string, base string, body string, draft bool) (int, error) {
-	return 0, nil
+	number := 100 + len(f.pulls)
+	f.pulls = append(f.pulls, github.PullRequest{
+		Number:      number,
+		UnsafeTitle: title,
+		UnsafeBody:  body,
+		UnsafeHead:  github.Branch{Ref: head},
+		UnsafeBase:  github.Branch{Ref: base},
+	})
+	return number, nil
+}
+
+func (f *fakeGithub) GetDefaultBranch(ctx context.Context, organization string, repository string) (string, error) {
+	return f.defaultBranch, nil
 }
 
 func (f *fakeGithub) GetRef(ctx context.Context, organization string, repository string, ref string) (github.Reference, error) {
@@ -533,7 +688,12 @@ func (f *fakeGithub) GetRef(ctx context.Context, organization string, repository
 }
 
 func (f *fakeGithub) ListCommitFiles(ctx context.Context, organization string, repository string, commitSHA string, pathPrefix string) ([]string, error) {
-	return f.commitFiles, nil
+	return f.commitFiles, f.commitErr
+}
+
+func (f *fakeGithub) CreateStatus(ctx context.Context, organization string, repository string, sha string, state string, context string, description string) error {
+	f.statuses = append(f.statuses, fakeStatus{sha: sha, state: state, context: context, description: description})
+	return nil
 }
 
 func TestSkipFileForSizeCheck(t *testing.T) {
@@ -23,13 +23,52 @@ import (
 	"slices"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/gravitational/trace"
+
+	"github.com/gravitational/shared-workflows/libs/changelog"
 )
 
 const NoChangelogLabel string = "no-changelog"
 const ChangelogPrefix string = "changelog: "
-const ChangelogRegex string = "(?mi)^changelog: .*"
+const ChangelogRegex string = `(?mi)^changelog(?:\(([^)]*)\))?(?:\[([^\]]*)\])?: (.*)`
+
+// maxChangelogEntryLength is the maximum number of characters of a
+// changelog entry.
+const maxChangelogEntryLength = 250
+
+var (
+	// changelogScopePattern matches the scope of a changelog entry, for
+	// example the tool or component it applies to.
+	changelogScopePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9 ._/-]*$`)
+	// changelogReferencePattern matches PR and issue references and URLs.
+	changelogReferencePattern = regexp.MustCompile(`https?://\S+|(?:[\w.-]+/[\w.-]+)?#\d+`)
+)
+
+// changelogEntry is a changelog line of a PR body, for example
+// "changelog(fix)[tctl]: Fixed a crash.".
+type changelogEntry struct {
+	// Category is the optional category of the entry.
+	Category string
+	// Scope is the optional component the entry applies to.
+	Scope string
+	// Summary describes the change.
+	Summary string
+}
+
+// String returns the entry as a PR body line.
+func (e changelogEntry) String() string {
+	var prefix strings.Builder
+	prefix.WriteString("changelog")
+	if e.Category != "" {
+		prefix.WriteString("(" + e.Category + ")")
+	}
+	if e.Scope != "" {
+		prefix.WriteString("[" + e.Scope + "]")
+	}
+	return prefix.String() + ": " + e.Summary
+}
 
 // Checks if the PR contains a changelog entry in the PR body, or a "no-changelog" label.
 //
@@ -58,31 +97,43 @@ func (b *Bot) CheckChangelog(ctx context.Context) error {
 	for _, changelogEntry := range changelogEntries {
 		err = b.validateChangelogEntry(ctx, changelogEntry)
 		if err != nil {
-			return trace.Wrap(err, "failed to validate changelog entry %q", changelogEntry)
+			return trace.Wrap(err, "failed to validate changelog entry %q", changelogEntry.Summary)
 		}
 	}
 
 	return nil
 }
 
-func (b *Bot) getChangelogEntries(prBody string) []string {
+func (b *Bot) getChangelogEntries(prBody string) []changelogEntry {
 	changelogRegex := regexp.MustCompile(ChangelogRegex)
 
-	changelogMatches := changelogRegex.FindAllString(prBody, -1)
-	for i, changelogMatch := range changelogMatches {
-		changelogMatches[i] = changelogMatch[len(ChangelogPrefix):] // Case insensitive prefix removal
+	var changelogEntries []changelogEntry
+	for _, match := range changelogRegex.FindAllStringSubmatch(prBody, -1) {
+		changelogEntries = append(changelogEntries, changelogEntry{
+			Category: strings.ToLower(strings.TrimSpace(match[1])),
+			Scope:    strings.ToLower(strings.TrimSpace(match[2])),
+			Summary:  strings.TrimSuffix(match[3], "\r"),
+		})
 	}
 
-	if len(changelogMatches) > 0 {
-		log.Printf("Found changelog entries %v", changelogMatches)
+	if len(changelogEntries) > 0 {
+		log.Printf("Found changelog entries %v", changelogEntries)
 	}
-	return changelogMatches
+	return changelogEntries
 }
 
 // Checks for common issues with the changelog entry.
 // This is not intended to be comprehensive, rather, it is intended to cover the majority of problems.
-func (b *Bot) validateChangelogEntry(ctx context.Context, changelogEntry string) error {
-	changelogEntry = strings.ToLower(strings.TrimSpace(changelogEntry)) // Format the entry for easy validation
+func (b *Bot) validateChangelogEntry(ctx context.Context, entry changelogEntry) error {
+	if entry.Category != "" && !changelog.IsCategory(entry.Category) {
+		return trace.BadParameter("The changelog category %q is unknown, it must be one of: %v.", entry.Category, strings.Join(changelog.CategoryNames(), ", "))
+	}
+	if entry.Scope != "" && !changelogScopePattern.MatchString(entry.Scope) {
+		return trace.BadParameter("The changelog scope %q must only contain letters, digits, spaces and the characters ._/-.", entry.Scope)
+	}
+
+	summary := strings.TrimSpace(entry.Summary)
+	changelogEntry := strings.ToLower(summary) // Format the entry for easy validation
 	if changelogEntry == "" {
 		return trace.BadParameter("The changelog entry must contain one or more non-whitespace characters.")
 	}
@@ -108,5 +159,24 @@ func (b *Bot) validateChangelogEntry(ctx context.Context, changelogEntry string)
 		return trace.BadParameter("The %q label must be set instead of listing 'none' as the changelog entry.", NoChangelogLabel)
 	}
 
+	words := slices.DeleteFunc(strings.Fields(changelogReferencePattern.ReplaceAllString(changelogEntry, "")), func(word string) bool {
+		return !strings.ContainsFunc(word, unicode.IsLetter)
+	})
+	if len(words) < 2 {
+		return trace.BadParameter("The changelog entry must describe the change, not only reference a PR or an issue.")
+	}
+
+	if !unicode.IsUpper([]rune(summary)[0]) {
+		return trace.BadParameter("The changelog entry must be in sentence case and start with an upper case letter.")
+	}
+
+	if !strings.HasSuffix(summary, ".") || strings.HasSuffix(summary, "..") {
+		return trace.BadParameter("The changelog entry must end with a single period.")
+	}
+
+	if length := utf8.RuneCountInString(summary); length > maxChangelogEntryLength {
+		return trace.BadParameter("The changelog entry must be at most %v characters long, it is %v characters long.", maxChangelogEntryLength, length)
+	}
+
 	return nil
 }
@@ -43,27 +43,27 @@ func TestGetChangelogEntry(t *testing.T) {
 	tests := []struct {
 		desc     string
 		body     string
-		expected []string
+		expected []changelogEntry
 	}{
 		{
 			desc:     "pass-simple",
 			body:     strings.Join([]string{"some typical PR entry", fmt.Sprintf("%schangelog entry", ChangelogPrefix), "some extra text"}, "\n"),
-			expected: []string{"changelog entry"},
+			expected: []changelogEntry{{Summary: "changelog entry"}},
 		},
 		{
 			desc:     "pass-case-invariant",
 			body:     strings.Join([]string{"some typical PR entry", fmt.Sprintf("%schangelog entry", strings.ToUpper(ChangelogPrefix))}, "\n"),
-			expected: []string{"changelog entry"},
+			expected: []changelogEntry{{Summary: "changelog entry"}},
 		},
 		{
 			desc:     "pass-prefix-in-changelog-entry",
 			body:     strings.Join([]string{"some typical PR entry", strings.Repeat(ChangelogPrefix, 5)}, "\n"),
-			expected: []string{strings.Repeat(ChangelogPrefix, 4)},
+			expected: []changelogEntry{{Summary: strings.Repeat(ChangelogPrefix, 4)}},
 		},
 		{
 			desc:     "pass-only-changelog-in-body",
 			body:     fmt.Sprintf("%schangelog entry", ChangelogPrefix),
-			expected: []string{"changelog entry"},
+			expected: []changelogEntry{{Summary: "changelog entry"}},
 		},
 		{
 			desc: "pass-multiple-entries",
@@ -72,10 +72,25 @@ func TestGetChangelogEntry(t *testing.T) {
 				ChangelogPrefix + "entry 2",
 				ChangelogPrefix + "entry 3",
 			}, "\n"),
-			expected: []string{
-				"entry 1",
-				"entry 2",
-				"entry 3",
+			expected: []changelogEntry{
+				{Summary: "entry 1"},
+				{Summary: "entry 2"},
+				{Summary: "entry 3"},
+			},
+		},
+		{
+			desc: "pass-category-and-scope",
+			body: strings.Join([]string{
+				"changelog(security): entry 1\r",
+				"Changelog[tctl]: entry 2",
+				"changelog(Fix)[web ui]: entry 3",
+				"changelog(): entry 4",
+			}, "\n"),
+			expected: []changelogEntry{
+				{Category: "security", Summary: "entry 1"},
+				{Scope: "tctl", Summary: "entry 2"},
+				{Category: "fix", Scope: "web ui", Summary: "entry 3"},
+				{Summary: "entry 4"},
 			},
 		},
 		{
@@ -95,6 +110,11 @@ func TestGetChangelogEntry(t *testing.T) {
 
 			changelogEntries := b.getChangelogEntries(test.body)
 			require.Exactly(t, test.expected, changelogEntries)
+
+			// Entries are copied to backports as lines of the PR body.
+			for _, entry := range changelogEntries {
+				require.Equal(t, []changelogEntry{entry}, b.getChangelogEntries(entry.String()))
+			}
 		})
 	}
 }
@@ -103,18 +123,84 @@ func TestValidateGetChangelogEntry(t *testing.T) {
 	tests := []struct {
 		desc        string
 		entry       string
+		category    string
+		scope       string
 		shouldError bool
 	}{
 		{
 			desc:        "pass-simple",
-			entry:       "Changelog entry",
+			entry:       "Changelog entry.",
 			shouldError: false,
 		},
 		{
 			desc:        "pass-markdown-single-line-code-block",
-			entry:       "Changelog `entry`",
+			entry:       "Changelog `entry`.",
+			shouldError: false,
+		},
+		{
+			desc:        "pass-category-and-scope",
+			entry:       "Fixed a crash when logging in.",
+			category:    "fix",
+			scope:       "web ui",
 			shouldError: false,
 		},
+		{
+			desc:        "pass-with-reference",
+			entry:       "Fixed a crash when logging in, see #1234.",
+			shouldError: false,
+		},
+		{
+			desc:        "fail-unknown-category",
+			entry:       "Changelog entry.",
+			category:    "misc",
+			shouldError: true,
+		},
+		{
+			desc:        "fail-invalid-scope",
+			entry:       "Changelog entry.",
+			scope:       "tctl!",
+			shouldError: true,
+		},
+		{
+			desc:        "fail-lower-case",
+			entry:       "changelog entry.",
+			shouldError: true,
+		},
+		{
+			desc:        "fail-no-period",
+			entry:       "Changelog entry",
+			shouldError: true,
+		},
+		{
+			desc:        "fail-other-punctuation",
+			entry:       "Changelog entry!",
+			shouldError: true,
+		},
+		{
+			desc:        "fail-ellipsis",
+			entry:       "Changelog entry...",
+			shouldError: true,
+		},
+		{
+			desc:        "fail-too-long",
+			entry:       "Changelog " + strings.Repeat("entry ", 50) + "end.",
+			shouldError: true,
+		},
+		{
+			desc:        "fail-pr-number-only",
+			entry:       "Fixes #1234.",
+			shouldError: true,
+		},
+		{
+			desc:        "fail-issue-url-only",
+			entry:       "See https://github.com/gravitational/teleport/issues/1234.",
+			shouldError: true,
+		},
+		{
+			desc:        "fail-cross-repo-reference-only",
+			entry:       "Fixes gravitational/teleport#1234",
+			shouldError: true,
+		},
 		{
 			desc:        "fail-empty",
 			entry:       "",
@@ -175,7 +261,11 @@ func TestValidateGetChangelogEntry(t *testing.T) {
 		t.Run(test.desc, func(t *testing.T) {
 			b, ctx := buildTestingFixtures()
 
-			err := b.validateChangelogEntry(ctx, test.entry)
+			err := b.validateChangelogEntry(ctx, changelogEntry{
+				Category: test.category,
+				Scope:    test.scope,
+				Summary:  test.entry,
+			})
 			if !test.shouldError {
 				require.NoError(t, err, "the test should not have errored but did")
 				return
@@ -28,17 +28,44 @@ import (
 	"github.com/gravitational/trace"
 )
 
+// checkStatusContext is the context of the commit status that reports the
+// result of Check when ReportCheckStatus is set.
+const checkStatusContext = "bot/check"
+
+// maxStatusDescription is the maximum length of the description of a commit
+// status, in characters.
+const maxStatusDescription = 140
+
 // Check checks if required reviewers have approved the PR.
 //
 // Team specific reviews require an approval from both sets of reviews.
 // External reviews require approval from admins.
 func (b *Bot) Check(ctx context.Context) error {
+	err := b.check(ctx)
+	if !b.c.ReportCheckStatus {
+		return trace.Wrap(err)
+	}
+	if statusErr := b.reportCheckStatus(ctx, err); statusErr != nil {
+		return trace.NewAggregate(err, statusErr)
+	}
+	return trace.Wrap(err)
+}
+
+// check runs the checks of Check.
+func (b *Bot) check(ctx context.Context) error {
 	// First check whether the PR was explicitly marked as "do not merge".
-	err := b.checkDoNotMerge(ctx)
+	doNotMerge, err := b.hasDoNotMerge(ctx)
 	if err != nil {
 		return trace.Wrap(err)
 	}
 
+	var requirements []review.Requirement
+	if doNotMerge {
+		requirements = append(requirements, review.Requirement{
+			Name: fmt.Sprintf("Remove the %v label", doNotMergeLabel),
+		})
+	}
+
 	reviews, err := b.c.GitHub.ListReviews(ctx,
 		b.c.Environment.Organization,
 		b.c.Environment.Repository,
@@ -52,19 +79,26 @@ func (b *Bot) Check(ctx context.Context) error {
 		return trace.Wrap(err, "checking for internal author")
 	}
 	if !internal {
-		if err := b.c.Review.CheckExternal(b.c.Environment.Author, reviews); err != nil {
-			return trace.Wrap(err)
+		log.Printf("Check: Found external author %q.", b.c.Environment.Author)
+		external := b.c.Review.ExplainExternal(b.c.Environment.Author, reviews)
+		requirements = append(requirements, external...)
+		b.updateCheckComment(ctx, requirements)
+		if doNotMerge {
+			return trace.Wrap(errDoNotMerge())
 		}
-		return nil
+		return trace.Wrap(review.Verdict(external))
 	}
 
-	// Remove stale "Check" status badges inline for internal reviews.
-	err = b.dismiss(ctx,
-		b.c.Environment.Organization,
-		b.c.Environment.Repository,
-		b.c.Environment.UnsafeHead)
-	if err != nil {
-		return trace.Wrap(err)
+	// Remove stale "Check" status badges inline for internal reviews. A
+	// commit status replaces the previous one, so there is nothing to remove.
+	if !b.c.ReportCheckStatus {
+		err = b.dismiss(ctx,
+			b.c.Environment.Organization,
+			b.c.Environment.Repository,
+			b.c.Environment.UnsafeHead)
+		if err != nil {
+			return trace.Wrap(err)
+		}
 	}
 
 	files, err := b.c.GitHub.ListFiles(ctx,
@@ -75,7 +109,8 @@ func (b *Bot) Check(ctx context.Context) error {
 		return trace.Wrap(err)
 	}
 
-	changes := classifyChanges(b.c, files)
+	sizes := b.loadSizeConfig(ctx, b.c.Environment.UnsafeBase)
+	changes := classifyChanges(b.c, files, sizes)
 	log.Printf("Check: required approvals: %d", changes.ApproverCount)
 
 	if changes.Large {
@@ -104,7 +139,26 @@ func (b *Bot) Check(ctx context.Context) error {
 		}
 	}
 
-	if err := b.c.Review.CheckInternal(b.c.Environment, reviews, changes, files); err != nil {
+	// RFDs also require approval from the approvers they list.
+	rfdRequirements, err := b.rfdRequirements(ctx, files, reviews)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	assignments, err := b.reviewAssignments(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	// The check is the verdict of the requirements the comment lists.
+	log.Printf("Check: Found internal author %v.", b.c.Environment.Author)
+	explained := append(assignments.ExplainInternal(b.c.Environment, reviews, changes, files), rfdRequirements...)
+	requirements = append(requirements, explained...)
+	b.updateCheckComment(ctx, requirements)
+	if doNotMerge {
+		return trace.Wrap(errDoNotMerge())
+	}
+	if err := review.Verdict(explained); err != nil {
 		return trace.Wrap(err)
 	}
 
@@ -117,8 +171,9 @@ func (b *Bot) Check(ctx context.Context) error {
 	return nil
 }
 
-// checkDoNotMerge checks if the PR has "do-not-merge" label on it.
-func (b *Bot) checkDoNotMerge(ctx context.Context) error {
+// reportCheckStatus sets the commit status of the head of the PR to the
+// result of the checks.
+func (b *Bot) reportCheckStatus(ctx context.Context, checkErr error) error {
 	pull, err := b.c.GitHub.GetPullRequest(ctx,
 		b.c.Environment.Organization,
 		b.c.Environment.Repository,
@@ -127,13 +182,101 @@ func (b *Bot) checkDoNotMerge(ctx context.Context) error {
 		return trace.Wrap(err)
 	}
 
-	if slices.Contains(pull.UnsafeLabels, doNotMergeLabel) {
-		return trace.BadParameter("the pull request is marked as %v", doNotMergeLabel)
+	state, description := github.CommitStatusSuccess, "All required reviewers have approved"
+	if checkErr != nil {
+		state, description = github.CommitStatusFailure, trace.UserMessage(checkErr)
+	}
+	if runes := []rune(description); len(runes) > maxStatusDescription {
+		description = string(runes[:maxStatusDescription-3]) + "..."
 	}
+	return trace.Wrap(b.c.GitHub.CreateStatus(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		pull.UnsafeHead.SHA,
+		state,
+		checkStatusContext,
+		description))
+}
 
+// checkDoNotMerge checks if the PR has "do-not-merge" label on it.
+func (b *Bot) checkDoNotMerge(ctx context.Context) error {
+	doNotMerge, err := b.hasDoNotMerge(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if doNotMerge {
+		return trace.Wrap(errDoNotMerge())
+	}
 	return nil
 }
 
+// hasDoNotMerge returns true if the PR has "do-not-merge" label on it.
+func (b *Bot) hasDoNotMerge(ctx context.Context) (bool, error) {
+	pull, err := b.c.GitHub.GetPullRequest(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		b.c.Environment.Number)
+	if err != nil {
+		return false, trace.Wrap(err)
+	}
+
+	return slices.Contains(pull.UnsafeLabels, doNotMergeLabel), nil
+}
+
+func errDoNotMerge() error {
+	return trace.BadParameter("the pull request is marked as %v", doNotMergeLabel)
+}
+
+// updateCheckComment keeps a single comment on the PR up to date with the
+// review requirements. The comment is only created once a requirement is
+// unmet and is updated on every check afterwards. Failures are only logged
+// as the comment is informational.
+func (b *Bot) updateCheckComment(ctx context.Context, requirements []review.Requirement) {
+	met := !slices.ContainsFunc(requirements, func(r review.Requirement) bool {
+		return !r.Met
+	})
+	if err := b.upsertComment(ctx, checkCommentMarker, renderRequirements(requirements), !met); err != nil {
+		log.Printf("Check: Failed to update review requirements comment: %v", err)
+	}
+}
+
+// renderRequirements renders the review requirements as a markdown table.
+// Reviewers are not mentioned to avoid notifying them on every update.
+func renderRequirements(requirements []review.Requirement) string {
+	var sb strings.Builder
+	sb.WriteString("### Review requirements\n\n")
+	if !slices.ContainsFunc(requirements, func(r review.Requirement) bool { return !r.Met }) {
+		sb.WriteString("All review requirements are met.\n\n")
+	}
+	sb.WriteString("| | Requirement | Can be satisfied by | Approved by |\n")
+	sb.WriteString("|---|---|---|---|\n")
+	for _, r := range requirements {
+		status := ":x:"
+		if r.Met {
+			status = ":white_check_mark:"
+		}
+		fmt.Fprintf(&sb, "| %v | %v | %v | %v |\n", status, r.Name, formatLogins(r.Reviewers), formatLogins(r.Approvals))
+	}
+	return sb.String()
+}
+
+// formatLogins formats GitHub logins for the requirements table, listing at
+// most maxListedLogins of them.
+func formatLogins(logins []string) string {
+	if len(logins) == 0 {
+		return "-"
+	}
+	var formatted []string
+	for i, login := range logins {
+		if i == maxListedLogins {
+			formatted = append(formatted, fmt.Sprintf("and %d more", len(logins)-i))
+			break
+		}
+		formatted = append(formatted, "`"+login+"`")
+	}
+	return strings.Join(formatted, ", ")
+}
+
 // dismissReviewers removes stale review requests from an approved pull request.
 func (b *Bot) dismissReviewers(ctx context.Context) error {
 	r, err := b.reviewersToDismiss(ctx)
@@ -217,4 +360,12 @@ const (
 	// doNotMergeLabel is the name of the GitHub label that is put on PRs
 	// to prevent them from merging.
 	doNotMergeLabel = "do-not-merge"
+
+	// checkCommentMarker identifies the comment that lists the review
+	// requirements of the PR.
+	checkCommentMarker = "<!-- bot:check-requirements -->"
+
+	// maxListedLogins is the maximum number of reviewers listed for each
+	// requirement in the check comment.
+	maxListedLogins = 10
 )
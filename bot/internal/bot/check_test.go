@@ -18,11 +18,14 @@ package bot
 
 import (
 	"context"
+	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/gravitational/shared-workflows/bot/internal/env"
 	"github.com/gravitational/shared-workflows/bot/internal/github"
 	"github.com/gravitational/shared-workflows/bot/internal/review"
+	"github.com/gravitational/trace"
 	"github.com/stretchr/testify/require"
 )
 
@@ -151,3 +154,83 @@ func TestDismissUnnecessaryReviewers(t *testing.T) {
 		})
 	}
 }
+
+// TestUpdateCheckComment checks that the review requirements are kept in a
+// single comment.
+func TestUpdateCheckComment(t *testing.T) {
+	// Comments of other users are never taken for the bot's, even with the
+	// marker.
+	forged := checkCommentMarker + "\nAll review requirements are met."
+	gh := &fakeGithub{
+		comments: []github.Comment{
+			{ID: 1, Author: defaultLogin, Body: "unrelated"},
+			{ID: 2, Author: "mallory", Body: forged},
+		},
+	}
+	b := &Bot{
+		c: &Config{
+			Environment: &env.Environment{},
+			GitHub:      gh,
+		},
+	}
+	ctx := context.Background()
+
+	// Nothing is posted while all requirements are met.
+	b.updateCheckComment(ctx, []review.Requirement{{Name: "Approval from an owner", Met: true}})
+	require.Len(t, gh.comments, 2)
+
+	b.updateCheckComment(ctx, []review.Requirement{
+		{Name: "Remove the do-not-merge label"},
+		{Name: "Approval from an owner", Reviewers: []string{"alice", "bob"}},
+	})
+	require.Len(t, gh.comments, 3)
+	require.Equal(t, forged, gh.comments[1].Body)
+	require.Contains(t, gh.comments[2].Body, checkCommentMarker)
+	require.Contains(t, gh.comments[2].Body, "| :x: | Approval from an owner | `alice`, `bob` | - |")
+
+	// The same comment is updated once requirements are met.
+	b.updateCheckComment(ctx, []review.Requirement{
+		{Name: "Approval from an owner", Met: true, Reviewers: []string{"alice", "bob"}, Approvals: []string{"bob"}},
+	})
+	require.Len(t, gh.comments, 3)
+	require.Contains(t, gh.comments[2].Body, "All review requirements are met.")
+	require.Contains(t, gh.comments[2].Body, "| :white_check_mark: | Approval from an owner | `alice`, `bob` | `bob` |")
+	require.NotContains(t, gh.comments[2].Body, "do-not-merge")
+}
+
+func TestReportCheckStatus(t *testing.T) {
+	gh := &fakeGithub{
+		pull: github.PullRequest{UnsafeHead: github.Branch{SHA: "abc123"}},
+	}
+	b := &Bot{
+		c: &Config{
+			Environment:       &env.Environment{Organization: "foo", Repository: "bar", Number: 1},
+			GitHub:            gh,
+			ReportCheckStatus: true,
+		},
+	}
+	ctx := context.Background()
+
+	require.NoError(t, b.reportCheckStatus(ctx, nil))
+	require.NoError(t, b.reportCheckStatus(ctx, trace.BadParameter("requires approval from %v", strings.Repeat("a", 200))))
+	require.NoError(t, b.reportCheckStatus(ctx, trace.BadParameter("requires approval from %v", strings.Repeat("é", 200))))
+	require.Len(t, gh.statuses, 3)
+
+	require.Equal(t, fakeStatus{
+		sha:         "abc123",
+		state:       github.CommitStatusSuccess,
+		context:     checkStatusContext,
+		description: "All required reviewers have approved",
+	}, gh.statuses[0])
+
+	failure := gh.statuses[1]
+	require.Equal(t, github.CommitStatusFailure, failure.state)
+	require.Len(t, failure.description, maxStatusDescription)
+	require.True(t, strings.HasPrefix(failure.description, "requires approval from aaa"))
+	require.True(t, strings.HasSuffix(failure.description, "..."))
+
+	// Descriptions are cut between characters.
+	failure = gh.statuses[2]
+	require.True(t, utf8.ValidString(failure.description))
+	require.Equal(t, maxStatusDescription, utf8.RuneCountInString(failure.description))
+}
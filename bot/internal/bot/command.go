@@ -0,0 +1,478 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bot
+
+import (
+	"context"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gravitational/trace"
+)
+
+// permission determines who is allowed to run a command.
+type permission int
+
+const (
+	// adminPermission only allows admins to run a command.
+	adminPermission permission = iota
+	// ownerPermission allows admins and owners of the repository to run a
+	// command.
+	ownerPermission
+	// internalAuthorPermission allows admins, owners and the author of the PR
+	// to run a command if the author is an internal contributor.
+	internalAuthorPermission
+)
+
+// String returns the name of the group of users with the permission.
+func (p permission) String() string {
+	switch p {
+	case ownerPermission:
+		return "owners"
+	case internalAuthorPermission:
+		return "internal authors"
+	default:
+		return "admins"
+	}
+}
+
+// command is a slash command that can be run by commenting on a PR.
+type command struct {
+	// name of the command including the leading slash, for example
+	// "/reassign".
+	name string
+	// usage describes the arguments of the command.
+	usage string
+	// permission determines who is allowed to run the command.
+	permission permission
+	// minArgs and maxArgs limit the number of arguments. A negative maxArgs
+	// allows any number of arguments.
+	minArgs, maxArgs int
+	// prefix matches every comment starting with the name, so "/excludeflake"
+	// is also found in "/excludeflakes TestFoo". The skip commands have
+	// always been matched this way.
+	prefix bool
+	// run executes the command. Commands without run have no immediate
+	// effect, they are read from the PR comments by the workflow they apply
+	// to, for example "/excludeflake" by the exclude-flakes workflow.
+	run func(b *Bot, ctx context.Context, args []string) error
+}
+
+// commands returns all registered commands.
+func commands() []command {
+	return []command{
+		{name: skipFlakePrefix, usage: "TestName...", permission: adminPermission, maxArgs: -1, prefix: true},
+		{name: skipBloatCheckPrefix, usage: "artifact...", permission: adminPermission, maxArgs: -1, prefix: true},
+		{name: skipRFDPrefix, permission: adminPermission, prefix: true},
+		{name: "/backport", usage: "branch/vN...", permission: internalAuthorPermission, minArgs: 1, maxArgs: -1, run: (*Bot).backportCommand},
+		{name: "/retry-backport", usage: "branch/vN", permission: internalAuthorPermission, minArgs: 1, maxArgs: 1, run: (*Bot).retryBackportCommand},
+		{name: "/reassign", permission: internalAuthorPermission, run: (*Bot).reassignCommand},
+		{name: "/skip-test-plan", permission: ownerPermission, run: (*Bot).skipTestPlanCommand},
+		{name: "/retry-check", permission: internalAuthorPermission, run: (*Bot).retryCheckCommand},
+	}
+}
+
+// findCommand returns the registered command with name.
+func findCommand(name string) (command, bool) {
+	i := slices.IndexFunc(commands(), func(c command) bool {
+		return c.name == name
+	})
+	if i == -1 {
+		return command{}, false
+	}
+	return commands()[i], true
+}
+
+// parseCommand splits a comment into a command name and its arguments.
+// Arguments are separated by any whitespace, including newlines. An empty
+// name is returned if the comment is not a command.
+func parseCommand(body string) (string, []string) {
+	fields := strings.Fields(body)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return "", nil
+	}
+	return strings.ToLower(fields[0]), fields[1:]
+}
+
+// checkArgs checks the number of arguments passed to the command.
+func (c command) checkArgs(args []string) error {
+	if len(args) < c.minArgs || (c.maxArgs >= 0 && len(args) > c.maxArgs) {
+		return trace.BadParameter("usage: %v %v", c.name, c.usage)
+	}
+	return nil
+}
+
+// match returns the arguments of the command if the comment body invokes it.
+func (c command) match(body string) ([]string, bool) {
+	if c.prefix {
+		if !strings.HasPrefix(body, c.name) {
+			return nil, false
+		}
+		return strings.Fields(body)[1:], true
+	}
+	name, args := parseCommand(body)
+	return args, name == c.name
+}
+
+// authorize checks that author is allowed to run the command from a comment
+// created and updated at the given times. Edited comments are rejected as
+// anyone with write access can edit a comment from an admin.
+func (b *Bot) authorize(ctx context.Context, c command, author string, createdAt, updatedAt time.Time) error {
+	if !createdAt.IsZero() && !createdAt.Equal(updatedAt) {
+		return trace.AccessDenied("edited comments can not run commands")
+	}
+
+	switch c.permission {
+	case internalAuthorPermission:
+		if author == b.c.Environment.Author {
+			internal, err := b.isInternal(ctx)
+			if err != nil {
+				return trace.Wrap(err, "checking for internal author")
+			}
+			if internal {
+				return nil
+			}
+		}
+		if b.c.Review.IsOwner(b.c.Environment, author) {
+			return nil
+		}
+		if slices.Contains(b.c.Review.GetAdminCheckers(b.c.Environment.Author), author) {
+			return nil
+		}
+	case ownerPermission:
+		if b.c.Review.IsOwner(b.c.Environment, author) {
+			return nil
+		}
+		fallthrough
+	default:
+		if slices.Contains(b.c.Review.GetAdminCheckers(b.c.Environment.Author), author) {
+			return nil
+		}
+	}
+	return trace.AccessDenied("%v can only be run by %v", c.name, c.permission)
+}
+
+// invocations returns the arguments of every authorized invocation of the
+// named command in the PR comments.
+func (b *Bot) invocations(ctx context.Context, name string) ([][]string, error) {
+	c, ok := findCommand(name)
+	if !ok {
+		return nil, trace.BadParameter("unknown command %v", name)
+	}
+	return b.commandInvocations(ctx, c)
+}
+
+// commandInvocations returns the arguments of every authorized invocation of
+// c in the PR comments.
+func (b *Bot) commandInvocations(ctx context.Context, c command) ([][]string, error) {
+	comments, err := b.c.GitHub.ListComments(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		b.c.Environment.Number,
+	)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	var invocations [][]string
+	for _, comment := range comments {
+		args, ok := c.match(comment.Body)
+		if !ok {
+			continue
+		}
+		if err := b.authorize(ctx, c, comment.Author, comment.CreatedAt, comment.UpdatedAt); err != nil {
+			log.Printf("Ignoring %v from %v: %v.", c.name, comment.Author, err)
+			continue
+		}
+		invocations = append(invocations, args)
+	}
+	return invocations, nil
+}
+
+// Command runs the slash command from the comment that triggered the
+// workflow. Comments that are not commands are ignored. Accepted commands
+// are acknowledged with a reaction on the comment.
+func (b *Bot) Command(ctx context.Context) error {
+	comment := b.c.Environment.Comment
+	if comment == nil || b.c.Environment.Number == 0 {
+		return trace.BadParameter("the command workflow must be triggered by a comment on a pull request")
+	}
+
+	name, args := parseCommand(comment.UnsafeBody)
+	c, ok := findCommand(name)
+	if !ok {
+		log.Printf("Command: Ignoring comment %v, no known command found.", comment.ID)
+		return nil
+	}
+
+	if err := b.authorize(ctx, c, comment.User.Login, comment.CreatedAt, comment.UpdatedAt); err != nil {
+		log.Printf("Command: Rejecting %v from %v: %v.", c.name, comment.User.Login, err)
+		b.react(ctx, comment.ID, reactionRejected)
+		return nil
+	}
+	if err := c.checkArgs(args); err != nil {
+		b.react(ctx, comment.ID, reactionFailed)
+		return trace.Wrap(err)
+	}
+
+	log.Printf("Command: Running %v %v from %v.", c.name, strings.Join(args, " "), comment.User.Login)
+	b.react(ctx, comment.ID, reactionAccepted)
+	if c.run == nil {
+		return nil
+	}
+
+	// issue_comment events don't include the branches of the PR.
+	if err := b.loadBranches(ctx); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := c.run(b, ctx, args); err != nil {
+		b.react(ctx, comment.ID, reactionFailed)
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// react adds a reaction to a comment. Failures are only logged as the
+// reaction is informational.
+func (b *Bot) react(ctx context.Context, id int64, reaction string) {
+	err := b.c.GitHub.CreateCommentReaction(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		id,
+		reaction)
+	if err != nil {
+		log.Printf("Command: Failed to react to comment %v: %v.", id, err)
+	}
+}
+
+// loadBranches fills in the head and base branches of the PR if they are
+// missing from the environment.
+func (b *Bot) loadBranches(ctx context.Context) error {
+	if b.c.Environment.UnsafeHead != "" && b.c.Environment.UnsafeBase != "" {
+		return nil
+	}
+	pull, err := b.c.GitHub.GetPullRequest(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		b.c.Environment.Number)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	b.c.Environment.UnsafeHead = pull.UnsafeHead.Ref
+	b.c.Environment.UnsafeBase = pull.UnsafeBase.Ref
+	return nil
+}
+
+// backportCommand requests backports of the PR to the given branches. The
+// backports are created right away if the PR is already merged, otherwise
+// the backport workflow creates them once it is.
+func (b *Bot) backportCommand(ctx context.Context, args []string) error {
+	var branches, labels []string
+	for _, arg := range args {
+		branch := strings.TrimPrefix(arg, "backport/")
+		if !branchPattern.MatchString(branch) {
+			return trace.BadParameter("invalid backport branch %q, expected branch/vN or master", arg)
+		}
+		branches = append(branches, branch)
+		labels = append(labels, "backport/"+branch)
+	}
+	if err := b.checkBackportAuthor(ctx); err != nil {
+		return trace.Wrap(err)
+	}
+
+	err := b.c.GitHub.AddLabels(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		b.c.Environment.Number,
+		labels)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	pull, err := b.c.GitHub.GetPullRequestWithCommits(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		b.c.Environment.Number)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if !pull.Merged {
+		log.Printf("Command: PR is not merged yet, backports to %v will be created on merge.", strings.Join(branches, ", "))
+		return nil
+	}
+	return trace.Wrap(b.backport(ctx, pull, branches, backportOptions{
+		draftOnConflict:     b.c.DraftBackportOnConflict,
+		waitForDependencies: b.c.WaitForBackportDependencies,
+	}))
+}
+
+// retryBackportCommand creates the backport to a single branch again, for
+// example after the conflicts of a previous attempt were fixed on the base
+// branch. The backport branch of the previous attempt is overwritten unless
+// it has commits that weren't made by the bot.
+func (b *Bot) retryBackportCommand(ctx context.Context, args []string) error {
+	branch := strings.TrimPrefix(args[0], "backport/")
+	if !branchPattern.MatchString(branch) {
+		return trace.BadParameter("invalid backport branch %q, expected branch/vN or master", args[0])
+	}
+
+	pull, err := b.c.GitHub.GetPullRequestWithCommits(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		b.c.Environment.Number)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if !pull.Merged {
+		return trace.BadParameter("backports can only be retried once the PR is merged")
+	}
+	if err := b.checkBackportAuthor(ctx); err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(b.backport(ctx, pull, []string{branch}, backportOptions{
+		retry:               true,
+		draftOnConflict:     b.c.DraftBackportOnConflict,
+		waitForDependencies: b.c.WaitForBackportDependencies,
+	}))
+}
+
+// checkBackportAuthor checks that backports can be created automatically
+// for the author of the PR.
+func (b *Bot) checkBackportAuthor(ctx context.Context) error {
+	internal, err := b.isInternal(ctx)
+	if err != nil {
+		return trace.Wrap(err, "checking for internal author")
+	}
+	if !internal {
+		return trace.BadParameter("automatic backports are only supported for internal contributors")
+	}
+	return nil
+}
+
+// reassignCommand replaces the pending review requests of the PR with a
+// new set of reviewers. Reviewers that were requested before are only
+// picked again if there is no one else.
+func (b *Bot) reassignCommand(ctx context.Context, args []string) error {
+	pending, err := b.c.GitHub.ListReviewers(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		b.c.Environment.Number)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	files, err := b.c.GitHub.ListFiles(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		b.c.Environment.Number)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	reviewers, err := b.getReviewers(ctx, files, pending)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	stale := slices.DeleteFunc(slices.Clone(pending), func(reviewer string) bool {
+		return slices.Contains(reviewers, reviewer)
+	})
+	if len(stale) > 0 {
+		log.Printf("Command: Removing review requests from: %v.", stale)
+		err := b.c.GitHub.DismissReviewers(ctx,
+			b.c.Environment.Organization,
+			b.c.Environment.Repository,
+			b.c.Environment.Number,
+			stale)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+
+	log.Printf("Command: Requesting reviews from: %v.", reviewers)
+	return trace.Wrap(b.c.GitHub.RequestReviewers(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		b.c.Environment.Number,
+		reviewers))
+}
+
+// skipTestPlanCommand labels the PR so the manual test plan check is skipped.
+func (b *Bot) skipTestPlanCommand(ctx context.Context, args []string) error {
+	return trace.Wrap(b.c.GitHub.AddLabels(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		b.c.Environment.Number,
+		[]string{noTestPlanLabel}))
+}
+
+// retryCheckCommand re-runs the most recent "Check" workflow run of the PR.
+// When the result of Check is reported as a commit status, Check is run
+// directly and its failures are reported in the status instead.
+func (b *Bot) retryCheckCommand(ctx context.Context, args []string) error {
+	if b.c.ReportCheckStatus {
+		if err := b.Check(ctx); err != nil {
+			log.Printf("Command: Check failed: %v.", err)
+		}
+		return nil
+	}
+
+	check, err := b.findWorkflow(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		checkWorkflowPath)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	// HEAD could be controlled by an attacker, however, all this would allow
+	// is the attacker to re-run a check.
+	runs, err := b.c.GitHub.ListWorkflowRuns(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		b.c.Environment.UnsafeHead,
+		check.ID)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if len(runs) == 0 {
+		return trace.NotFound("no check runs found for this PR")
+	}
+
+	latest := runs[0]
+	for _, run := range runs[1:] {
+		if run.CreatedAt.After(latest.CreatedAt) {
+			latest = run
+		}
+	}
+	log.Printf("Command: Re-running check workflow run %v.", latest.ID)
+	return trace.Wrap(b.c.GitHub.RerunWorkflowRun(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		latest.ID))
+}
+
+const (
+	// reactionAccepted acknowledges a command.
+	reactionAccepted = "+1"
+	// reactionRejected marks a command the commenter is not allowed to run.
+	reactionRejected = "-1"
+	// reactionFailed marks a command that failed to run.
+	reactionFailed = "confused"
+)
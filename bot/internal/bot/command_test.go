@@ -0,0 +1,274 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bot
+
+import (
+	"cmp"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/shared-workflows/bot/internal/env"
+	"github.com/gravitational/shared-workflows/bot/internal/github"
+	"github.com/gravitational/shared-workflows/bot/internal/review"
+)
+
+func TestParseCommand(t *testing.T) {
+	name, args := parseCommand("/Backport branch/v17\nbranch/v16")
+	require.Equal(t, "/backport", name)
+	require.Equal(t, []string{"branch/v17", "branch/v16"}, args)
+
+	name, args = parseCommand("LGTM /reassign")
+	require.Empty(t, name)
+	require.Empty(t, args)
+}
+
+// TestCommand checks permissions, argument validation and reactions of
+// commands run from comments.
+func TestCommand(t *testing.T) {
+	r, err := review.New(&review.Config{
+		Admins: []string{"admin"},
+		CoreReviewers: map[string]review.Reviewer{
+			"owner":    {Owner: true},
+			"reviewer": {Owner: false},
+			"author":   {Owner: false},
+		},
+		CloudReviewers:    map[string]review.Reviewer{},
+		CodeReviewersOmit: map[string]bool{},
+		DocsReviewers:     map[string]review.Reviewer{},
+		DocsReviewersOmit: map[string]bool{},
+	})
+	require.NoError(t, err)
+
+	now := time.Now()
+	tests := []struct {
+		desc      string
+		author    string
+		commenter string
+		body      string
+		edited    bool
+		reaction  string
+		labels    []string
+		assertErr require.ErrorAssertionFunc
+	}{
+		{
+			desc:      "not a command",
+			commenter: "reviewer",
+			body:      "LGTM",
+			assertErr: require.NoError,
+		},
+		{
+			desc:      "author can backport",
+			commenter: "author",
+			body:      "/backport branch/v17 backport/branch/v16",
+			reaction:  reactionAccepted,
+			labels:    []string{"backport/branch/v17", "backport/branch/v16"},
+			assertErr: require.NoError,
+		},
+		{
+			desc:      "external author can't backport",
+			author:    "outsider",
+			commenter: "outsider",
+			body:      "/backport branch/v17",
+			reaction:  reactionRejected,
+			assertErr: require.NoError,
+		},
+		{
+			desc:      "admin can't backport external PRs",
+			author:    "outsider",
+			commenter: "admin",
+			body:      "/backport branch/v17",
+			reaction:  reactionFailed,
+			assertErr: require.Error,
+		},
+		{
+			desc:      "external author can't reassign",
+			author:    "outsider",
+			commenter: "outsider",
+			body:      "/reassign",
+			reaction:  reactionRejected,
+			assertErr: require.NoError,
+		},
+		{
+			desc:      "external author can't retry check",
+			author:    "outsider",
+			commenter: "outsider",
+			body:      "/retry-check",
+			reaction:  reactionRejected,
+			assertErr: require.NoError,
+		},
+		{
+			desc:      "reviewer can't backport",
+			commenter: "reviewer",
+			body:      "/backport branch/v17",
+			reaction:  reactionRejected,
+			assertErr: require.NoError,
+		},
+		{
+			desc:      "invalid branch",
+			commenter: "author",
+			body:      "/backport main",
+			reaction:  reactionFailed,
+			assertErr: require.Error,
+		},
+		{
+			desc:      "missing arguments",
+			commenter: "author",
+			body:      "/backport",
+			reaction:  reactionFailed,
+			assertErr: require.Error,
+		},
+		{
+			desc:      "retry backport before merge",
+			commenter: "author",
+			body:      "/retry-backport branch/v17",
+			reaction:  reactionFailed,
+			assertErr: require.Error,
+		},
+		{
+			desc:      "retry backport takes a single branch",
+			commenter: "author",
+			body:      "/retry-backport branch/v17 branch/v16",
+			reaction:  reactionFailed,
+			assertErr: require.Error,
+		},
+		{
+			desc:      "owner can skip test plan",
+			commenter: "owner",
+			body:      "/skip-test-plan",
+			reaction:  reactionAccepted,
+			labels:    []string{noTestPlanLabel},
+			assertErr: require.NoError,
+		},
+		{
+			desc:      "author can't skip test plan",
+			commenter: "author",
+			body:      "/skip-test-plan",
+			reaction:  reactionRejected,
+			assertErr: require.NoError,
+		},
+		{
+			desc:      "edited comment",
+			commenter: "admin",
+			body:      "/skip-test-plan",
+			edited:    true,
+			reaction:  reactionRejected,
+			assertErr: require.NoError,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			gh := &fakeGithub{
+				pull: github.PullRequest{
+					UnsafeHead: github.Branch{Ref: "author/feature"},
+					UnsafeBase: github.Branch{Ref: "master"},
+				},
+			}
+			comment := &env.Comment{
+				ID:         42,
+				User:       env.User{Login: test.commenter},
+				UnsafeBody: test.body,
+				CreatedAt:  now,
+				UpdatedAt:  now,
+			}
+			if test.edited {
+				comment.UpdatedAt = now.Add(time.Minute)
+			}
+			b := &Bot{
+				c: &Config{
+					Environment: &env.Environment{
+						Organization: "gravitational",
+						Repository:   "teleport",
+						Number:       1,
+						Author:       cmp.Or(test.author, "author"),
+						Comment:      comment,
+					},
+					GitHub: gh,
+					Review: r,
+				},
+			}
+
+			test.assertErr(t, b.Command(context.Background()))
+			if test.reaction == "" {
+				require.Empty(t, gh.reactions)
+			} else {
+				require.Contains(t, gh.reactions[42], test.reaction)
+			}
+			require.Equal(t, test.labels, gh.labels)
+		})
+	}
+}
+
+func TestRetryCheckCommand(t *testing.T) {
+	gh := &fakeGithub{
+		workflows: []github.Workflow{{ID: 7, Path: checkWorkflowPath}},
+		runs: []github.Run{
+			{ID: 1, CreatedAt: time.Now().Add(-time.Hour)},
+			{ID: 2, CreatedAt: time.Now()},
+			{ID: 3, CreatedAt: time.Now().Add(-time.Minute)},
+		},
+	}
+	b := &Bot{
+		c: &Config{
+			Environment: &env.Environment{UnsafeHead: "author/feature"},
+			GitHub:      gh,
+		},
+	}
+	require.NoError(t, b.retryCheckCommand(context.Background(), nil))
+	require.Equal(t, []int64{2}, gh.reruns)
+}
+
+func TestReassignCommand(t *testing.T) {
+	r, err := review.New(&review.Config{
+		Admins: []string{"admin"},
+		CoreReviewers: map[string]review.Reviewer{
+			"1": {Owner: true},
+			"2": {Owner: true},
+			"3": {Owner: false},
+			"4": {Owner: false},
+		},
+		CloudReviewers:    map[string]review.Reviewer{},
+		CodeReviewersOmit: map[string]bool{},
+		DocsReviewers:     map[string]review.Reviewer{},
+		DocsReviewersOmit: map[string]bool{},
+	})
+	require.NoError(t, err)
+
+	gh := &fakeGithub{
+		reviewers: []string{"1", "3"},
+		files:     []github.PullRequestFile{{Name: "lib/auth/auth.go"}},
+	}
+	b := &Bot{
+		c: &Config{
+			Environment: &env.Environment{
+				Organization: "gravitational",
+				Repository:   "teleport",
+				Number:       1,
+				Author:       "4",
+				UnsafeBase:   "master",
+			},
+			GitHub: gh,
+			Review: r,
+		},
+	}
+	require.NoError(t, b.reassignCommand(context.Background(), nil))
+	// Previous reviewers are only kept if there is no one else.
+	require.ElementsMatch(t, []string{"2", "3"}, gh.requested)
+	require.Equal(t, []string{"1"}, gh.dismissed)
+}
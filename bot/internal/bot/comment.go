@@ -0,0 +1,85 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bot
+
+import (
+	"cmp"
+	"context"
+	"strings"
+
+	"github.com/gravitational/shared-workflows/bot/internal/github"
+	"github.com/gravitational/trace"
+)
+
+// defaultLogin is the login of the bot when it authenticates with the token
+// of a GitHub Actions workflow.
+const defaultLogin = "github-actions[bot]"
+
+// login returns the GitHub login the bot comments as.
+func (b *Bot) login() string {
+	return cmp.Or(b.c.Login, defaultLogin)
+}
+
+// findComment returns the comment of the bot on the PR that contains the
+// hidden marker, or nil if there is none. Comments of other users are
+// ignored, anyone can copy the marker into their own comment.
+func (b *Bot) findComment(ctx context.Context, marker string) (*github.Comment, error) {
+	comments, err := b.c.GitHub.ListComments(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		b.c.Environment.Number)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, comment := range comments {
+		if comment.Author == b.login() && strings.Contains(comment.Body, marker) {
+			return &comment, nil
+		}
+	}
+	return nil, nil
+}
+
+// upsertComment replaces the body of the comment identified by marker, or
+// creates it when it doesn't exist yet. The marker is prepended to body so
+// the comment can be found again. When create is false, a missing comment
+// is not created.
+func (b *Bot) upsertComment(ctx context.Context, marker string, body string, create bool) error {
+	comment, err := b.findComment(ctx, marker)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	body = marker + "\n" + body
+	switch {
+	case comment == nil && !create:
+		return nil
+	case comment == nil:
+		return trace.Wrap(b.c.GitHub.CreateComment(ctx,
+			b.c.Environment.Organization,
+			b.c.Environment.Repository,
+			b.c.Environment.Number,
+			body))
+	case comment.Body == body:
+		return nil
+	default:
+		return trace.Wrap(b.c.GitHub.EditComment(ctx,
+			b.c.Environment.Organization,
+			b.c.Environment.Repository,
+			comment.ID,
+			body))
+	}
+}
@@ -67,7 +67,7 @@ func (b *Bot) dismiss(ctx context.Context, organization string, repository strin
 	check, err := b.findWorkflow(ctx,
 		organization,
 		repository,
-		".github/workflows/check.yaml")
+		checkWorkflowPath)
 	if err != nil {
 		return trace.Wrap(err)
 	}
@@ -92,6 +92,10 @@ func (b *Bot) dismiss(ctx context.Context, organization string, repository strin
 	return nil
 }
 
+// checkWorkflowPath is the path of the "Check" workflow in repositories
+// that use the bot.
+const checkWorkflowPath = ".github/workflows/check.yaml"
+
 func (b *Bot) findWorkflow(ctx context.Context, organization string, repository string, path string) (github.Workflow, error) {
 	workflows, err := b.c.GitHub.ListWorkflows(ctx, organization, repository)
 	if err != nil {
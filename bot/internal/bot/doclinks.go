@@ -0,0 +1,359 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bot
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/shared-workflows/bot/internal/github"
+)
+
+// maxRedirects is the number of redirects followed when resolving a link.
+const maxRedirects = 10
+
+var (
+	// docsLinkPattern matches Markdown links and images, capturing the "!" of
+	// images and the link target.
+	docsLinkPattern = regexp.MustCompile(`(!?)\[[^\]]*\]\(\s*<?([^)\s>]+)>?(?:\s+["'][^)]*["'])?\s*\)`)
+	// docsInlineCodePattern matches inline code spans.
+	docsInlineCodePattern = regexp.MustCompile("`[^`]*`")
+	// docsHeadingPattern matches Markdown headings, capturing their text.
+	docsHeadingPattern = regexp.MustCompile(`^#{1,6}\s+(.*?)\s*#*\s*$`)
+	// docsHeadingIDPattern matches an explicit heading ID, for example
+	// "## Setup {#setup}".
+	docsHeadingIDPattern = regexp.MustCompile(`\s*\{#([^}]+)\}$`)
+	// docsSchemePattern matches links with a URL scheme, such as https: or
+	// mailto:.
+	docsSchemePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*:`)
+)
+
+// docsLink is a link found in a docs page.
+type docsLink struct {
+	// Line is the line number of the link.
+	Line int
+	// Target is the target of the link.
+	Target string
+}
+
+// brokenDocsLink is a link that doesn't resolve to a page or heading.
+type brokenDocsLink struct {
+	docsLink
+	// File is the page containing the link, relative to the clone.
+	File string
+	// Reason describes why the link is broken.
+	Reason string
+}
+
+// CheckDocsLinks checks the links in the docs pages that a PR adds or
+// changes. Relative links and links to docs URL paths must lead to a page,
+// after following the redirects in the docs configuration, and links to
+// headings must match a heading of the page. Broken links are reported as
+// GitHub Actions error annotations written to w.
+//
+// teleportClonePath is a relative path to a gravitational/teleport clone
+// checked out at the head of the PR.
+func (b *Bot) CheckDocsLinks(ctx context.Context, teleportClonePath string, w io.Writer) error {
+	// The event is not a pull request, so don't check PR files.
+	if b.c.Environment.Number == 0 {
+		return nil
+	}
+
+	c, _, err := readDocsConfig(teleportClonePath)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	pages, err := docsPages(teleportClonePath)
+	if err != nil {
+		return trace.Wrap(err, "unable to list Teleport documentation pages at %v", teleportClonePath)
+	}
+
+	files, err := b.c.GitHub.ListFiles(ctx, b.c.Environment.Organization, b.c.Environment.Repository, b.c.Environment.Number)
+	if err != nil {
+		return trace.Wrap(err, "unable to fetch files for PR %v", b.c.Environment.Number)
+	}
+
+	checker := &docsLinkChecker{
+		root:      teleportClonePath,
+		pages:     pages,
+		redirects: redirectMap(c.Redirects),
+		headings:  make(map[string]map[string]struct{}),
+	}
+	var broken []brokenDocsLink
+	for _, f := range files {
+		if f.Status == github.StatusRemoved || !isDocsPage(f.Name) {
+			continue
+		}
+		links, err := checker.check(f.Name)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		broken = append(broken, links...)
+	}
+
+	for _, link := range broken {
+		fmt.Fprintf(w, "::error file=%v,line=%v::%v\n",
+			escapeAnnotationProperty(link.File),
+			link.Line,
+			escapeAnnotationData(fmt.Sprintf("Broken link %v: %v", link.Target, link.Reason)))
+	}
+	if len(broken) > 0 {
+		return trace.Errorf("found %v broken links in docs pages", len(broken))
+	}
+	return nil
+}
+
+// isDocsPage returns true if name is a docs page, not a partial.
+func isDocsPage(name string) bool {
+	ext := path.Ext(name)
+	return strings.HasPrefix(name, docsPrefix+"/") &&
+		(ext == ".mdx" || ext == ".md") &&
+		!strings.Contains(name, includeSegment)
+}
+
+// docsLinkChecker resolves links in docs pages.
+type docsLinkChecker struct {
+	// root is the path of the clone.
+	root string
+	// pages maps URL paths to page files.
+	pages map[string]string
+	// redirects maps redirect sources to destinations.
+	redirects map[string]string
+	// headings caches the heading anchors of page files.
+	headings map[string]map[string]struct{}
+}
+
+// check returns the broken links in a page, name is relative to the clone.
+func (c *docsLinkChecker) check(name string) ([]brokenDocsLink, error) {
+	data, err := os.ReadFile(filepath.Join(c.root, filepath.FromSlash(name)))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	var broken []brokenDocsLink
+	for _, link := range parseDocsLinks(data) {
+		reason, err := c.resolve(name, link.Target)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if reason != "" {
+			broken = append(broken, brokenDocsLink{docsLink: link, File: name, Reason: reason})
+		}
+	}
+	return broken, nil
+}
+
+// resolve returns why a link target in the page name is broken, or an empty
+// string if it isn't.
+func (c *docsLinkChecker) resolve(name string, target string) (string, error) {
+	if docsSchemePattern.MatchString(target) || strings.HasPrefix(target, "//") {
+		return "", nil
+	}
+
+	target, anchor, _ := strings.Cut(target, "#")
+	target, _, _ = strings.Cut(target, "?")
+
+	var file string
+	switch ext := path.Ext(target); {
+	case target == "":
+		file = name
+	case ext == ".mdx" || ext == ".md":
+		// Links to files are relative to the page file.
+		file = path.Join(path.Dir(name), target)
+		if strings.HasPrefix(target, "/") {
+			file = path.Join(docsPrefix, target)
+		}
+		if _, err := os.Stat(filepath.Join(c.root, filepath.FromSlash(file))); err != nil {
+			return fmt.Sprintf("%v does not exist", file), nil
+		}
+	case ext != "":
+		// Images and other assets are not checked.
+		return "", nil
+	default:
+		// Links to URL paths are relative to the URL path of the page.
+		urlPath := target
+		if !strings.HasPrefix(target, "/") {
+			urlPath = path.Join(toURLPath(name), target)
+		}
+		if !strings.HasSuffix(urlPath, "/") {
+			urlPath += "/"
+		}
+		var reason string
+		file, reason = c.page(urlPath)
+		if reason != "" {
+			return reason, nil
+		}
+	}
+
+	if anchor == "" {
+		return "", nil
+	}
+	headings, err := c.pageHeadings(file)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	if _, ok := headings[anchor]; !ok {
+		return fmt.Sprintf("%v has no heading with the anchor #%v", file, anchor), nil
+	}
+	return "", nil
+}
+
+// page returns the file of the page at a URL path, following redirects, or
+// why there is none.
+func (c *docsLinkChecker) page(urlPath string) (string, string) {
+	for range maxRedirects {
+		if file, ok := c.pages[urlPath]; ok {
+			return file, ""
+		}
+		next, ok := c.redirects[urlPath]
+		if !ok {
+			return "", fmt.Sprintf("%v is not a docs page or redirect", urlPath)
+		}
+		urlPath = next
+	}
+	return "", fmt.Sprintf("%v redirects more than %v times", urlPath, maxRedirects)
+}
+
+// pageHeadings returns the heading anchors of a page file.
+func (c *docsLinkChecker) pageHeadings(file string) (map[string]struct{}, error) {
+	if headings, ok := c.headings[file]; ok {
+		return headings, nil
+	}
+	data, err := os.ReadFile(filepath.Join(c.root, filepath.FromSlash(file)))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	headings := parseDocsHeadings(data)
+	c.headings[file] = headings
+	return headings, nil
+}
+
+// forEachDocsLine calls fn with the lines of a page and their numbers,
+// skipping front matter and fenced code blocks.
+func forEachDocsLine(data []byte, fn func(line string, number int)) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1<<20)
+	var fence string
+	frontMatter := false
+	for number := 1; scanner.Scan(); number++ {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case number == 1 && trimmed == "---":
+			frontMatter = true
+			continue
+		case frontMatter:
+			frontMatter = trimmed != "---"
+			continue
+		case fence != "":
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		case strings.HasPrefix(trimmed, "```"), strings.HasPrefix(trimmed, "~~~"):
+			fence = trimmed[:3]
+			continue
+		}
+		fn(line, number)
+	}
+}
+
+// parseDocsLinks returns the links in a page, except for images and links in
+// code.
+func parseDocsLinks(data []byte) []docsLink {
+	var links []docsLink
+	forEachDocsLine(data, func(line string, number int) {
+		line = docsInlineCodePattern.ReplaceAllString(line, "")
+		for _, m := range docsLinkPattern.FindAllStringSubmatch(line, -1) {
+			if m[1] == "!" {
+				continue
+			}
+			links = append(links, docsLink{Line: number, Target: m[2]})
+		}
+	})
+	return links
+}
+
+// parseDocsHeadings returns the anchors of the headings in a page. Anchors
+// are generated like Docusaurus does, unless a heading sets its ID with
+// {#id}.
+func parseDocsHeadings(data []byte) map[string]struct{} {
+	headings := make(map[string]struct{})
+	counts := make(map[string]int)
+	forEachDocsLine(data, func(line string, _ int) {
+		m := docsHeadingPattern.FindStringSubmatch(line)
+		if m == nil {
+			return
+		}
+		if id := docsHeadingIDPattern.FindStringSubmatch(m[1]); id != nil {
+			headings[id[1]] = struct{}{}
+			return
+		}
+		slug := headingSlug(m[1])
+		if n := counts[slug]; n > 0 {
+			headings[fmt.Sprintf("%v-%v", slug, n)] = struct{}{}
+		} else {
+			headings[slug] = struct{}{}
+		}
+		counts[slug]++
+	})
+	return headings
+}
+
+// headingSlug returns the anchor of a heading: the lower case text of the
+// heading without punctuation, with spaces replaced by dashes.
+func headingSlug(heading string) string {
+	// Keep the text of links.
+	heading = docsLinkPattern.ReplaceAllStringFunc(heading, func(link string) string {
+		text, _, _ := strings.Cut(strings.TrimPrefix(link, "!"), "](")
+		return strings.TrimPrefix(text, "[")
+	})
+
+	var slug strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(heading)) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsNumber(r), r == '-', r == '_':
+			slug.WriteRune(r)
+		case r == ' ':
+			slug.WriteRune('-')
+		}
+	}
+	return slug.String()
+}
+
+// escapeAnnotationData escapes the message of a GitHub Actions workflow
+// command.
+func escapeAnnotationData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeAnnotationProperty escapes a property of a GitHub Actions workflow
+// command.
+func escapeAnnotationProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
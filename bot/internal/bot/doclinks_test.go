@@ -0,0 +1,118 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bot
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/shared-workflows/bot/internal/env"
+	"github.com/gravitational/shared-workflows/bot/internal/github"
+)
+
+const testGuidePage = `---
+title: Guide
+---
+
+# Guide [beta](/nope/)
+
+## Intro
+
+See [MySQL](/databases/mysql/) and [the old MySQL setup](/database-access/mysql/#setup).
+Read [the intro](#intro), [the second intro](#intro-1) and [custom](#custom-id).
+Install with [step one](./other.mdx#step-1-install "Install").
+Broken: [missing page](/nope/), [missing anchor](/databases/mysql/#missing).
+Broken: [missing file](./missing.mdx) and [relative](../databases/postgres/).
+
+## Intro
+
+Skipped: [external](https://goteleport.com), ![image](../img/a.png), ` + "`[code](/nope/)`" + `.
+
+` + "```" + `
+[fenced](/nope/)
+## Not a heading
+` + "```" + `
+
+## Custom heading {#custom-id}
+`
+
+func TestCheckDocsLinks(t *testing.T) {
+	root := t.TempDir()
+	for name, content := range map[string]string{
+		"docs/config.json":                   `{"redirects": [{"source": "/database-access/mysql/", "destination": "/databases/mysql/", "permanent": true}]}`,
+		"docs/pages/guides/guide.mdx":        testGuidePage,
+		"docs/pages/guides/other.mdx":        "## Step 1: Install\n",
+		"docs/pages/databases/mysql.mdx":     "# MySQL\n\n## Setup\n",
+		"docs/pages/includes/partial.mdx":    "[missing page](/nope/)\n",
+		"docs/pages/databases/databases.mdx": "# Databases\n",
+	} {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+	}
+
+	b := &Bot{
+		c: &Config{
+			Environment: &env.Environment{Organization: "gravitational", Repository: "teleport", Number: 1},
+			GitHub: &fakeGithub{
+				files: []github.PullRequestFile{
+					{Name: "docs/pages/guides/guide.mdx", Status: github.StatusModified},
+					{Name: "docs/pages/includes/partial.mdx", Status: github.StatusModified},
+					{Name: "docs/pages/guides/removed.mdx", Status: github.StatusRemoved},
+					{Name: "lib/auth/auth.go", Status: github.StatusModified},
+				},
+			},
+		},
+	}
+
+	var out bytes.Buffer
+	err := b.CheckDocsLinks(context.Background(), root, &out)
+	require.ErrorContains(t, err, "found 5 broken links")
+	require.Equal(t, ""+
+		"::error file=docs/pages/guides/guide.mdx,line=5::Broken link /nope/: /nope/ is not a docs page or redirect\n"+
+		"::error file=docs/pages/guides/guide.mdx,line=12::Broken link /nope/: /nope/ is not a docs page or redirect\n"+
+		"::error file=docs/pages/guides/guide.mdx,line=12::Broken link /databases/mysql/#missing: docs/pages/databases/mysql.mdx has no heading with the anchor #missing\n"+
+		"::error file=docs/pages/guides/guide.mdx,line=13::Broken link ./missing.mdx: docs/pages/guides/missing.mdx does not exist\n"+
+		"::error file=docs/pages/guides/guide.mdx,line=13::Broken link ../databases/postgres/: /guides/databases/postgres/ is not a docs page or redirect\n",
+		out.String())
+
+	b.c.Environment.Number = 0
+	require.NoError(t, b.CheckDocsLinks(context.Background(), root, &out))
+}
+
+func TestParseDocsHeadings(t *testing.T) {
+	headings := parseDocsHeadings([]byte(testGuidePage))
+	require.Equal(t, map[string]struct{}{
+		"guide-beta": {},
+		"intro":      {},
+		"intro-1":    {},
+		"custom-id":  {},
+	}, headings)
+
+	require.Equal(t, "step-1-install-tsh--tctl", headingSlug("Step 1: Install `tsh` & `tctl`"))
+	require.Equal(t, "what-is-teleport", headingSlug("What is [Teleport](/)?"))
+}
+
+func TestEscapeAnnotation(t *testing.T) {
+	require.Equal(t, "100%25 done%0Anext", escapeAnnotationData("100% done\nnext"))
+	require.Equal(t, "a%3Ab%2Cc", escapeAnnotationProperty("a:b,c"))
+}
@@ -3,8 +3,12 @@ package bot
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/gravitational/shared-workflows/bot/internal/github"
@@ -30,6 +34,12 @@ type DocsRedirect struct {
 // any docs files and, if so, returns an error if any of these docs files does
 // not correspond to a new redirect in the docs configuration file.
 //
+// It also returns an error if a redirect destination is not a docs page, if
+// redirects form chains or cycles, or if a redirect source is still a page.
+// Only redirects the PR adds or changes are checked, compared to the docs
+// configuration on the base branch, so existing problems don't fail
+// unrelated PRs.
+//
 // teleportClonePath is a relative path to a gravitational/teleport clone. It is
 // assumed that there is a file called docs/config.json at the root of the
 // directory that lists redirects in the redirects field.
@@ -39,33 +49,223 @@ func (b *Bot) CheckDocsPathsForMissingRedirects(ctx context.Context, teleportClo
 		return nil
 	}
 
+	c, docsConfigPath, err := readDocsConfig(teleportClonePath)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	files, err := b.c.GitHub.ListFiles(ctx, b.c.Environment.Organization, b.c.Environment.Repository, b.c.Environment.Number)
+	if err != nil {
+		return trace.Wrap(err, "unable to fetch files for PR %v: %v", b.c.Environment.Number)
+	}
+
+	m := missingRedirectSources(c.Redirects, files)
+	if len(m) > 0 {
+		return trace.Errorf("docs config at %v is missing redirects for the following renamed or deleted pages: %v", docsConfigPath, strings.Join(m, ","))
+	}
+
+	pages, err := docsPages(teleportClonePath)
+	if err != nil {
+		return trace.Wrap(err, "unable to list Teleport documentation pages at %v", teleportClonePath)
+	}
+	base, err := b.baseDocsRedirects(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if problems := redirectProblems(c.Redirects, base, pages); len(problems) > 0 {
+		return trace.Errorf("docs config at %v has invalid redirects:\n%v", docsConfigPath, strings.Join(problems, "\n"))
+	}
+
+	return nil
+}
+
+// readDocsConfig reads docs/config.json from a gravitational/teleport clone
+// and returns it with its path.
+func readDocsConfig(teleportClonePath string) (*DocsConfig, string, error) {
 	if teleportClonePath == "" {
-		return trace.BadParameter("unable to load Teleport documentation config with an empty path")
+		return nil, "", trace.BadParameter("unable to load Teleport documentation config with an empty path")
 	}
 
-	docsConfigPath := filepath.Join(teleportClonePath, "docs", "config.json")
+	docsConfigPath := filepath.Join(teleportClonePath, filepath.FromSlash(docsConfigFile))
 	f, err := os.Open(docsConfigPath)
 	if err != nil {
-		return trace.BadParameter("unable to load Teleport documentation config at %v: %v", teleportClonePath, err)
+		return nil, "", trace.BadParameter("unable to load Teleport documentation config at %v: %v", teleportClonePath, err)
 	}
 	defer f.Close()
 
 	var c DocsConfig
 	if err := json.NewDecoder(f).Decode(&c); err != nil {
-		return trace.BadParameter("unable to load redirect configuration from %v: %v", docsConfigPath, err)
+		return nil, "", trace.BadParameter("unable to load redirect configuration from %v: %v", docsConfigPath, err)
 	}
+	return &c, docsConfigPath, nil
+}
 
-	files, err := b.c.GitHub.ListFiles(ctx, b.c.Environment.Organization, b.c.Environment.Repository, b.c.Environment.Number)
-	if err != nil {
-		return trace.Wrap(err, "unable to fetch files for PR %v: %v", b.c.Environment.Number)
+// docsConfigFile is the path of the docs configuration in a
+// gravitational/teleport repository.
+const docsConfigFile = "docs/config.json"
+
+// baseDocsRedirects returns the redirects of the docs configuration on the
+// base branch of the PR, or none if the base branch has no configuration.
+func (b *Bot) baseDocsRedirects(ctx context.Context) ([]DocsRedirect, error) {
+	data, err := b.c.GitHub.GetContents(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		docsConfigFile,
+		b.c.Environment.UnsafeBase)
+	switch {
+	case trace.IsNotFound(err):
+		return nil, nil
+	case err != nil:
+		return nil, trace.Wrap(err)
+	}
+	var c DocsConfig
+	if err := json.Unmarshal(data, &c); err != nil {
+		// The PR may fix a broken config, so check all of its redirects.
+		log.Printf("Failed to parse %v on the base branch, checking all redirects: %v.", docsConfigFile, err)
+		return nil, nil
 	}
+	return c.Redirects, nil
+}
 
-	m := missingRedirectSources(c.Redirects, files)
-	if len(m) > 0 {
-		return trace.Errorf("docs config at %v is missing redirects for the following renamed or deleted pages: %v", docsConfigPath, strings.Join(m, ","))
+// docsPages maps the URL paths of the docs pages in a gravitational/teleport
+// clone to the paths of their files, relative to the clone.
+func docsPages(teleportClonePath string) (map[string]string, error) {
+	pages := make(map[string]string)
+	err := filepath.WalkDir(filepath.Join(teleportClonePath, docsPrefix), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if ext := filepath.Ext(p); ext != ".mdx" && ext != ".md" {
+			return nil
+		}
+		rel, err := filepath.Rel(teleportClonePath, p)
+		if err != nil {
+			return err
+		}
+		// Partials are not pages.
+		if strings.Contains(filepath.ToSlash(rel), includeSegment) {
+			return nil
+		}
+		pages[toURLPath(rel)] = filepath.ToSlash(rel)
+		return nil
+	})
+	return pages, trace.Wrap(err)
+}
+
+// redirectDestination returns the URL path of a redirect destination without
+// its fragment or query and with a trailing slash, or false for redirects to
+// other sites.
+func redirectDestination(destination string) (string, bool) {
+	if strings.Contains(destination, "://") {
+		return "", false
+	}
+	if i := strings.IndexAny(destination, "#?"); i >= 0 {
+		destination = destination[:i]
 	}
+	if !strings.HasSuffix(destination, "/") {
+		destination += "/"
+	}
+	return destination, true
+}
 
-	return nil
+// redirectSource returns the URL path of a redirect source with a trailing
+// slash, like the paths of pages and redirect destinations.
+func redirectSource(source string) string {
+	if !strings.HasSuffix(source, "/") {
+		source += "/"
+	}
+	return source
+}
+
+// redirectMap maps the redirect sources in conf to the URL paths of their
+// destinations, skipping redirects to other sites.
+func redirectMap(conf []DocsRedirect) map[string]string {
+	next := make(map[string]string)
+	for _, r := range conf {
+		if destination, ok := redirectDestination(r.Destination); ok {
+			next[redirectSource(r.Source)] = destination
+		}
+	}
+	return next
+}
+
+// redirectProblems checks that every redirect in conf leads to a page in
+// pages in one step and that no redirect source is still a page. Only
+// problems involving a redirect that isn't in base, the redirects before the
+// change, are returned, each with a description.
+func redirectProblems(conf []DocsRedirect, base []DocsRedirect, pages map[string]string) []string {
+	next := redirectMap(conf)
+
+	// changed are the sources of the added or changed redirects.
+	changed := make(map[string]bool)
+	for _, r := range conf {
+		if !slices.Contains(base, r) {
+			changed[redirectSource(r.Source)] = true
+		}
+	}
+	involvesChanged := func(sources []string) bool {
+		return slices.ContainsFunc(sources, func(source string) bool {
+			return changed[source]
+		})
+	}
+
+	var problems []string
+	reported := make(map[string]struct{})
+	for _, r := range conf {
+		source := redirectSource(r.Source)
+		if _, ok := pages[source]; ok && changed[source] {
+			problems = append(problems, fmt.Sprintf("redirect source %v is still a docs page", r.Source))
+		}
+
+		destination, ok := redirectDestination(r.Destination)
+		if !ok {
+			continue
+		}
+		if _, ok := next[destination]; !ok {
+			if _, ok := pages[destination]; !ok && changed[source] {
+				problems = append(problems, fmt.Sprintf("redirect destination %v of %v is not a docs page", r.Destination, r.Source))
+			}
+			continue
+		}
+
+		// Follow the redirects from the destination until a path that is
+		// not redirected or a path that was already visited.
+		chain := []string{source}
+		visited := map[string]int{source: 0}
+		cur := destination
+		for {
+			if i, ok := visited[cur]; ok {
+				cycle := slices.Clone(chain[i:])
+				if !involvesChanged(cycle) {
+					break
+				}
+				// Report each cycle once, starting with its smallest path.
+				start := slices.Index(cycle, slices.Min(cycle))
+				cycle = append(cycle[start:], cycle[:start]...)
+				key := strings.Join(cycle, " → ")
+				if _, ok := reported[key]; !ok {
+					reported[key] = struct{}{}
+					problems = append(problems, fmt.Sprintf("redirect cycle: %v → %v", key, cycle[0]))
+				}
+				break
+			}
+			visited[cur] = len(chain)
+			chain = append(chain, cur)
+			n, ok := next[cur]
+			if !ok {
+				// Every path of the chain but the last is a redirect source.
+				if involvesChanged(chain[:len(chain)-1]) {
+					problems = append(problems, fmt.Sprintf("redirect chain: %v, redirect %v to %v instead", strings.Join(chain, " → "), r.Source, cur))
+				}
+				break
+			}
+			cur = n
+		}
+	}
+	return problems
 }
 
 var docsPrefix = filepath.Join("docs", "pages")
@@ -108,7 +308,7 @@ const includeSegment = "/includes/"
 func missingRedirectSources(conf []DocsRedirect, files github.PullRequestFiles) []string {
 	sources := make(map[string]struct{})
 	for _, s := range conf {
-		sources[s.Source] = struct{}{}
+		sources[redirectSource(s.Source)] = struct{}{}
 	}
 
 	// Make a map of URL paths for file paths introduced by this PR, either
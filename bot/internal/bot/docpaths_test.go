@@ -17,6 +17,7 @@ func TestCheckDocsPathsForMissingRedirects(t *testing.T) {
 		description       string
 		teleportClonePath string
 		docsConfig        string
+		baseDocsConfig    string
 		errorSubstring    string
 		number            int
 	}{
@@ -48,6 +49,75 @@ func TestCheckDocsPathsForMissingRedirects(t *testing.T) {
 			number:         1,
 			errorSubstring: "missing redirects for the following renamed or deleted pages: /database-access/get-started/",
 		},
+		{
+			description:       "redirect to a missing page",
+			teleportClonePath: "/teleport",
+			docsConfig: `{
+  "redirects": [
+      {
+      	  "source": "/database-access/get-started/",
+      	  "destination": "/enroll-resources/database-access/getting-started/",
+      	  "permanent": true
+      }
+  ]
+}`,
+			number:         1,
+			errorSubstring: "redirect destination /enroll-resources/database-access/getting-started/ of /database-access/get-started/ is not a docs page",
+		},
+		{
+			description:       "redirect chain",
+			teleportClonePath: "/teleport",
+			docsConfig: `{
+  "redirects": [
+      {
+      	  "source": "/database-access/get-started/",
+      	  "destination": "/enroll-resources/database-access/get-started/",
+      	  "permanent": true
+      },
+      {
+      	  "source": "/databases/get-started/",
+      	  "destination": "/database-access/get-started/",
+      	  "permanent": true
+      }
+  ]
+}`,
+			number:         1,
+			errorSubstring: "redirect chain: /databases/get-started/ → /database-access/get-started/ → /enroll-resources/database-access/get-started/",
+		},
+		{
+			description:       "redirect chain on the base branch",
+			teleportClonePath: "/teleport",
+			docsConfig: `{
+  "redirects": [
+      {
+      	  "source": "/database-access/get-started/",
+      	  "destination": "/enroll-resources/database-access/get-started/",
+      	  "permanent": true
+      },
+      {
+      	  "source": "/databases/get-started/",
+      	  "destination": "/database-access/get-started/",
+      	  "permanent": true
+      },
+      {
+      	  "source": "/databases/old/",
+      	  "destination": "/databases/missing/",
+      	  "permanent": true
+      }
+  ]
+}`,
+			baseDocsConfig: `{
+  "redirects": [
+      {
+      	  "source": "/databases/old/",
+      	  "destination": "/databases/missing/",
+      	  "permanent": true
+      }
+  ]
+}`,
+			number:         1,
+			errorSubstring: "redirect chain: /databases/get-started/ → /database-access/get-started/ → /enroll-resources/database-access/get-started/",
+		},
 		{
 			description:       "invalid config file",
 			teleportClonePath: "/teleport",
@@ -76,12 +146,21 @@ func TestCheckDocsPathsForMissingRedirects(t *testing.T) {
 			_, err = f.WriteString(c.docsConfig)
 			require.NoError(t, err)
 
+			page := filepath.Join(tmpdir, "teleport", "docs", "pages", "enroll-resources", "database-access", "get-started.mdx")
+			require.NoError(t, os.MkdirAll(filepath.Dir(page), 0777))
+			require.NoError(t, os.WriteFile(page, []byte("# Get started"), 0666))
+
+			contents := map[string][]byte{}
+			if c.baseDocsConfig != "" {
+				contents[docsConfigFile] = []byte(c.baseDocsConfig)
+			}
 			b := &Bot{
 				c: &Config{
 					Environment: &env.Environment{
 						Number: c.number,
 					},
 					GitHub: &fakeGithub{
+						contents: contents,
 						files: []github.PullRequestFile{
 							{
 								Name:         "docs/pages/enroll-resources/database-access/get-started.mdx",
@@ -99,6 +178,9 @@ func TestCheckDocsPathsForMissingRedirects(t *testing.T) {
 				return
 			}
 			assert.ErrorContains(t, err, c.errorSubstring)
+			if c.baseDocsConfig != "" {
+				assert.NotContains(t, err.Error(), "/databases/missing/")
+			}
 		})
 	}
 }
@@ -370,6 +452,123 @@ func TestMissingRedirectSources(t *testing.T) {
 	}
 }
 
+func TestRedirectProblems(t *testing.T) {
+	pages := map[string]string{
+		"/databases/":       "docs/pages/databases/databases.mdx",
+		"/databases/mysql/": "docs/pages/databases/mysql.mdx",
+		"/old/":             "docs/pages/old.mdx",
+	}
+
+	cases := []struct {
+		description string
+		redirects   []DocsRedirect
+		base        []DocsRedirect
+		expected    []string
+	}{
+		{
+			description: "valid redirects",
+			redirects: []DocsRedirect{
+				{Source: "/database-access/", Destination: "/databases/"},
+				{Source: "/database-access/mysql/", Destination: "/databases/mysql/#setup"},
+				{Source: "/database-access/postgres/", Destination: "/databases/mysql"},
+				{Source: "/external/", Destination: "https://goteleport.com/"},
+			},
+		},
+		{
+			description: "missing destination",
+			redirects: []DocsRedirect{
+				{Source: "/database-access/", Destination: "/databases/postgres/"},
+			},
+			expected: []string{
+				"redirect destination /databases/postgres/ of /database-access/ is not a docs page",
+			},
+		},
+		{
+			description: "source is still a page",
+			redirects: []DocsRedirect{
+				{Source: "/old/", Destination: "/databases/"},
+			},
+			expected: []string{
+				"redirect source /old/ is still a docs page",
+			},
+		},
+		{
+			description: "chain",
+			redirects: []DocsRedirect{
+				{Source: "/a/", Destination: "/b/"},
+				{Source: "/b/", Destination: "/c/"},
+				{Source: "/c/", Destination: "/databases/"},
+			},
+			expected: []string{
+				"redirect chain: /a/ → /b/ → /c/ → /databases/, redirect /a/ to /databases/ instead",
+				"redirect chain: /b/ → /c/ → /databases/, redirect /b/ to /databases/ instead",
+			},
+		},
+		{
+			description: "cycle",
+			redirects: []DocsRedirect{
+				{Source: "/b/", Destination: "/c/"},
+				{Source: "/c/", Destination: "/a/"},
+				{Source: "/a/", Destination: "/b/"},
+				{Source: "/d/", Destination: "/a/"},
+				{Source: "/e/", Destination: "/e/"},
+			},
+			expected: []string{
+				"redirect cycle: /a/ → /b/ → /c/ → /a/",
+				"redirect cycle: /e/ → /e/",
+			},
+		},
+		{
+			description: "sources without a trailing slash",
+			redirects: []DocsRedirect{
+				{Source: "/old", Destination: "/databases/"},
+				{Source: "/a", Destination: "/b"},
+				{Source: "/b", Destination: "/databases/"},
+			},
+			expected: []string{
+				"redirect source /old is still a docs page",
+				"redirect chain: /a/ → /b/ → /databases/, redirect /a to /databases/ instead",
+			},
+		},
+		{
+			description: "existing problems",
+			redirects: []DocsRedirect{
+				{Source: "/old/", Destination: "/databases/"},
+				{Source: "/a/", Destination: "/missing/"},
+				{Source: "/b/", Destination: "/c/"},
+				{Source: "/c/", Destination: "/databases/"},
+				{Source: "/d/", Destination: "/d/"},
+			},
+			base: []DocsRedirect{
+				{Source: "/old/", Destination: "/databases/"},
+				{Source: "/a/", Destination: "/missing/"},
+				{Source: "/b/", Destination: "/c/"},
+				{Source: "/c/", Destination: "/databases/"},
+				{Source: "/d/", Destination: "/d/"},
+			},
+		},
+		{
+			description: "chain through a new redirect",
+			redirects: []DocsRedirect{
+				{Source: "/a/", Destination: "/b/"},
+				{Source: "/b/", Destination: "/databases/"},
+			},
+			base: []DocsRedirect{
+				{Source: "/a/", Destination: "/b/"},
+			},
+			expected: []string{
+				"redirect chain: /a/ → /b/ → /databases/, redirect /a/ to /databases/ instead",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			assert.Equal(t, c.expected, redirectProblems(c.redirects, c.base, pages))
+		})
+	}
+}
+
 func Test_toURLPath(t *testing.T) {
 	cases := []struct {
 		description string
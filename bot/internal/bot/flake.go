@@ -2,8 +2,10 @@ package bot
 
 import (
 	"context"
+	"io"
 	"log"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/gravitational/shared-workflows/bot/internal/github"
@@ -15,16 +17,28 @@ const skipFlakePrefix = "/excludeflake"
 // ExcludeFlakes gets the list of test names that can be
 // excluded from flaky test detection for a particular PR.
 // Admin reviewers can exclude tests by commenting on a
-// PR with "/excludeflake Test1 Test2".
+// PR with "/excludeflake Test1 Test2". Tests in the flaky
+// test quarantine are excluded until their entry expires.
 //
 // The result is written to a GitHub Action output parameter
-// named FLAKE_SKIP.
-func (b *Bot) ExcludeFlakes(ctx context.Context) error {
+// named FLAKE_SKIP, warnings about expired quarantine entries
+// are written to w.
+func (b *Bot) ExcludeFlakes(ctx context.Context, w io.Writer) error {
 	skip, err := b.skipItems(ctx, skipFlakePrefix)
 	if err != nil {
 		return trace.Wrap(err)
 	}
 
+	q, err := b.loadFlakeQuarantine(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	for _, name := range q.activeTests(b.now(), w) {
+		if !slices.Contains(skip, name) {
+			skip = append(skip, name)
+		}
+	}
+
 	log.Printf("tests to skip: %v", strings.Join(skip, " "))
 
 	output := "FLAKE_SKIP=" + strings.Join(skip, " ")
@@ -0,0 +1,305 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bot
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gravitational/trace"
+	"gopkg.in/yaml.v3"
+
+	"github.com/gravitational/shared-workflows/bot/internal/s3"
+)
+
+const (
+	// defaultFlakeQuarantinePath is the path of the quarantine list in the
+	// repository.
+	defaultFlakeQuarantinePath = ".github/flaky-tests.yaml"
+	// quarantineDateLayout is the layout of quarantine expiry dates.
+	quarantineDateLayout = time.DateOnly
+	// candidateQuarantineDays is the number of days proposed quarantine
+	// entries last.
+	candidateQuarantineDays = 30
+)
+
+// flakeQuarantine is the list of quarantined flaky tests, which are skipped
+// by flaky test detection until they expire.
+type flakeQuarantine struct {
+	Tests []quarantinedTest `yaml:"tests"`
+}
+
+// quarantinedTest is a quarantined flaky test.
+type quarantinedTest struct {
+	// Name is the name of the test, for example TestFoo or TestFoo/bar.
+	Name string `yaml:"name"`
+	// Owner is the GitHub login of the person fixing the test.
+	Owner string `yaml:"owner"`
+	// Issue is the URL of the issue tracking the fix.
+	Issue string `yaml:"issue"`
+	// Expires is the date at which the test is no longer skipped, in
+	// YYYY-MM-DD format.
+	Expires string `yaml:"expires"`
+
+	expires time.Time
+}
+
+// parseFlakeQuarantine parses and validates a YAML quarantine list.
+func parseFlakeQuarantine(data []byte) (*flakeQuarantine, error) {
+	var q flakeQuarantine
+	if err := yaml.Unmarshal(data, &q); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for i := range q.Tests {
+		test := &q.Tests[i]
+		if test.Name == "" || test.Owner == "" || test.Issue == "" || test.Expires == "" {
+			return nil, trace.BadParameter("quarantined test %v: name, owner, issue and expires are required", i)
+		}
+		expires, err := time.Parse(quarantineDateLayout, test.Expires)
+		if err != nil {
+			return nil, trace.BadParameter("quarantined test %v: invalid expiry date %q, expected YYYY-MM-DD", test.Name, test.Expires)
+		}
+		test.expires = expires
+	}
+	return &q, nil
+}
+
+// activeTests returns the names of the tests that haven't expired at now.
+// Expired tests are written to w as warning annotations so their entries get
+// removed or extended.
+func (q *flakeQuarantine) activeTests(now time.Time, w io.Writer) []string {
+	var names []string
+	for _, test := range q.Tests {
+		// Tests are quarantined until the end of the expiry day.
+		if !now.Before(test.expires.AddDate(0, 0, 1)) {
+			fmt.Fprintf(w, "::warning::%v\n", escapeAnnotationData(fmt.Sprintf(
+				"Quarantine of %v expired on %v, remove it or extend it (owner: %v, issue: %v)",
+				test.Name, test.Expires, test.Owner, test.Issue)))
+			continue
+		}
+		names = append(names, test.Name)
+	}
+	return names
+}
+
+// loadFlakeQuarantine reads the quarantine list from the configured source.
+// A missing list in the repository is empty.
+func (b *Bot) loadFlakeQuarantine(ctx context.Context) (*flakeQuarantine, error) {
+	source := cmp.Or(b.c.FlakeQuarantine, defaultFlakeQuarantinePath)
+
+	var data []byte
+	u, err := url.Parse(source)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	switch u.Scheme {
+	case "file":
+		data, err = os.ReadFile(u.Path)
+	case "s3":
+		var client *s3.Client
+		client, err = s3.New(ctx, cmp.Or(b.c.S3, &s3.Config{}))
+		if err == nil {
+			data, err = client.GetObject(ctx, u.Host, strings.TrimPrefix(u.Path, "/"))
+		}
+	case "":
+		data, err = b.c.GitHub.GetContents(ctx,
+			b.c.Environment.Organization,
+			b.c.Environment.Repository,
+			source,
+			b.c.Environment.UnsafeBase)
+		if trace.IsNotFound(err) {
+			log.Printf("No %v found, no tests are quarantined.", source)
+			return &flakeQuarantine{}, nil
+		}
+	default:
+		return nil, trace.BadParameter("unsupported quarantine source %q, expected a repository path, file or s3", source)
+	}
+	if err != nil {
+		return nil, trace.Wrap(err, "loading the flaky test quarantine from %v", source)
+	}
+
+	q, err := parseFlakeQuarantine(data)
+	if err != nil {
+		return nil, trace.Wrap(err, "parsing %v", source)
+	}
+	return q, nil
+}
+
+// testHistoryRecord holds the fields of the ci-normalize testcase and meta
+// records needed to find flaky tests. Both record types are read from the
+// same files and told apart by their fields.
+type testHistoryRecord struct {
+	// MetaID links testcases to the meta record of their run.
+	MetaID string `json:"meta_id"`
+	// TestcaseID is only set on testcase records.
+	TestcaseID string `json:"testcase_id"`
+	// Name is the name of the test of testcase records.
+	Name string `json:"test_name"`
+	// Status is the result of testcase records: pass, failed, skipped or
+	// error.
+	Status string `json:"status"`
+	// SHA is the commit of meta records.
+	SHA string `json:"git_sha"`
+}
+
+// flakeCandidate is a test that failed and passed on the same commit.
+type flakeCandidate struct {
+	// Name is the name of the test.
+	Name string
+	// Commits is the number of commits the test both failed and passed on.
+	Commits int
+}
+
+// ProposeQuarantine reads the test history written by ci-normalize and
+// writes quarantine entries for the tests that failed and passed on the same
+// commit to w, most flaky first. Tests that are already quarantined are
+// skipped. The owner and issue of the entries must be filled in before they
+// are added to the quarantine list.
+//
+// patterns are globs of JSONL files with ci-normalize testcase and meta
+// records.
+func (b *Bot) ProposeQuarantine(ctx context.Context, patterns []string, w io.Writer) error {
+	var files []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		files = append(files, matches...)
+	}
+	if len(files) == 0 {
+		return trace.NotFound("no test history found in %v", strings.Join(patterns, ", "))
+	}
+
+	candidates, err := findFlakeCandidates(files)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	q, err := b.loadFlakeQuarantine(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	candidates = slices.DeleteFunc(candidates, func(c flakeCandidate) bool {
+		return slices.ContainsFunc(q.Tests, func(test quarantinedTest) bool { return test.Name == c.Name })
+	})
+
+	if len(candidates) == 0 {
+		fmt.Fprintln(w, "# No new flaky tests found.")
+		return nil
+	}
+	expires := b.now().AddDate(0, 0, candidateQuarantineDays).Format(quarantineDateLayout)
+	for _, c := range candidates {
+		fmt.Fprintf(w, "  # Failed and passed on %v commits.\n", c.Commits)
+		fmt.Fprintf(w, "  - name: %v\n    owner: \"\"\n    issue: \"\"\n    expires: %v\n", c.Name, expires)
+	}
+	return nil
+}
+
+// findFlakeCandidates returns the tests of the history files that failed and
+// passed on the same commit.
+func findFlakeCandidates(files []string) ([]flakeCandidate, error) {
+	shas := make(map[string]string)
+	var testcases []testHistoryRecord
+	for _, name := range files {
+		err := readJSONLines(name, func(r testHistoryRecord) {
+			switch {
+			case r.TestcaseID != "":
+				testcases = append(testcases, r)
+			case r.SHA != "":
+				shas[r.MetaID] = r.SHA
+			}
+		})
+		if err != nil {
+			return nil, trace.Wrap(err, "reading %v", name)
+		}
+	}
+
+	type result struct{ passed, failed bool }
+	results := make(map[string]map[string]*result)
+	for _, tc := range testcases {
+		sha, ok := shas[tc.MetaID]
+		if !ok {
+			continue
+		}
+		if results[tc.Name] == nil {
+			results[tc.Name] = make(map[string]*result)
+		}
+		r := results[tc.Name][sha]
+		if r == nil {
+			r = &result{}
+			results[tc.Name][sha] = r
+		}
+		switch tc.Status {
+		case "pass":
+			r.passed = true
+		case "failed", "error":
+			r.failed = true
+		}
+	}
+
+	var candidates []flakeCandidate
+	for name, bySHA := range results {
+		commits := 0
+		for _, r := range bySHA {
+			if r.passed && r.failed {
+				commits++
+			}
+		}
+		if commits > 0 {
+			candidates = append(candidates, flakeCandidate{Name: name, Commits: commits})
+		}
+	}
+	slices.SortFunc(candidates, func(a, b flakeCandidate) int {
+		return cmp.Or(cmp.Compare(b.Commits, a.Commits), cmp.Compare(a.Name, b.Name))
+	})
+	return candidates, nil
+}
+
+// readJSONLines decodes each line of a JSONL file and calls fn with it.
+func readJSONLines[T any](name string, fn func(T)) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 10<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var v T
+		if err := json.Unmarshal(scanner.Bytes(), &v); err != nil {
+			return trace.BadParameter("line %v: %v", line, err)
+		}
+		fn(v)
+	}
+	return trace.Wrap(scanner.Err())
+}
@@ -4,11 +4,15 @@ import (
 	"context"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gravitational/shared-workflows/bot/internal/env"
 	"github.com/gravitational/shared-workflows/bot/internal/github"
 	"github.com/gravitational/shared-workflows/bot/internal/review"
+	"github.com/gravitational/trace"
 	"github.com/stretchr/testify/require"
 )
 
@@ -43,8 +47,131 @@ func TestSkipFlakes(t *testing.T) {
 	t.Setenv(github.OutputEnv, f.Name())
 
 	// Validate that only the entries excluded by admins exist in the output
-	require.NoError(t, b.ExcludeFlakes(context.Background()))
+	require.NoError(t, b.ExcludeFlakes(context.Background(), io.Discard))
 	actual, err := io.ReadAll(f)
 	require.NoError(t, err)
 	require.Equal(t, "FLAKE_SKIP=TestFoo TestBar TestQuux", string(actual))
 }
+
+func TestFlakeQuarantine(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	gh := &fakeGithub{
+		contents: map[string][]byte{
+			"master:" + defaultFlakeQuarantinePath: []byte(`
+tests:
+  - name: TestFoo
+    owner: alice
+    issue: https://github.com/gravitational/teleport/issues/1
+    expires: 2026-03-10
+  - name: TestExpired
+    owner: bob
+    issue: https://github.com/gravitational/teleport/issues/2
+    expires: 2026-03-09
+  - name: TestBar/sub
+    owner: carol
+    issue: https://github.com/gravitational/teleport/issues/3
+    expires: 2026-04-01
+`),
+		},
+		comments: []github.Comment{comment("admin1", "/excludeflake TestFoo TestBaz")},
+	}
+	r, err := review.New(&review.Config{
+		Admins:            []string{"admin1"},
+		CoreReviewers:     make(map[string]review.Reviewer),
+		CloudReviewers:    make(map[string]review.Reviewer),
+		CodeReviewersOmit: make(map[string]bool),
+		DocsReviewers:     make(map[string]review.Reviewer),
+		DocsReviewersOmit: make(map[string]bool),
+	})
+	require.NoError(t, err)
+	b := &Bot{
+		c: &Config{
+			Environment: &env.Environment{Number: 1, UnsafeBase: "master"},
+			GitHub:      gh,
+			Review:      r,
+			Now:         func() time.Time { return now },
+		},
+	}
+
+	out := filepath.Join(t.TempDir(), "output")
+	t.Setenv(github.OutputEnv, out)
+	var warnings strings.Builder
+	require.NoError(t, b.ExcludeFlakes(context.Background(), &warnings))
+	actual, err := os.ReadFile(out)
+	require.NoError(t, err)
+	require.Equal(t, "FLAKE_SKIP=TestFoo TestBaz TestBar/sub", string(actual))
+	require.Equal(t, "::warning::Quarantine of TestExpired expired on 2026-03-09, remove it or extend it "+
+		"(owner: bob, issue: https://github.com/gravitational/teleport/issues/2)\n", warnings.String())
+
+	// Quarantined tests are skipped outside of PRs too.
+	b.c.Environment.Number = 0
+	require.NoError(t, b.ExcludeFlakes(context.Background(), io.Discard))
+	actual, err = os.ReadFile(out)
+	require.NoError(t, err)
+	require.Equal(t, "FLAKE_SKIP=TestFoo TestBar/sub", string(actual))
+
+	for _, invalid := range []string{
+		"tests: [{name: TestFoo, owner: alice, expires: 2026-03-10}]",
+		"tests: [{name: TestFoo, owner: alice, issue: x, expires: 03/10/2026}]",
+	} {
+		_, err := parseFlakeQuarantine([]byte(invalid))
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestProposeQuarantine(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, lines ...string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(strings.Join(lines, "\n")), 0o644))
+	}
+	write("meta.jsonl",
+		`{"meta_id": "run1", "git_sha": "sha1"}`,
+		`{"meta_id": "run2", "git_sha": "sha1"}`,
+		`{"meta_id": "run3", "git_sha": "sha2"}`,
+		`{"meta_id": "run4", "git_sha": "sha2"}`,
+	)
+	write("tests.jsonl",
+		`{"testcase_id": "a", "meta_id": "run1", "test_name": "TestFlaky", "status": "failed"}`,
+		`{"testcase_id": "b", "meta_id": "run2", "test_name": "TestFlaky", "status": "pass"}`,
+		`{"testcase_id": "c", "meta_id": "run3", "test_name": "TestFlaky", "status": "error"}`,
+		`{"testcase_id": "d", "meta_id": "run4", "test_name": "TestFlaky", "status": "pass"}`,
+		`{"testcase_id": "e", "meta_id": "run1", "test_name": "TestOnce", "status": "failed"}`,
+		`{"testcase_id": "f", "meta_id": "run2", "test_name": "TestOnce", "status": "pass"}`,
+		`{"testcase_id": "g", "meta_id": "run1", "test_name": "TestBroken", "status": "failed"}`,
+		`{"testcase_id": "h", "meta_id": "run2", "test_name": "TestBroken", "status": "failed"}`,
+		`{"testcase_id": "i", "meta_id": "run3", "test_name": "TestFixed", "status": "failed"}`,
+		`{"testcase_id": "j", "meta_id": "run1", "test_name": "TestQuarantined", "status": "failed"}`,
+		`{"testcase_id": "k", "meta_id": "run2", "test_name": "TestQuarantined", "status": "pass"}`,
+		``,
+	)
+
+	gh := &fakeGithub{
+		contents: map[string][]byte{
+			defaultFlakeQuarantinePath: []byte("tests: [{name: TestQuarantined, owner: alice, issue: x, expires: 2026-04-01}]"),
+		},
+	}
+	b := &Bot{
+		c: &Config{
+			Environment: &env.Environment{},
+			GitHub:      gh,
+			Now:         func() time.Time { return time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC) },
+		},
+	}
+
+	var out strings.Builder
+	require.NoError(t, b.ProposeQuarantine(context.Background(), []string{filepath.Join(dir, "*.jsonl")}, &out))
+	require.Equal(t, `  # Failed and passed on 2 commits.
+  - name: TestFlaky
+    owner: ""
+    issue: ""
+    expires: 2026-04-09
+  # Failed and passed on 1 commits.
+  - name: TestOnce
+    owner: ""
+    issue: ""
+    expires: 2026-04-09
+`, out.String())
+
+	err := b.ProposeQuarantine(context.Background(), []string{filepath.Join(dir, "*.json")}, &out)
+	require.True(t, trace.IsNotFound(err))
+}
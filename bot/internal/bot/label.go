@@ -18,7 +18,10 @@ package bot
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"log"
+	"slices"
 	"strings"
 
 	"github.com/gravitational/shared-workflows/bot/internal/env"
@@ -27,46 +30,124 @@ import (
 )
 
 // Label parses the content of the PR (branch name, files, etc) and sets
-// appropriate labels.
+// appropriate labels. Labels of rules that no longer match are removed.
 func (b *Bot) Label(ctx context.Context) error {
-	files, err := b.c.GitHub.ListFiles(ctx,
-		b.c.Environment.Organization,
-		b.c.Environment.Repository,
-		b.c.Environment.Number)
+	add, remove, err := b.labelDiff(ctx)
 	if err != nil {
 		return trace.Wrap(err)
 	}
 
-	labels, err := b.labels(ctx, files)
+	if len(add) > 0 {
+		err = b.c.GitHub.AddLabels(ctx,
+			b.c.Environment.Organization,
+			b.c.Environment.Repository,
+			b.c.Environment.Number,
+			add)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	for _, label := range remove {
+		log.Printf("Label: Removing label %v.", label)
+		err = b.c.GitHub.RemoveLabel(ctx,
+			b.c.Environment.Organization,
+			b.c.Environment.Repository,
+			b.c.Environment.Number,
+			label)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+
+	return nil
+}
+
+// LabelDryRun writes the labels that Label would add and remove to w
+// without changing the PR.
+func (b *Bot) LabelDryRun(ctx context.Context, w io.Writer) error {
+	add, remove, err := b.labelDiff(ctx)
 	if err != nil {
 		return trace.Wrap(err)
 	}
-	if len(labels) == 0 {
+
+	if len(add) == 0 && len(remove) == 0 {
+		fmt.Fprintln(w, "No label changes.")
 		return nil
 	}
+	for _, label := range add {
+		fmt.Fprintf(w, "+ %v\n", label)
+	}
+	for _, label := range remove {
+		fmt.Fprintf(w, "- %v\n", label)
+	}
+	return nil
+}
 
-	err = b.c.GitHub.AddLabels(ctx,
+// labelDiff returns the labels missing from the PR and the managed labels
+// on the PR that no rule adds anymore.
+func (b *Bot) labelDiff(ctx context.Context) ([]string, []string, error) {
+	pull, err := b.c.GitHub.GetPullRequest(ctx,
 		b.c.Environment.Organization,
 		b.c.Environment.Repository,
-		b.c.Environment.Number,
-		labels)
+		b.c.Environment.Number)
 	if err != nil {
-		return trace.Wrap(err)
+		return nil, nil, trace.Wrap(err)
 	}
 
-	return nil
+	files, err := b.c.GitHub.ListFiles(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		b.c.Environment.Number)
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+
+	// The config is read from the base branch, so the PR can't change the
+	// rules it is labeled with.
+	config, err := b.loadLabelConfig(ctx, pull.UnsafeBase.SHA)
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+
+	labels, err := b.labels(ctx, files, config)
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+
+	var add, remove []string
+	for _, label := range labels {
+		if !slices.Contains(pull.UnsafeLabels, label) {
+			add = append(add, label)
+		}
+	}
+	managed := config.managedLabels()
+	for _, label := range pull.UnsafeLabels {
+		if slices.Contains(managed, label) && !slices.Contains(labels, label) {
+			remove = append(remove, label)
+		}
+	}
+	slices.Sort(add)
+	slices.Sort(remove)
+
+	return add, remove, nil
 }
 
-// labels determines which labels should be applied to a PR
-func (b *Bot) labels(ctx context.Context, files []github.PullRequestFile) ([]string, error) {
+// labels determines which labels should be applied to a PR. A nil config
+// uses the compiled-in rules of the repository.
+func (b *Bot) labels(ctx context.Context, files []github.PullRequestFile, config *labelConfig) ([]string, error) {
+	if config == nil {
+		config = defaultLabelConfig(b.c.Environment.Repository)
+	}
+
 	var labels []string
 
+	size := prSize(files, &config.Size)
 	// don't add a size label to cloud deploy PRs since they are always xl
 	if !b.c.Environment.IsCloudDeployBranch() {
-		labels = append(labels, string(prSize(files)))
+		labels = append(labels, string(size))
 	}
 
-	c := classifyChanges(b.c, files)
+	c := classifyChanges(b.c, files, &config.Size)
 	if c.Docs && !c.Code {
 		log.Println("Label: Adding no-changelog because this is a docs-only change.")
 		labels = append(labels, NoChangelogLabel)
@@ -78,16 +159,13 @@ func (b *Bot) labels(ctx context.Context, files []github.PullRequestFile) ([]str
 		labels = append(labels, "backport")
 	}
 
-	for _, file := range files {
-		if strings.HasPrefix(file.Name, "vendor/") {
-			continue
-		}
-
-		for k, v := range prefixes[b.c.Environment.Repository] {
-			if strings.HasPrefix(file.Name, k) {
-				log.Printf("Label: Found prefix %v, attaching labels: %v.", k, v)
-				labels = append(labels, v...)
-			}
+	files = slices.DeleteFunc(slices.Clone(files), func(file github.PullRequestFile) bool {
+		return strings.HasPrefix(file.Name, "vendor/")
+	})
+	for _, rule := range config.Rules {
+		if rule.match(files, b.c.Environment.Author, b.c.Environment.UnsafeBase, size) {
+			log.Printf("Label: Found matching rule, attaching labels: %v.", rule.Labels)
+			labels = append(labels, rule.Labels...)
 		}
 	}
 
@@ -0,0 +1,205 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bot
+
+import (
+	"cmp"
+	"context"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/gravitational/trace"
+	"gopkg.in/yaml.v3"
+
+	"github.com/gravitational/shared-workflows/bot/internal/github"
+	"github.com/gravitational/shared-workflows/bot/internal/match"
+)
+
+// defaultLabelConfigPath is the path of the labeler config in the target
+// repository.
+const defaultLabelConfigPath = ".github/labeler.yaml"
+
+// labelConfig holds the labeling rules of a repository.
+type labelConfig struct {
+	// Rules are applied in order, the labels of every matching rule are
+	// added to the PR.
+	Rules []labelRule `yaml:"rules"`
+	// Size configures the size buckets of PRs, which are used for size
+	// labels and to require admin approval of xlarge PRs.
+	Size sizeConfig `yaml:"size"`
+}
+
+// labelRule adds labels to PRs that match all of its conditions. Empty
+// conditions match every PR.
+type labelRule struct {
+	// Labels are added to matching PRs.
+	Labels []string `yaml:"labels"`
+	// Paths are globs or prefixes, the rule matches if any changed file
+	// matches a path or one of the regexes.
+	Paths []string `yaml:"paths"`
+	// Regexes are regular expressions matched against changed files.
+	Regexes []string `yaml:"regexes"`
+	// Authors are logins or globs of PR authors.
+	Authors []string `yaml:"authors"`
+	// Branches are names or globs of base branches.
+	Branches []string `yaml:"branches"`
+	// Sizes are size buckets, for example "sm" or "size/xl".
+	Sizes []string `yaml:"sizes"`
+	// Keep leaves the labels on the PR when the rule stops matching.
+	// Otherwise they are removed.
+	Keep bool `yaml:"keep"`
+
+	regexes []*regexp.Regexp
+}
+
+// parseLabelConfig parses and validates a YAML labeler config.
+func parseLabelConfig(data []byte) (*labelConfig, error) {
+	var config labelConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	if err := config.Size.check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	for i := range config.Rules {
+		rule := &config.Rules[i]
+		if len(rule.Labels) == 0 {
+			return nil, trace.BadParameter("rule %v: missing labels", i)
+		}
+		if err := match.CheckGlobs(slices.Concat(rule.Paths, rule.Authors, rule.Branches)...); err != nil {
+			return nil, trace.Wrap(err, "rule %v", i)
+		}
+		for _, expr := range rule.Regexes {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, trace.BadParameter("rule %v: invalid regex %q: %v", i, expr, err)
+			}
+			rule.regexes = append(rule.regexes, re)
+		}
+		for j, size := range rule.Sizes {
+			label := sizeLabel(size)
+			if !strings.HasPrefix(size, "size/") {
+				label = sizeLabel("size/" + size)
+			}
+			if !slices.Contains(sizeLabels, label) {
+				return nil, trace.BadParameter("rule %v: unknown size %q", i, size)
+			}
+			rule.Sizes[j] = string(label)
+		}
+	}
+
+	return &config, nil
+}
+
+// defaultLabelConfig returns the compiled-in rules used by repositories
+// without a labeler config. Their labels are never removed.
+func defaultLabelConfig(repository string) *labelConfig {
+	var config labelConfig
+	for prefix, labels := range prefixes[repository] {
+		config.Rules = append(config.Rules, labelRule{
+			Labels: labels,
+			Paths:  []string{prefix},
+			Keep:   true,
+		})
+	}
+	config.Size.Thresholds = defaultSizeThresholds
+	return &config
+}
+
+// loadLabelConfig reads the labeler config and the generated files in
+// .gitattributes from ref, falling back to the compiled-in rules if the
+// repository has no labeler config.
+func (b *Bot) loadLabelConfig(ctx context.Context, ref string) (*labelConfig, error) {
+	path := cmp.Or(b.c.LabelConfigPath, defaultLabelConfigPath)
+	data, err := b.c.GitHub.GetContents(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		path,
+		ref)
+	var config *labelConfig
+	switch {
+	case trace.IsNotFound(err):
+		log.Printf("No %v found, using the default labels and sizes.", path)
+		config = defaultLabelConfig(b.c.Environment.Repository)
+	case err != nil:
+		return nil, trace.Wrap(err)
+	default:
+		config, err = parseLabelConfig(data)
+		if err != nil {
+			return nil, trace.Wrap(err, "parsing %v", path)
+		}
+	}
+
+	if err := b.loadGitAttributes(ctx, ref, &config.Size); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return config, nil
+}
+
+// match returns true if the PR matches all conditions of the rule.
+func (r *labelRule) match(files []github.PullRequestFile, author string, base string, size sizeLabel) bool {
+	if len(r.Authors) > 0 && !slices.ContainsFunc(r.Authors, func(pattern string) bool {
+		return match.Glob(pattern, author)
+	}) {
+		return false
+	}
+	if len(r.Branches) > 0 && !slices.ContainsFunc(r.Branches, func(pattern string) bool {
+		return match.Glob(pattern, base)
+	}) {
+		return false
+	}
+	if len(r.Sizes) > 0 && !slices.Contains(r.Sizes, string(size)) {
+		return false
+	}
+	if len(r.Paths) == 0 && len(r.regexes) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(files, func(file github.PullRequestFile) bool {
+		return r.matchFile(file.Name)
+	})
+}
+
+// matchFile returns true if name matches one of the paths or regexes.
+func (r *labelRule) matchFile(name string) bool {
+	for _, pattern := range r.Paths {
+		if match.Glob(pattern, name) || strings.HasPrefix(name, pattern) {
+			return true
+		}
+	}
+	return slices.ContainsFunc(r.regexes, func(re *regexp.Regexp) bool {
+		return re.MatchString(name)
+	})
+}
+
+// managedLabels returns the labels that are removed from the PR when no
+// rule adds them anymore.
+func (c *labelConfig) managedLabels() []string {
+	var labels []string
+	for _, s := range sizeLabels {
+		labels = append(labels, string(s))
+	}
+	for _, rule := range c.Rules {
+		if !rule.Keep {
+			labels = append(labels, rule.Labels...)
+		}
+	}
+	return labels
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package bot
 
 import (
+	"bytes"
 	"context"
 	"testing"
 
@@ -105,6 +106,22 @@ func TestLabel(t *testing.T) {
 			},
 			labels: []string{"ui", string(small)},
 		},
+		{
+			desc:   "prefixes are anchored",
+			repo:   "teleport",
+			branch: "foo",
+			files: []github.PullRequestFile{
+				{
+					Name:      "lib/web/x.go",
+					Additions: 1,
+				},
+				{
+					Name:      "lib/kubernetestoken/token.go",
+					Additions: 1,
+				},
+			},
+			labels: []string{"kubernetes-access", string(small)},
+		},
 		{
 			desc:   "teleport.e",
 			repo:   "teleport.e",
@@ -182,9 +199,204 @@ func TestLabel(t *testing.T) {
 					},
 				},
 			}
-			labels, err := b.labels(context.Background(), test.files)
+			labels, err := b.labels(context.Background(), test.files, nil)
 			require.NoError(t, err)
 			require.ElementsMatch(t, labels, test.labels)
 		})
 	}
 }
+
+const testLabelConfig = `
+rules:
+  - labels: [desktop-access]
+    paths: ["lib/srv/desktop/"]
+  - labels: [rdp]
+    regexes: ['^lib/.*/rdp/.*\.go$']
+    keep: true
+  - labels: [dependencies]
+    authors: ["dependabot[bot]", "renovate*"]
+  - labels: [backport-review]
+    branches: ["branch/*"]
+    paths: ["lib/auth/"]
+  - labels: [big-web]
+    paths: ["web/*/*.ts"]
+    sizes: [lg, size/xl]
+`
+
+// TestParseLabelConfig checks that invalid labeler configs are rejected.
+func TestParseLabelConfig(t *testing.T) {
+	config, err := parseLabelConfig([]byte(testLabelConfig))
+	require.NoError(t, err)
+	require.Len(t, config.Rules, 5)
+	require.Equal(t, []string{"size/lg", "size/xl"}, config.Rules[4].Sizes)
+	require.ElementsMatch(t, []string{
+		"size/sm", "size/md", "size/lg", "size/xl",
+		"desktop-access", "dependencies", "backport-review", "big-web",
+	}, config.managedLabels())
+
+	for _, invalid := range []string{
+		"rules: [{paths: [lib/]}]",
+		"rules: [{labels: [a], regexes: ['(']}]",
+		"rules: [{labels: [a], paths: ['[']}]",
+		"rules: [{labels: [a], sizes: [huge]}]",
+		"rules: {}",
+	} {
+		_, err := parseLabelConfig([]byte(invalid))
+		require.Error(t, err, invalid)
+	}
+}
+
+// TestLabelRules checks that all conditions of a rule must match.
+func TestLabelRules(t *testing.T) {
+	config, err := parseLabelConfig([]byte(testLabelConfig))
+	require.NoError(t, err)
+
+	tests := []struct {
+		desc   string
+		author string
+		branch string
+		files  []github.PullRequestFile
+		labels []string
+	}{
+		{
+			desc:   "prefix and regex",
+			author: "alice",
+			branch: "master",
+			files:  []github.PullRequestFile{{Name: "lib/srv/desktop/rdp/client.go"}},
+			labels: []string{"desktop-access", "rdp", string(small)},
+		},
+		{
+			desc:   "vendored files are skipped",
+			author: "alice",
+			branch: "master",
+			files:  []github.PullRequestFile{{Name: "vendor/lib/srv/desktop/rdp/client.go"}},
+			labels: []string{string(small)},
+		},
+		{
+			desc:   "author",
+			author: "dependabot[bot]",
+			branch: "master",
+			files:  []github.PullRequestFile{{Name: "go.mod"}},
+			labels: []string{"dependencies", string(small)},
+		},
+		{
+			desc:   "author glob",
+			author: "renovate-bot",
+			branch: "master",
+			files:  []github.PullRequestFile{{Name: "go.mod"}},
+			labels: []string{"dependencies", string(small)},
+		},
+		{
+			desc:   "base branch and path",
+			author: "alice",
+			branch: "branch/v17",
+			files:  []github.PullRequestFile{{Name: "lib/auth/auth.go"}},
+			labels: []string{"backport", "backport-review", string(small)},
+		},
+		{
+			desc:   "path without base branch",
+			author: "alice",
+			branch: "master",
+			files:  []github.PullRequestFile{{Name: "lib/auth/auth.go"}},
+			labels: []string{string(small)},
+		},
+		{
+			desc:   "size",
+			author: "alice",
+			branch: "master",
+			files:  []github.PullRequestFile{{Name: "web/packages/app.ts", Additions: 1000}},
+			labels: []string{"big-web", string(large)},
+		},
+		{
+			desc:   "too small",
+			author: "alice",
+			branch: "master",
+			files:  []github.PullRequestFile{{Name: "web/packages/app.ts", Additions: 10}},
+			labels: []string{string(small)},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			b := &Bot{
+				c: &Config{
+					Environment: &env.Environment{
+						Organization: "foo",
+						Repository:   "teleport",
+						Author:       test.author,
+						UnsafeBase:   test.branch,
+					},
+				},
+			}
+			labels, err := b.labels(context.Background(), test.files, config)
+			require.NoError(t, err)
+			require.ElementsMatch(t, test.labels, labels)
+		})
+	}
+}
+
+// TestLabelDiff checks that missing labels are added and labels of rules
+// that no longer match are removed.
+func TestLabelDiff(t *testing.T) {
+	tests := []struct {
+		desc     string
+		contents map[string][]byte
+		labels   []string
+		files    []github.PullRequestFile
+		add      []string
+		remove   []string
+		output   string
+	}{
+		{
+			desc:     "config",
+			contents: map[string][]byte{defaultLabelConfigPath: []byte(testLabelConfig)},
+			labels:   []string{"desktop-access", "rdp", "size/xl", "needs-review"},
+			files:    []github.PullRequestFile{{Name: "lib/auth/auth.go"}},
+			add:      []string{"size/sm"},
+			remove:   []string{"desktop-access", "size/xl"},
+			output:   "+ size/sm\n- desktop-access\n- size/xl\n",
+		},
+		{
+			desc:   "default rules are kept",
+			labels: []string{"desktop-access", "size/sm"},
+			files:  []github.PullRequestFile{{Name: "lib/auth/auth.go"}},
+			output: "No label changes.\n",
+		},
+		{
+			desc:   "default rules",
+			labels: []string{"size/md"},
+			files:  []github.PullRequestFile{{Name: "tool/tsh/tsh.go"}},
+			add:    []string{"size/sm", "tsh"},
+			remove: []string{"size/md"},
+			output: "+ size/sm\n+ tsh\n- size/md\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			gh := &fakeGithub{
+				pull:     github.PullRequest{UnsafeLabels: test.labels},
+				files:    test.files,
+				contents: test.contents,
+			}
+			b := &Bot{
+				c: &Config{
+					Environment: &env.Environment{
+						Organization: "foo",
+						Repository:   "teleport",
+						UnsafeBase:   "master",
+					},
+					GitHub: gh,
+				},
+			}
+
+			var out bytes.Buffer
+			require.NoError(t, b.LabelDryRun(context.Background(), &out))
+			require.Equal(t, test.output, out.String())
+			require.Empty(t, gh.labels)
+			require.Empty(t, gh.removed)
+
+			require.NoError(t, b.Label(context.Background()))
+			require.Equal(t, test.add, gh.labels)
+			require.Equal(t, test.remove, gh.removed)
+		})
+	}
+}
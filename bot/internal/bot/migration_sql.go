@@ -0,0 +1,278 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bot
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/shared-workflows/bot/internal/github"
+)
+
+// sqlIgnoreDirective is the SQL comment that skips the checks of a
+// statement, for example when an index is created on a small table.
+const sqlIgnoreDirective = "verify:ignore"
+
+// sqlCheck flags a dangerous SQL statement.
+type sqlCheck struct {
+	// pattern matches the normalized statement.
+	pattern *regexp.Regexp
+	// unless matches statements that are safe despite matching pattern.
+	unless *regexp.Regexp
+	// error is true if the statement fails verification, otherwise it is
+	// a warning.
+	error bool
+	// reason explains why the statement is dangerous.
+	reason string
+}
+
+// sqlChecks are the checks of up migrations. Statements are normalized to
+// upper case with single spaces and without comments before matching.
+var sqlChecks = []sqlCheck{
+	{
+		pattern: regexp.MustCompile(`^CREATE (?:UNIQUE )?INDEX `),
+		unless:  regexp.MustCompile(`^CREATE (?:UNIQUE )?INDEX CONCURRENTLY `),
+		error:   true,
+		reason:  "CREATE INDEX without CONCURRENTLY blocks writes to the table while the index is built",
+	},
+	{
+		pattern: regexp.MustCompile(`^ALTER TABLE .* ALTER (?:COLUMN )?\S+ (?:SET DATA )?TYPE `),
+		error:   true,
+		reason:  "changing the type of a column rewrites the table under an exclusive lock",
+	},
+	{
+		pattern: regexp.MustCompile(`^ALTER TABLE .* ADD (?:CONSTRAINT \S+ )?(?:FOREIGN KEY|CHECK)\b`),
+		unless:  regexp.MustCompile(` NOT VALID$`),
+		error:   true,
+		reason:  "adding a constraint without NOT VALID scans the table under a lock, validate it in a separate statement",
+	},
+	{
+		pattern: regexp.MustCompile(`^ALTER TABLE .* DROP (?:COLUMN )?`),
+		unless:  regexp.MustCompile(`^ALTER TABLE .* DROP (?:CONSTRAINT|DEFAULT|NOT NULL)\b`),
+		reason:  "dropping a column is not reversible, its data is lost",
+	},
+	{
+		pattern: regexp.MustCompile(`^DROP TABLE `),
+		reason:  "dropping a table is not reversible, its data is lost",
+	},
+	{
+		pattern: regexp.MustCompile(`^TRUNCATE `),
+		reason:  "truncating a table is not reversible, its data is lost",
+	},
+	{
+		pattern: regexp.MustCompile(`^ALTER TABLE .* RENAME `),
+		reason:  "renaming breaks the code of the previous release, which still runs during a rollout",
+	},
+}
+
+var (
+	// sqlCreateTablePattern matches CREATE TABLE statements, capturing the
+	// table name.
+	sqlCreateTablePattern = regexp.MustCompile(`^CREATE TABLE (?:IF NOT EXISTS )?(\S+?)\s*\(`)
+	// sqlIndexTablePattern matches the table of CREATE INDEX statements.
+	sqlIndexTablePattern = regexp.MustCompile(` ON (?:ONLY )?(\S+?)\s*(?:USING |\()`)
+)
+
+// sqlStatement is a statement of a migration.
+type sqlStatement struct {
+	// Line is the line number where the statement starts.
+	Line int
+	// Text is the statement in upper case, with comments removed and
+	// spaces collapsed.
+	Text string
+	// Ignore is true if the statement has a verify:ignore comment.
+	Ignore bool
+}
+
+// sqlFinding is a dangerous statement found in a migration.
+type sqlFinding struct {
+	// Line is the line number of the statement.
+	Line int
+	// Error is true if the statement fails verification.
+	Error bool
+	// Reason explains why the statement is dangerous.
+	Reason string
+}
+
+// verifyMigrationSQL checks the SQL of the up migrations added or changed by
+// the PR. Warnings are written to w as workflow annotations, errors fail
+// verification.
+func (b *Bot) verifyMigrationSQL(ctx context.Context, pull github.PullRequest, pathPrefix string, files []github.PullRequestFile, w io.Writer) error {
+	var errs []string
+	for _, f := range files {
+		if !strings.HasPrefix(f.Name, pathPrefix) || !strings.HasSuffix(f.Name, ".sql") || strings.HasSuffix(f.Name, ".down.sql") {
+			continue
+		}
+		content, err := b.c.GitHub.GetContents(ctx,
+			b.c.Environment.Organization,
+			b.c.Environment.Repository,
+			f.Name,
+			pull.UnsafeHead.SHA)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		for _, finding := range checkMigrationSQL(string(content)) {
+			if finding.Error {
+				errs = append(errs, fmt.Sprintf("%v:%v: %v", f.Name, finding.Line, finding.Reason))
+				continue
+			}
+			fmt.Fprintf(w, "::warning file=%v,line=%v::%v\n", escapeAnnotationProperty(f.Name), finding.Line, escapeAnnotationData(finding.Reason))
+		}
+	}
+	if len(errs) > 0 {
+		return trace.BadParameter("migrations have dangerous statements, rewrite them or add a %q comment to the statement:\n%v",
+			"-- "+sqlIgnoreDirective, strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// checkMigrationSQL returns the dangerous statements of a migration.
+// Indexes on tables created by the same migration don't need CONCURRENTLY as
+// nothing uses the table yet.
+func checkMigrationSQL(sql string) []sqlFinding {
+	statements := splitSQLStatements(sql)
+
+	created := make(map[string]bool)
+	for _, stmt := range statements {
+		if m := sqlCreateTablePattern.FindStringSubmatch(stmt.Text); m != nil {
+			created[m[1]] = true
+		}
+	}
+
+	var findings []sqlFinding
+	for _, stmt := range statements {
+		if stmt.Ignore {
+			continue
+		}
+		for _, check := range sqlChecks {
+			if !check.pattern.MatchString(stmt.Text) || (check.unless != nil && check.unless.MatchString(stmt.Text)) {
+				continue
+			}
+			if m := sqlIndexTablePattern.FindStringSubmatch(stmt.Text); m != nil && created[m[1]] && strings.HasPrefix(stmt.Text, "CREATE ") {
+				continue
+			}
+			findings = append(findings, sqlFinding{Line: stmt.Line, Error: check.error, Reason: check.reason})
+			break
+		}
+	}
+	return findings
+}
+
+// splitSQLStatements splits SQL on semicolons, ignoring the ones in quotes,
+// dollar-quoted strings and comments.
+func splitSQLStatements(sql string) []sqlStatement {
+	var statements []sqlStatement
+	var text strings.Builder
+	line, start := 1, 0
+	ignore := false
+
+	flush := func() {
+		if s := strings.TrimSpace(text.String()); s != "" {
+			statements = append(statements, sqlStatement{
+				Line:   start,
+				Text:   strings.ToUpper(strings.Join(strings.Fields(s), " ")),
+				Ignore: ignore,
+			})
+		}
+		text.Reset()
+		start, ignore = 0, false
+	}
+	write := func(s string) {
+		if start == 0 && strings.TrimSpace(s) != "" {
+			start = line
+		}
+		text.WriteString(s)
+		line += strings.Count(s, "\n")
+	}
+
+	for i := 0; i < len(sql); {
+		rest := sql[i:]
+		var n int
+		switch {
+		case strings.HasPrefix(rest, "--"):
+			n = strings.IndexByte(rest, '\n')
+			if n < 0 {
+				n = len(rest)
+			}
+			ignore = ignore || strings.Contains(rest[:n], sqlIgnoreDirective)
+			line += strings.Count(rest[:n], "\n")
+			i += n
+			continue
+		case strings.HasPrefix(rest, "/*"):
+			n = strings.Index(rest[2:], "*/")
+			if n < 0 {
+				n = len(rest)
+			} else {
+				n += 4
+			}
+			ignore = ignore || strings.Contains(rest[:n], sqlIgnoreDirective)
+			line += strings.Count(rest[:n], "\n")
+			text.WriteString(" ")
+			i += n
+			continue
+		case rest[0] == '\'' || rest[0] == '"':
+			n = quotedLength(rest, rest[:1])
+		case rest[0] == '$':
+			if tag := dollarQuoteTag(rest); tag != "" {
+				n = quotedLength(rest, tag)
+			} else {
+				n = 1
+			}
+		case rest[0] == ';':
+			flush()
+			i++
+			continue
+		default:
+			n = 1
+		}
+		write(rest[:n])
+		i += n
+	}
+	flush()
+	return statements
+}
+
+// quotedLength returns the length of the string at the start of s, which is
+// delimited by quote. Unterminated strings run to the end of s.
+func quotedLength(s string, quote string) int {
+	end := strings.Index(s[len(quote):], quote)
+	if end < 0 {
+		return len(s)
+	}
+	return len(quote) + end + len(quote)
+}
+
+// dollarQuoteTag returns the tag of a dollar-quoted string at the start of s,
+// for example "$$" or "$body$", or an empty string if there is none.
+func dollarQuoteTag(s string) string {
+	end := strings.IndexByte(s[1:], '$')
+	if end < 0 {
+		return ""
+	}
+	tag := s[:end+2]
+	for _, r := range tag[1 : len(tag)-1] {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return ""
+		}
+	}
+	return tag
+}
@@ -0,0 +1,364 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/shared-workflows/bot/internal/env"
+	"github.com/gravitational/shared-workflows/bot/internal/github"
+	"github.com/gravitational/shared-workflows/bot/internal/review"
+)
+
+// waitingOnAuthorLabel is the label of PRs that wait for the author to
+// address requested changes.
+const waitingOnAuthorLabel = "waiting-on-author"
+
+var (
+	// nudgeRemindedPattern matches the marker of reminder comments, capturing
+	// the reminded reviewers.
+	nudgeRemindedPattern = regexp.MustCompile(`<!-- bot:nudge reminded=([^ ]*) -->`)
+	// nudgeReassignedPattern matches the marker of reassignment comments,
+	// capturing the replaced and new reviewers.
+	nudgeReassignedPattern = regexp.MustCompile(`<!-- bot:nudge reassigned=([^ ]*) -->`)
+)
+
+// Nudge follows up on the review requests of open PRs. Reviewers that
+// haven't responded to a request for remindAfter are reminded with a
+// comment mentioning them, and after reassignAfter the request is moved to
+// another reviewer from the same set, see review.Assignments.Alternate.
+// Reviewers that are omitted or away aren't reminded but are replaced after
+// remindAfter. A zero reassignAfter never replaces reviewers, and reviewers
+// that were replaced on a PR aren't picked for it again.
+//
+// PRs where a reviewer requested changes and didn't get a new review
+// request are labeled as waiting on the author instead, and their reviewers
+// aren't nudged. Draft PRs are skipped.
+//
+// Review requests are timed from their latest "review_requested" event, or
+// from when the PR was last marked ready for review. Reviewers whose request
+// has no event are never nudged.
+//
+// Nudge is meant to run on a schedule. Reminders and replacements are
+// recorded in the comments of the bot, so each reminder happens once per
+// review request.
+func (b *Bot) Nudge(ctx context.Context, remindAfter time.Duration, reassignAfter time.Duration) error {
+	if remindAfter <= 0 {
+		return trace.BadParameter("remind after must be positive")
+	}
+	if reassignAfter != 0 && reassignAfter < remindAfter {
+		return trace.BadParameter("reassign after (%v) must not be shorter than remind after (%v)", reassignAfter, remindAfter)
+	}
+
+	pulls, err := b.c.GitHub.ListPullRequests(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		"open")
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	var load review.Load
+	if b.c.Review.LoadBalance() {
+		load, err = b.reviewerLoad(ctx)
+		if err != nil {
+			log.Printf("Nudge: Failed to find reviewer load: %v. Falling back to random assignment.", err)
+		}
+	}
+
+	n := &nudger{
+		remindAfter:   remindAfter,
+		reassignAfter: reassignAfter,
+		load:          load,
+	}
+	var errs []error
+	for _, pull := range pulls {
+		if pull.Draft {
+			continue
+		}
+		// Each PR is handled by a bot with the environment of the PR.
+		c := *b.c
+		c.Environment = &env.Environment{
+			Organization: b.c.Environment.Organization,
+			Repository:   b.c.Environment.Repository,
+			Number:       pull.Number,
+			Author:       pull.Author,
+			UnsafeBase:   pull.UnsafeBase.Ref,
+			UnsafeHead:   pull.UnsafeHead.Ref,
+		}
+		if err := n.nudge(ctx, &Bot{c: &c}, pull); err != nil {
+			log.Printf("Nudge: Failed to nudge reviewers of #%v: %v.", pull.Number, err)
+			errs = append(errs, trace.Wrap(err, "nudging reviewers of #%v", pull.Number))
+		}
+	}
+	return trace.NewAggregate(errs...)
+}
+
+// nudger nudges the reviewers of PRs.
+type nudger struct {
+	// remindAfter is how long a review request waits before the reviewer
+	// is reminded.
+	remindAfter time.Duration
+	// reassignAfter is how long a review request waits before it is moved
+	// to another reviewer, zero never moves requests.
+	reassignAfter time.Duration
+	// load is the number of outstanding review requests of each reviewer,
+	// nil picks alternate reviewers at random.
+	load review.Load
+}
+
+// nudge reminds and replaces the reviewers of a PR, b has the environment
+// of the PR.
+func (n *nudger) nudge(ctx context.Context, b *Bot, pull github.PullRequest) error {
+	e := b.c.Environment
+	reviews, err := b.c.GitHub.ListReviews(ctx, e.Organization, e.Repository, e.Number)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	pending, err := b.c.GitHub.ListReviewers(ctx, e.Organization, e.Repository, e.Number)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	waiting := waitingOnAuthor(reviews, pending)
+	labeled := slices.Contains(pull.UnsafeLabels, waitingOnAuthorLabel)
+	switch {
+	case waiting && !labeled:
+		log.Printf("Nudge: #%v is waiting on the author.", e.Number)
+		if err := b.c.GitHub.AddLabels(ctx, e.Organization, e.Repository, e.Number, []string{waitingOnAuthorLabel}); err != nil {
+			return trace.Wrap(err)
+		}
+	case !waiting && labeled:
+		if err := b.c.GitHub.RemoveLabel(ctx, e.Organization, e.Repository, e.Number, waitingOnAuthorLabel); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	if waiting {
+		return nil
+	}
+
+	comments, err := b.c.GitHub.ListComments(ctx, e.Organization, e.Repository, e.Number)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	history := parseNudgeHistory(comments, b.login())
+	timeline, err := b.c.GitHub.ListTimeline(ctx, e.Organization, e.Repository, e.Number)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	requests := reviewRequests(timeline)
+
+	// Reviewers that were requested, reviewed or replaced aren't picked as
+	// alternates.
+	exclude := slices.Concat(pending, history.replaced)
+	for _, r := range reviews {
+		exclude = append(exclude, r.Author)
+	}
+
+	now := b.now()
+	var remind []string
+	var replaced, alternates []string
+	for _, reviewer := range pending {
+		requested, ok := requests[reviewer]
+		if !ok {
+			log.Printf("Nudge: No review request of %v found on #%v, skipping.", reviewer, e.Number)
+			continue
+		}
+		for _, r := range reviews {
+			if r.Author == reviewer && r.SubmittedAt.After(requested) {
+				requested = r.SubmittedAt
+			}
+		}
+		waited := now.Sub(requested)
+		unavailable := b.c.Review.IsUnavailable(reviewer)
+
+		if n.reassignAfter > 0 && (waited >= n.reassignAfter || unavailable && waited >= n.remindAfter) {
+			alternate, err := b.c.Review.Alternate(e, reviewer, exclude, n.load)
+			if err == nil {
+				log.Printf("Nudge: Replacing %v with %v on #%v.", reviewer, alternate, e.Number)
+				replaced = append(replaced, reviewer)
+				alternates = append(alternates, alternate)
+				exclude = append(exclude, alternate)
+				if n.load != nil {
+					n.load[reviewer]--
+					n.load[alternate]++
+				}
+				continue
+			}
+			log.Printf("Nudge: Can't replace %v on #%v: %v.", reviewer, e.Number, err)
+		}
+
+		reminded, ok := history.reminded[reviewer]
+		if waited >= n.remindAfter && !unavailable && (!ok || reminded.Before(requested)) {
+			remind = append(remind, reviewer)
+		}
+	}
+
+	if len(replaced) > 0 {
+		if err := b.c.GitHub.RequestReviewers(ctx, e.Organization, e.Repository, e.Number, alternates); err != nil {
+			return trace.Wrap(err)
+		}
+		if err := b.c.GitHub.DismissReviewers(ctx, e.Organization, e.Repository, e.Number, replaced); err != nil {
+			return trace.Wrap(err)
+		}
+		if err := b.c.GitHub.CreateComment(ctx, e.Organization, e.Repository, e.Number,
+			reassignedComment(replaced, alternates, n.reassignAfter)); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	if len(remind) > 0 {
+		log.Printf("Nudge: Reminding %v on #%v.", remind, e.Number)
+		if err := b.c.GitHub.CreateComment(ctx, e.Organization, e.Repository, e.Number,
+			remindedComment(remind, n.remindAfter)); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// waitingOnAuthor returns true if a reviewer requested changes and hasn't
+// been requested to review again, so the author has to act.
+func waitingOnAuthor(reviews []github.Review, pending []string) bool {
+	latest := make(map[string]string)
+	for _, r := range reviews {
+		// Comments don't replace the previous state of the review.
+		if r.State == review.Commented && latest[r.Author] != "" {
+			continue
+		}
+		latest[r.Author] = r.State
+	}
+	for reviewer, state := range latest {
+		if state == review.ChangesRequested && !slices.Contains(pending, reviewer) {
+			return true
+		}
+	}
+	return false
+}
+
+// reviewRequests returns when each reviewer was requested to review, from
+// the timeline of a PR. Requests made before the PR was last marked ready
+// for review are timed from then, as drafts aren't reviewed.
+func reviewRequests(timeline []github.TimelineEvent) map[string]time.Time {
+	var ready time.Time
+	for _, event := range timeline {
+		if event.Event == "ready_for_review" && event.CreatedAt.After(ready) {
+			ready = event.CreatedAt
+		}
+	}
+	requests := make(map[string]time.Time)
+	for _, event := range timeline {
+		if event.Event != "review_requested" || event.RequestedReviewer == "" {
+			continue
+		}
+		requested := event.CreatedAt
+		if ready.After(requested) {
+			requested = ready
+		}
+		if requested.After(requests[event.RequestedReviewer]) {
+			requests[event.RequestedReviewer] = requested
+		}
+	}
+	return requests
+}
+
+// nudgeHistory is the nudges recorded in the comments of a PR.
+type nudgeHistory struct {
+	// reminded is when each reviewer was last reminded.
+	reminded map[string]time.Time
+	// replaced are the reviewers whose review requests were moved to
+	// another reviewer.
+	replaced []string
+}
+
+// parseNudgeHistory returns the nudges recorded in the comments posted by
+// login, others can't fake them.
+func parseNudgeHistory(comments []github.Comment, login string) nudgeHistory {
+	history := nudgeHistory{
+		reminded: make(map[string]time.Time),
+	}
+	for _, comment := range comments {
+		if comment.Author != login {
+			continue
+		}
+		if m := nudgeRemindedPattern.FindStringSubmatch(comment.Body); m != nil {
+			for _, reviewer := range strings.Split(m[1], ",") {
+				if comment.CreatedAt.After(history.reminded[reviewer]) {
+					history.reminded[reviewer] = comment.CreatedAt
+				}
+			}
+		}
+		if m := nudgeReassignedPattern.FindStringSubmatch(comment.Body); m != nil {
+			for _, pair := range strings.Split(m[1], ",") {
+				if replaced, _, ok := strings.Cut(pair, ":"); ok {
+					history.replaced = append(history.replaced, replaced)
+				}
+			}
+		}
+	}
+	return history
+}
+
+// remindedComment returns the comment that reminds reviewers of a review
+// request.
+func remindedComment(reviewers []string, after time.Duration) string {
+	return fmt.Sprintf("%v - this PR has been waiting for your review for more than %v. "+
+		"Please review it or let the author know if someone else should.\n\n<!-- bot:nudge reminded=%v -->",
+		mentions(reviewers), formatWait(after), strings.Join(reviewers, ","))
+}
+
+// reassignedComment returns the comment that announces that review
+// requests were moved to alternate reviewers.
+func reassignedComment(replaced []string, alternates []string, after time.Duration) string {
+	var sb strings.Builder
+	var pairs []string
+	fmt.Fprintf(&sb, "Review requests that got no response for more than %v, or whose reviewer is away, were moved:\n\n", formatWait(after))
+	for i := range replaced {
+		fmt.Fprintf(&sb, "* %v → @%v\n", replaced[i], alternates[i])
+		pairs = append(pairs, replaced[i]+":"+alternates[i])
+	}
+	fmt.Fprintf(&sb, "\n<!-- bot:nudge reassigned=%v -->", strings.Join(pairs, ","))
+	return sb.String()
+}
+
+// mentions returns the @-mentions of logins.
+func mentions(logins []string) string {
+	var mentioned []string
+	for _, login := range logins {
+		mentioned = append(mentioned, "@"+login)
+	}
+	return strings.Join(mentioned, " ")
+}
+
+// formatWait formats a wait in days when it is a whole number of days.
+func formatWait(d time.Duration) string {
+	const day = 24 * time.Hour
+	switch {
+	case d == day:
+		return "1 day"
+	case d%day == 0:
+		return fmt.Sprintf("%v days", int(d/day))
+	}
+	return d.String()
+}
@@ -0,0 +1,251 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/shared-workflows/bot/internal/env"
+	"github.com/gravitational/shared-workflows/bot/internal/github"
+	"github.com/gravitational/shared-workflows/bot/internal/review"
+)
+
+func TestNudge(t *testing.T) {
+	opened := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	day := func(n float64) time.Time {
+		return opened.Add(time.Duration(n * float64(24*time.Hour)))
+	}
+	requested := func(at time.Time, reviewers ...string) []github.TimelineEvent {
+		var events []github.TimelineEvent
+		for _, reviewer := range reviewers {
+			events = append(events, github.TimelineEvent{Event: "review_requested", CreatedAt: at, RequestedReviewer: reviewer})
+		}
+		return events
+	}
+
+	tests := []struct {
+		desc       string
+		draft      bool
+		labels     []string
+		omit       map[string]bool
+		pending    []string
+		reviews    []github.Review
+		comments   []github.Comment
+		timeline   []github.TimelineEvent
+		now        time.Time
+		requested  []string
+		dismissed  []string
+		reminded   string
+		reassigned string
+		added      []string
+		removed    []string
+	}{
+		{
+			desc:    "recent request",
+			pending: []string{"alice", "carol"},
+			now:     day(1),
+		},
+		{
+			desc:     "reviewers are reminded",
+			pending:  []string{"alice", "carol"},
+			now:      day(2),
+			reminded: "alice,carol",
+		},
+		{
+			desc:    "reviewers are reminded once",
+			pending: []string{"alice", "carol"},
+			comments: []github.Comment{
+				{Author: defaultLogin, Body: remindedComment([]string{"alice", "carol"}, 48*time.Hour), CreatedAt: day(2)},
+			},
+			now: day(3),
+		},
+		{
+			desc:    "reminders of others don't count",
+			pending: []string{"alice", "carol"},
+			comments: []github.Comment{
+				{Author: "dev", Body: remindedComment([]string{"alice", "carol"}, 48*time.Hour), CreatedAt: day(2)},
+			},
+			now:      day(3),
+			reminded: "alice,carol",
+		},
+		{
+			desc:    "re-requested reviewer",
+			pending: []string{"alice", "carol"},
+			reviews: []github.Review{
+				{Author: "alice", State: review.Commented, SubmittedAt: day(2)},
+			},
+			now:      day(3),
+			reminded: "carol",
+		},
+		{
+			desc:       "requests are reassigned",
+			pending:    []string{"alice", "carol"},
+			now:        day(4),
+			requested:  []string{"bob", "dave"},
+			dismissed:  []string{"alice", "carol"},
+			reassigned: "alice:bob,carol:dave",
+		},
+		{
+			desc:    "alternates aren't reassigned right away",
+			pending: []string{"bob", "dave"},
+			comments: []github.Comment{
+				{Author: defaultLogin, Body: reassignedComment([]string{"alice", "carol"}, []string{"bob", "dave"}, 96*time.Hour), CreatedAt: day(4)},
+			},
+			timeline: requested(day(4), "bob", "dave"),
+			now:      day(5),
+		},
+		{
+			desc:    "replaced reviewers aren't picked again",
+			pending: []string{"bob", "dave"},
+			comments: []github.Comment{
+				{Author: defaultLogin, Body: reassignedComment([]string{"alice", "carol"}, []string{"bob", "dave"}, 96*time.Hour), CreatedAt: day(4)},
+			},
+			timeline: requested(day(4), "bob", "dave"),
+			now:      day(8),
+			reminded: "bob,dave",
+		},
+		{
+			desc:       "requests without events aren't nudged",
+			pending:    []string{"alice", "carol"},
+			timeline:   requested(opened, "alice"),
+			now:        day(5),
+			requested:  []string{"bob"},
+			dismissed:  []string{"alice"},
+			reassigned: "alice:bob",
+		},
+		{
+			desc:     "requests are timed from when the PR is ready",
+			pending:  []string{"alice", "carol"},
+			timeline: append(requested(opened, "alice", "carol"), github.TimelineEvent{Event: "ready_for_review", CreatedAt: day(4)}),
+			now:      day(5),
+		},
+		{
+			desc:       "omitted reviewers are reassigned early",
+			omit:       map[string]bool{"carol": true},
+			pending:    []string{"alice", "carol"},
+			now:        day(2),
+			requested:  []string{"dave"},
+			dismissed:  []string{"carol"},
+			reassigned: "carol:dave",
+			reminded:   "alice",
+		},
+		{
+			desc:    "waiting on the author",
+			pending: []string{"carol"},
+			reviews: []github.Review{
+				{Author: "alice", State: review.ChangesRequested, SubmittedAt: day(1)},
+				{Author: "alice", State: review.Commented, SubmittedAt: day(1.5)},
+			},
+			now:   day(5),
+			added: []string{waitingOnAuthorLabel},
+		},
+		{
+			desc:    "no longer waiting on the author",
+			labels:  []string{waitingOnAuthorLabel},
+			pending: []string{"alice"},
+			reviews: []github.Review{
+				{Author: "alice", State: review.ChangesRequested, SubmittedAt: day(1)},
+			},
+			now:     day(2),
+			removed: []string{waitingOnAuthorLabel},
+		},
+		{
+			desc:    "drafts are skipped",
+			draft:   true,
+			pending: []string{"alice", "carol"},
+			now:     day(5),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			omit := test.omit
+			if omit == nil {
+				omit = map[string]bool{}
+			}
+			r, err := review.New(&review.Config{
+				Admins: []string{"admin"},
+				RepoReviewers: map[string]map[string]review.Reviewer{
+					"bar": {
+						"alice": {Owner: true},
+						"bob":   {Owner: true},
+						"carol": {},
+						"dave":  {},
+						"dev":   {},
+					},
+				},
+				CoreReviewers:     map[string]review.Reviewer{},
+				CloudReviewers:    map[string]review.Reviewer{},
+				CodeReviewersOmit: omit,
+				DocsReviewers:     map[string]review.Reviewer{},
+				DocsReviewersOmit: map[string]bool{},
+			})
+			require.NoError(t, err)
+
+			gh := &fakeGithub{
+				pulls: []github.PullRequest{{
+					Number:       1,
+					Author:       "dev",
+					CreatedAt:    opened,
+					Draft:        test.draft,
+					UnsafeLabels: test.labels,
+				}},
+				pullReviewers: map[int][]string{1: test.pending},
+				reviews:       test.reviews,
+				comments:      test.comments,
+				timeline:      test.timeline,
+			}
+			if gh.timeline == nil {
+				gh.timeline = requested(opened, test.pending...)
+			}
+			b := &Bot{
+				c: &Config{
+					Environment: &env.Environment{Organization: "foo", Repository: "bar"},
+					GitHub:      gh,
+					Review:      r,
+					Now:         func() time.Time { return test.now },
+				},
+			}
+			err = b.Nudge(context.Background(), 48*time.Hour, 96*time.Hour)
+			require.NoError(t, err)
+
+			require.Equal(t, test.requested, gh.requested)
+			require.Equal(t, test.dismissed, gh.dismissed)
+			require.Equal(t, test.added, gh.labels)
+			require.Equal(t, test.removed, gh.removed)
+
+			var reminded, reassigned string
+			for _, comment := range gh.comments[len(test.comments):] {
+				if m := nudgeRemindedPattern.FindStringSubmatch(comment.Body); m != nil {
+					reminded = m[1]
+				}
+				if m := nudgeReassignedPattern.FindStringSubmatch(comment.Body); m != nil {
+					reassigned = m[1]
+				}
+			}
+			require.Equal(t, test.reminded, reminded)
+			require.Equal(t, test.reassigned, reassigned)
+		})
+	}
+
+	b := &Bot{c: &Config{Environment: &env.Environment{}, GitHub: &fakeGithub{}}}
+	require.Error(t, b.Nudge(context.Background(), 0, 0))
+	require.Error(t, b.Nudge(context.Background(), 48*time.Hour, 24*time.Hour))
+}
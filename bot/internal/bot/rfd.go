@@ -13,39 +13,26 @@ import (
 	"github.com/gravitational/trace"
 )
 
+// skipRFDPrefix is the command admins can use to skip RFD validation.
+const skipRFDPrefix = "/excluderfd"
+
 // ValidateNewRFD ensures that PRs which add **new** RFDs follow
 // the process laid out in [RFD 0](https://github.com/gravitational/teleport/blob/61ed36979ecb98310c853a6535108f57464cbef2/rfd/0000-rfds.md).
 // Namely, this ensures that
 // - All branches are in the form rfd/$number-your-title
 // - The RFD itself exists at /rfd/$number-your-title.md
 // - All RFD numbers are properly zero padded to avoid collisions (rfd/123-foo vs. rfd/0123-bar)
+// - New RFDs list their authors, state and required approvers
+// - RFD numbers aren't used by another RFD or by an older open RFD PR
+// - Changed RFDs only move to a later state, for example from draft to implemented
 func (b *Bot) ValidateNewRFD(ctx context.Context) error {
-	comments, err := b.c.GitHub.ListComments(ctx,
-		b.c.Environment.Organization,
-		b.c.Environment.Repository,
-		b.c.Environment.Number,
-	)
+	skip, err := b.invocations(ctx, skipRFDPrefix)
 	if err != nil {
 		return trace.Wrap(err)
 	}
-
-	const skipPrefix = "/excluderfd"
-	admins := b.c.Review.GetAdminCheckers(b.c.Environment.Author)
-	for _, c := range comments {
-		if !slices.Contains(admins, c.Author) {
-			log.Printf("ignoring comment from non-admin %v", c.Author)
-			continue
-		}
-		// non-admins can edit comments from admins, so we only
-		// consider comments that have not been updated
-		if !c.CreatedAt.IsZero() && c.CreatedAt != c.UpdatedAt {
-			log.Printf("ignoring edited comment from %v", c.Author)
-			continue
-		}
-		if strings.HasPrefix(c.Body, skipPrefix) {
-			log.Printf("skipping RFD validation due to skip comment from %v", c.Author)
-			return nil
-		}
+	if len(skip) > 0 {
+		log.Printf("skipping RFD validation due to %v comment from an admin", skipRFDPrefix)
+		return nil
 	}
 
 	files, err := b.c.GitHub.ListFiles(ctx,
@@ -60,7 +47,7 @@ func (b *Bot) ValidateNewRFD(ctx context.Context) error {
 	matches := branchRegexp.FindStringSubmatch(b.c.Environment.UnsafeHead)
 	isRFDBranch := len(matches) == 2
 
-	var hasValidRFD bool
+	var newRFDs []string
 	for _, file := range files {
 		if file.Status != github.StatusAdded {
 			continue
@@ -89,12 +76,143 @@ func (b *Bot) ValidateNewRFD(ctx context.Context) error {
 			return trace.BadParameter("Found branch named %q, expected branch to be named %q", b.c.Environment.UnsafeHead, "rfd/"+expectedNumber+b.c.Environment.UnsafeHead[4+len(rfdNumberString):])
 		}
 
-		hasValidRFD = true
+		newRFDs = append(newRFDs, file.Name)
 	}
 
-	if isRFDBranch && !hasValidRFD {
+	if isRFDBranch && len(newRFDs) == 0 {
 		return trace.BadParameter("RFD %q is missing", b.c.Environment.UnsafeHead+".md")
 	}
 
+	// The PR is only needed to read the RFDs it adds or changes.
+	changesRFD := slices.ContainsFunc(files, func(file github.PullRequestFile) bool {
+		return rfdFilePattern.MatchString(file.Name) || rfdFilePattern.MatchString(file.PreviousName)
+	})
+	if len(newRFDs) == 0 && !changesRFD {
+		return nil
+	}
+
+	pull, err := b.c.GitHub.GetPullRequest(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		b.c.Environment.Number)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	for _, newRFD := range newRFDs {
+		if err := b.validateRFDMetadata(ctx, newRFD, pull); err != nil {
+			return trace.Wrap(err)
+		}
+		if err := b.validateRFDNumber(ctx, newRFD, pull); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+
+	for _, file := range files {
+		if err := b.validateRFDTransition(ctx, file, pull); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+
+	return nil
+}
+
+// validateRFDMetadata ensures that a new RFD lists its authors, a valid state
+// and its required approvers.
+func (b *Bot) validateRFDMetadata(ctx context.Context, name string, pull github.PullRequest) error {
+	metadata, err := b.loadRFDMetadata(ctx, name, pull.UnsafeHead.SHA)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if len(metadata.Approvers) == 0 {
+		return trace.BadParameter("RFD %q must list its approvers in a %q section", name, "Required Approvers")
+	}
+	return nil
+}
+
+// validateRFDNumber ensures that the number of a new RFD isn't used by an RFD
+// on the base branch or by an older open PR.
+func (b *Bot) validateRFDNumber(ctx context.Context, name string, pull github.PullRequest) error {
+	number := rfdNumber(name)
+
+	names, err := b.c.GitHub.ListDirectory(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		"rfd",
+		pull.UnsafeBase.SHA)
+	if err != nil && !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+	for _, existing := range names {
+		existing = "rfd/" + existing
+		if existing != name && rfdNumber(existing) == number {
+			return trace.BadParameter("RFD number %04d is already used by %q, pick the next free number", number, existing)
+		}
+	}
+
+	pulls, err := b.c.GitHub.ListPullRequests(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		"open")
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	branchRegexp := regexp.MustCompile(`^rfd\/(\d+)-`)
+	for _, other := range pulls {
+		// The oldest PR keeps the number.
+		if other.Number == b.c.Environment.Number || (b.c.Environment.Number != 0 && other.Number > b.c.Environment.Number) {
+			continue
+		}
+		matches := branchRegexp.FindStringSubmatch(other.UnsafeHead.Ref)
+		if len(matches) != 2 {
+			continue
+		}
+		if n, err := strconv.Atoi(matches[1]); err == nil && n == number {
+			return trace.BadParameter("RFD number %04d is already used by PR #%v, pick the next free number", number, other.Number)
+		}
+	}
 	return nil
 }
+
+// validateRFDTransition ensures that an RFD changed by the PR only moves to
+// a later state.
+func (b *Bot) validateRFDTransition(ctx context.Context, file github.PullRequestFile, pull github.PullRequest) error {
+	if file.Status != github.StatusModified && file.Status != github.StatusChanged && file.Status != github.StatusRenamed {
+		return nil
+	}
+	previous := file.Name
+	if file.Status == github.StatusRenamed && file.PreviousName != "" {
+		previous = file.PreviousName
+	}
+	if !rfdFilePattern.MatchString(file.Name) || !rfdFilePattern.MatchString(previous) {
+		return nil
+	}
+
+	before, err := b.loadRFDMetadata(ctx, previous, pull.UnsafeBase.SHA)
+	if trace.IsBadParameter(err) {
+		// Older RFDs may not have valid front matter.
+		log.Printf("Skipping state transition check of %v: %v.", previous, err)
+		return nil
+	}
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	after, err := b.loadRFDMetadata(ctx, file.Name, pull.UnsafeHead.SHA)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(checkRFDTransition(before.State, after.State), "RFD %q", file.Name)
+}
+
+// rfdNumber returns the number of an RFD file, or -1 if name isn't an RFD.
+func rfdNumber(name string) int {
+	matches := rfdFilePattern.FindStringSubmatch(name)
+	if len(matches) != 2 {
+		return -1
+	}
+	n, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return -1
+	}
+	return n
+}
@@ -0,0 +1,274 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bot
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/gravitational/trace"
+	"gopkg.in/yaml.v3"
+
+	"github.com/gravitational/shared-workflows/bot/internal/github"
+	"github.com/gravitational/shared-workflows/bot/internal/review"
+)
+
+const (
+	// rfdStateDraft is the state of RFDs under discussion.
+	rfdStateDraft = "draft"
+	// rfdStateImplemented is the state of RFDs that have been implemented.
+	rfdStateImplemented = "implemented"
+	// rfdStateDeprecated is the state of implemented RFDs that no longer
+	// apply.
+	rfdStateDeprecated = "deprecated"
+	// rfdStateCanceled is the state of RFDs that won't be implemented.
+	rfdStateCanceled = "canceled"
+)
+
+// rfdTransitions are the states each RFD state can change to.
+var rfdTransitions = map[string][]string{
+	rfdStateDraft:       {rfdStateImplemented, rfdStateCanceled},
+	rfdStateImplemented: {rfdStateDeprecated},
+	rfdStateDeprecated:  {},
+	rfdStateCanceled:    {},
+}
+
+var (
+	// rfdFilePattern matches RFD files, capturing the RFD number.
+	rfdFilePattern = regexp.MustCompile(`^rfd/(\d+)-[^/]*\.md$`)
+	// rfdApproversHeadingPattern matches the heading of the required
+	// approvers section.
+	rfdApproversHeadingPattern = regexp.MustCompile(`(?i)^#+\s*required\s+approv(?:er|al)s?\b`)
+	// rfdHeadingPattern matches Markdown headings.
+	rfdHeadingPattern = regexp.MustCompile(`^#+\s`)
+	// rfdApproverPattern matches GitHub handles.
+	rfdApproverPattern = regexp.MustCompile(`@([A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?)`)
+)
+
+// rfdMetadata is the metadata of an RFD.
+type rfdMetadata struct {
+	// Authors are the authors listed in the front matter.
+	Authors []string
+	// State is the state of the RFD from the front matter.
+	State string
+	// Approvers are the groups of required approvers, every group must
+	// approve.
+	Approvers []rfdApproverGroup
+}
+
+// rfdApproverGroup requires an approval from one of its reviewers, for
+// example "Security: (@alice || @bob)".
+type rfdApproverGroup struct {
+	// Name is the name of the group, for example "Security".
+	Name string
+	// Reviewers are the GitHub logins of the reviewers.
+	Reviewers []string
+}
+
+// parseRFDMetadata parses the front matter and the required approvers
+// section of an RFD:
+//
+//	---
+//	authors: Alice (alice@goteleport.com)
+//	state: draft
+//	---
+//
+//	## Required Approvers
+//
+//	* Engineering: @bob && @carol
+//	* Security: (@dave || @erin)
+func parseRFDMetadata(content string) (*rfdMetadata, error) {
+	frontMatter, body, ok := strings.Cut(strings.TrimPrefix(content, "---\n"), "\n---")
+	if !strings.HasPrefix(content, "---\n") || !ok {
+		return nil, trace.BadParameter("RFD must start with front matter between --- lines")
+	}
+
+	var fields struct {
+		Authors any    `yaml:"authors"`
+		State   string `yaml:"state"`
+	}
+	if err := yaml.Unmarshal([]byte(frontMatter), &fields); err != nil {
+		return nil, trace.BadParameter("RFD front matter is invalid: %v", err)
+	}
+
+	var metadata rfdMetadata
+	switch authors := fields.Authors.(type) {
+	case string:
+		metadata.Authors = []string{authors}
+	case []any:
+		for _, author := range authors {
+			metadata.Authors = append(metadata.Authors, fmt.Sprint(author))
+		}
+	}
+	metadata.Authors = slices.DeleteFunc(metadata.Authors, func(author string) bool {
+		return strings.TrimSpace(author) == ""
+	})
+	if len(metadata.Authors) == 0 {
+		return nil, trace.BadParameter("RFD front matter must list the authors")
+	}
+
+	// Older RFDs annotate the state, for example "implemented (v10.0)".
+	if state := strings.Fields(fields.State); len(state) > 0 {
+		metadata.State = strings.ToLower(state[0])
+	}
+	if _, ok := rfdTransitions[metadata.State]; !ok {
+		return nil, trace.BadParameter("RFD state %q is unknown, it must be one of: %v, %v, %v or %v",
+			fields.State, rfdStateDraft, rfdStateImplemented, rfdStateDeprecated, rfdStateCanceled)
+	}
+
+	metadata.Approvers = parseRFDApprovers(body)
+	return &metadata, nil
+}
+
+// parseRFDApprovers returns the approver groups of the required approvers
+// section. Groups are separated by "&&" and the reviewers of a group by
+// "||".
+func parseRFDApprovers(body string) []rfdApproverGroup {
+	var groups []rfdApproverGroup
+	inSection := false
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if rfdHeadingPattern.MatchString(line) {
+			inSection = rfdApproversHeadingPattern.MatchString(line)
+			continue
+		}
+		if !inSection {
+			continue
+		}
+
+		name, approvers, ok := strings.Cut(strings.TrimLeft(line, "*-+ "), ":")
+		if !ok {
+			name, approvers = "", line
+		}
+		for _, part := range strings.Split(approvers, "&&") {
+			var reviewers []string
+			for _, m := range rfdApproverPattern.FindAllStringSubmatch(part, -1) {
+				reviewers = append(reviewers, m[1])
+			}
+			if len(reviewers) > 0 {
+				groups = append(groups, rfdApproverGroup{
+					Name:      strings.TrimSpace(name),
+					Reviewers: reviewers,
+				})
+			}
+		}
+	}
+	return groups
+}
+
+// checkRFDTransition returns an error if an RFD can't change from one state
+// to another.
+func checkRFDTransition(from, to string) error {
+	if from == to || slices.Contains(rfdTransitions[from], to) {
+		return nil
+	}
+	return trace.BadParameter("RFD state can't change from %q to %q", from, to)
+}
+
+// rfdRequirements returns the review requirements of the RFD approvers of
+// the RFDs the PR adds or changes. The approvers of changed RFDs are read
+// from the base of the PR, so a PR can't replace the approvers it needs.
+// RFDs whose approvers can't be read require an admin approval instead. The
+// author can't approve their own PR, so groups that only list the author
+// are skipped.
+func (b *Bot) rfdRequirements(ctx context.Context, files []github.PullRequestFile, reviews []github.Review) ([]review.Requirement, error) {
+	var rfds []github.PullRequestFile
+	for _, file := range files {
+		if file.Status != github.StatusRemoved && rfdFilePattern.MatchString(file.Name) {
+			rfds = append(rfds, file)
+		}
+	}
+	if len(rfds) == 0 {
+		return nil, nil
+	}
+
+	pull, err := b.c.GitHub.GetPullRequest(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		b.c.Environment.Number)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	var requirements []review.Requirement
+	for _, file := range rfds {
+		name := file.Name
+		metadata, err := b.loadRFDApprovers(ctx, file, pull)
+		if trace.IsBadParameter(err) {
+			log.Printf("Requiring admin approval, failed to read the approvers of %v: %v.", name, err)
+			requirement := fmt.Sprintf("Admin approval for %v, whose approvers can't be read", name)
+			requirements = append(requirements, review.ExplainAnyApprover(requirement, b.c.Review.GetAdminCheckers(b.c.Environment.Author), reviews))
+			continue
+		}
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		for _, group := range metadata.Approvers {
+			reviewers := slices.DeleteFunc(slices.Clone(group.Reviewers), func(reviewer string) bool {
+				return reviewer == b.c.Environment.Author
+			})
+			if len(reviewers) == 0 {
+				continue
+			}
+			requirement := fmt.Sprintf("Approval from the %v approvers of %v", group.Name, name)
+			if group.Name == "" {
+				requirement = fmt.Sprintf("Approval from the approvers of %v", name)
+			}
+			requirements = append(requirements, review.ExplainAnyApprover(requirement, reviewers, reviews))
+		}
+	}
+	return requirements, nil
+}
+
+// loadRFDApprovers reads the metadata that holds the approvers of an RFD
+// the PR adds or changes. Added and copied RFDs are read from the head of
+// the PR, changed RFDs from the base.
+func (b *Bot) loadRFDApprovers(ctx context.Context, file github.PullRequestFile, pull github.PullRequest) (*rfdMetadata, error) {
+	if file.Status == github.StatusAdded || file.Status == github.StatusCopied {
+		return b.loadRFDMetadata(ctx, file.Name, pull.UnsafeHead.SHA)
+	}
+	metadata, err := b.loadRFDMetadata(ctx, cmp.Or(file.PreviousName, file.Name), pull.UnsafeBase.SHA)
+	if trace.IsNotFound(err) {
+		// Files without a status may be new.
+		return b.loadRFDMetadata(ctx, file.Name, pull.UnsafeHead.SHA)
+	}
+	return metadata, trace.Wrap(err)
+}
+
+// loadRFDMetadata reads and parses an RFD at a git reference.
+func (b *Bot) loadRFDMetadata(ctx context.Context, name string, ref string) (*rfdMetadata, error) {
+	content, err := b.c.GitHub.GetContents(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		name,
+		ref)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	metadata, err := parseRFDMetadata(string(content))
+	if err != nil {
+		return nil, trace.Wrap(err, "parsing %v", name)
+	}
+	return metadata, nil
+}
@@ -2,6 +2,9 @@ package bot
 
 import (
 	"context"
+	"fmt"
+	"maps"
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -26,6 +29,8 @@ func TestValidateNewRFD(t *testing.T) {
 		desc         string
 		branch       string
 		files        []github.PullRequestFile
+		contents     map[string][]byte
+		pulls        []github.PullRequest
 		errorMessage string
 	}{
 		{
@@ -186,12 +191,152 @@ func TestValidateNewRFD(t *testing.T) {
 			},
 			errorMessage: `Found branch named "rfd/1-test-123", expected branch to be named "rfd/0001-test-123"`,
 		},
+		{
+			desc:   "missing-authors",
+			branch: "rfd/0001-test-123",
+			files: []github.PullRequestFile{
+				{
+					Name:   "rfd/0001-test-123.md",
+					Status: github.StatusAdded,
+				},
+			},
+			contents: map[string][]byte{
+				"head:rfd/0001-test-123.md": []byte("---\nstate: draft\n---\n"),
+			},
+			errorMessage: "RFD front matter must list the authors",
+		},
+		{
+			desc:   "unknown-state",
+			branch: "rfd/0001-test-123",
+			files: []github.PullRequestFile{
+				{
+					Name:   "rfd/0001-test-123.md",
+					Status: github.StatusAdded,
+				},
+			},
+			contents: map[string][]byte{
+				"head:rfd/0001-test-123.md": []byte("---\nauthors: Alice\nstate: done\n---\n"),
+			},
+			errorMessage: `RFD state "done" is unknown`,
+		},
+		{
+			desc:   "missing-approvers",
+			branch: "rfd/0001-test-123",
+			files: []github.PullRequestFile{
+				{
+					Name:   "rfd/0001-test-123.md",
+					Status: github.StatusAdded,
+				},
+			},
+			contents: map[string][]byte{
+				"head:rfd/0001-test-123.md": []byte("---\nauthors: Alice\nstate: draft\n---\n\n# RFD 1\n"),
+			},
+			errorMessage: `RFD "rfd/0001-test-123.md" must list its approvers`,
+		},
+		{
+			desc:   "number-used-on-base",
+			branch: "rfd/0001-test-123",
+			files: []github.PullRequestFile{
+				{
+					Name:   "rfd/0001-test-123.md",
+					Status: github.StatusAdded,
+				},
+			},
+			contents: map[string][]byte{
+				"rfd/1-other.md": testRFD("draft"),
+			},
+			errorMessage: `RFD number 0001 is already used by "rfd/1-other.md"`,
+		},
+		{
+			desc:   "number-used-by-older-pr",
+			branch: "rfd/0001-test-123",
+			files: []github.PullRequestFile{
+				{
+					Name:   "rfd/0001-test-123.md",
+					Status: github.StatusAdded,
+				},
+			},
+			pulls: []github.PullRequest{
+				{Number: 2, UnsafeHead: github.Branch{Ref: "rfd/0001-other"}},
+				{Number: 3, UnsafeHead: github.Branch{Ref: "rfd/0002-other"}},
+			},
+			errorMessage: "RFD number 0001 is already used by PR #2",
+		},
+		{
+			desc:   "number-used-by-newer-pr",
+			branch: "rfd/0001-test-123",
+			files: []github.PullRequestFile{
+				{
+					Name:   "rfd/0001-test-123.md",
+					Status: github.StatusAdded,
+				},
+			},
+			pulls: []github.PullRequest{
+				{Number: 7, UnsafeHead: github.Branch{Ref: "rfd/0001-other"}},
+			},
+		},
+		{
+			desc:   "implemented-rfd",
+			branch: "rjones/implemented",
+			files: []github.PullRequestFile{
+				{
+					Name:   "rfd/0001-test-123.md",
+					Status: github.StatusModified,
+				},
+			},
+			contents: map[string][]byte{
+				"base:rfd/0001-test-123.md": testRFD("draft"),
+				"head:rfd/0001-test-123.md": testRFD("implemented"),
+			},
+		},
+		{
+			desc:   "invalid-state-transition",
+			branch: "rjones/draft",
+			files: []github.PullRequestFile{
+				{
+					Name:         "rfd/0001-test.md",
+					PreviousName: "rfd/0001-test-123.md",
+					Status:       github.StatusRenamed,
+				},
+			},
+			contents: map[string][]byte{
+				"base:rfd/0001-test-123.md": testRFD("implemented"),
+				"head:rfd/0001-test.md":     testRFD("draft"),
+			},
+			errorMessage: `RFD state can't change from "implemented" to "draft"`,
+		},
+		{
+			desc:   "legacy-rfd",
+			branch: "rjones/legacy",
+			files: []github.PullRequestFile{
+				{
+					Name:   "rfd/0001-test-123.md",
+					Status: github.StatusModified,
+				},
+			},
+			contents: map[string][]byte{
+				"base:rfd/0001-test-123.md": []byte("# RFD 1\n"),
+				"head:rfd/0001-test-123.md": testRFD("draft"),
+			},
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.desc, func(t *testing.T) {
+			contents := map[string][]byte{
+				"head:rfd/0001-test-123.md": testRFD("draft"),
+				"base:rfd/0001-test-123.md": testRFD("draft"),
+			}
+			maps.Copy(contents, test.contents)
 			gh := &fakeGithub{
-				files: test.files,
+				files:    test.files,
+				contents: contents,
+				pull: github.PullRequest{
+					Number:     5,
+					UnsafeBase: github.Branch{Ref: "master", SHA: "base"},
+					UnsafeHead: github.Branch{Ref: test.branch, SHA: "head"},
+				},
+				pulls: test.pulls,
 				comments: []github.Comment{
 					{Body: "/excludeflake", Author: "alice"},
 					{Body: "rfd", Author: "alice"},
@@ -204,6 +349,7 @@ func TestValidateNewRFD(t *testing.T) {
 					Environment: &env.Environment{
 						Organization: "foo",
 						Repository:   "test",
+						Number:       5,
 						UnsafeHead:   test.branch,
 					},
 					Review: r,
@@ -214,6 +360,11 @@ func TestValidateNewRFD(t *testing.T) {
 			err := b.ValidateNewRFD(context.Background())
 			if test.errorMessage == "" {
 				require.NoError(t, err)
+				// The PR is only read for RFD changes.
+				changesRFD := slices.ContainsFunc(test.files, func(file github.PullRequestFile) bool {
+					return rfdFilePattern.MatchString(file.Name)
+				})
+				require.Equal(t, changesRFD, gh.pullRequests > 0)
 				return
 			}
 
@@ -226,3 +377,150 @@ func TestValidateNewRFD(t *testing.T) {
 		})
 	}
 }
+
+// testRFD returns an RFD in the given state.
+func testRFD(state string) []byte {
+	return fmt.Appendf(nil, `---
+authors: Alice (alice@goteleport.com)
+state: %v
+---
+
+# RFD 1 - Test
+
+## Required Approvers
+
+* Engineering: @bob
+`, state)
+}
+
+func TestParseRFDMetadata(t *testing.T) {
+	metadata, err := parseRFDMetadata(`---
+authors: [Alice (alice@goteleport.com), Bob (bob@goteleport.com)]
+state: Implemented (v17.0)
+---
+
+# RFD 1 - Test
+
+## Required Approvers
+
+* Engineering: @carol && (@dave || @erin)
+* Security: @frank
+* Product: ( @grace )
+
+## What
+
+Ping @heidi for questions.
+`)
+	require.NoError(t, err)
+	require.Equal(t, &rfdMetadata{
+		Authors: []string{"Alice (alice@goteleport.com)", "Bob (bob@goteleport.com)"},
+		State:   rfdStateImplemented,
+		Approvers: []rfdApproverGroup{
+			{Name: "Engineering", Reviewers: []string{"carol"}},
+			{Name: "Engineering", Reviewers: []string{"dave", "erin"}},
+			{Name: "Security", Reviewers: []string{"frank"}},
+			{Name: "Product", Reviewers: []string{"grace"}},
+		},
+	}, metadata)
+
+	_, err = parseRFDMetadata("# RFD 1\n")
+	require.ErrorContains(t, err, "front matter")
+}
+
+func TestRFDTransitions(t *testing.T) {
+	require.NoError(t, checkRFDTransition(rfdStateDraft, rfdStateDraft))
+	require.NoError(t, checkRFDTransition(rfdStateDraft, rfdStateImplemented))
+	require.NoError(t, checkRFDTransition(rfdStateDraft, rfdStateCanceled))
+	require.NoError(t, checkRFDTransition(rfdStateImplemented, rfdStateDeprecated))
+	require.Error(t, checkRFDTransition(rfdStateImplemented, rfdStateDraft))
+	require.Error(t, checkRFDTransition(rfdStateCanceled, rfdStateImplemented))
+	require.Error(t, checkRFDTransition(rfdStateDeprecated, rfdStateImplemented))
+}
+
+func TestRFDRequirements(t *testing.T) {
+	r, err := review.New(&review.Config{
+		Admins:            []string{"admin1", "admin2"},
+		CoreReviewers:     make(map[string]review.Reviewer),
+		CloudReviewers:    make(map[string]review.Reviewer),
+		CodeReviewersOmit: make(map[string]bool),
+		DocsReviewers:     make(map[string]review.Reviewer),
+		DocsReviewersOmit: make(map[string]bool),
+	})
+	require.NoError(t, err)
+
+	gh := &fakeGithub{
+		contents: map[string][]byte{
+			"base:rfd/0001-test.md": []byte(`---
+authors: Alice
+state: draft
+---
+
+## Required approvers
+
+* Engineering: @alice && (@bob || @carol)
+* Security: @alice
+`),
+			// The PR can't replace the approvers of the RFD it changes.
+			"head:rfd/0001-test.md": []byte(`---
+authors: Alice
+state: draft
+---
+
+## Required approvers
+
+* Engineering: @mallory
+`),
+			"head:rfd/0003-new.md": []byte(`---
+authors: Alice
+state: draft
+---
+
+## Required approvers
+
+* Security: @dave
+`),
+			"head:rfd/0004-broken.md": []byte("# RFD 4 - Broken\n"),
+		},
+		pull: github.PullRequest{
+			UnsafeBase: github.Branch{SHA: "base"},
+			UnsafeHead: github.Branch{SHA: "head"},
+		},
+	}
+	b := &Bot{
+		c: &Config{
+			Environment: &env.Environment{Organization: "foo", Repository: "teleport", Author: "alice"},
+			GitHub:      gh,
+			Review:      r,
+		},
+	}
+	files := []github.PullRequestFile{
+		{Name: "rfd/0001-test.md", Status: github.StatusModified},
+		{Name: "rfd/0002-removed.md", Status: github.StatusRemoved},
+		{Name: "rfd/0003-new.md", Status: github.StatusAdded},
+		{Name: "rfd/0004-broken.md", Status: github.StatusAdded},
+		{Name: "lib/a.go", Status: github.StatusModified},
+	}
+
+	requirements, err := b.rfdRequirements(context.Background(), files, nil)
+	require.NoError(t, err)
+	require.Equal(t, []review.Requirement{
+		{
+			Name:      "Approval from the Engineering approvers of rfd/0001-test.md",
+			Reviewers: []string{"bob", "carol"},
+		},
+		{
+			Name:      "Approval from the Security approvers of rfd/0003-new.md",
+			Reviewers: []string{"dave"},
+		},
+		{
+			Name:      "Admin approval for rfd/0004-broken.md, whose approvers can't be read",
+			Reviewers: []string{"admin1", "admin2"},
+		},
+	}, requirements)
+
+	reviews := []github.Review{{Author: "carol", State: review.Approved}}
+	requirements, err = b.rfdRequirements(context.Background(), files, reviews)
+	require.NoError(t, err)
+	require.True(t, requirements[0].Met)
+	require.Equal(t, []string{"carol"}, requirements[0].Approvals)
+}
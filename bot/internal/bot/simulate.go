@@ -0,0 +1,318 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bot
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"math/rand"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/shared-workflows/bot/internal/env"
+	"github.com/gravitational/shared-workflows/bot/internal/review"
+)
+
+// simulatedApproved is the approval result of PRs that pass the checks.
+const simulatedApproved = "approved"
+
+// simulatedPull is the result of replaying a PR with the current and the
+// candidate reviewer assignments.
+type simulatedPull struct {
+	// Number is the PR number.
+	Number int
+	// Author is the author of the PR.
+	Author string
+	// Current is the result with the current assignments.
+	Current simulatedOutcome
+	// Candidate is the result with the candidate assignments.
+	Candidate simulatedOutcome
+}
+
+// simulatedOutcome is the result of replaying a PR with one set of reviewer
+// assignments.
+type simulatedOutcome struct {
+	// Reviewers are the reviewers that would have been assigned, sorted.
+	Reviewers []string
+	// Approval is "approved" if the reviews of the PR pass the checks,
+	// otherwise it is the reason they don't.
+	Approval string
+}
+
+// Simulate replays reviewer assignment and the approval checks of past PRs
+// with the current assignments and candidate ones, and writes a Markdown
+// report of who would have been assigned, the load of each reviewer and the
+// PRs whose approval result differs to w.
+//
+// The PRs replayed are numbers, or when it is empty, the PRs opened between
+// since and until. Reviews aren't requested. The approval checks use the
+// reviews the PRs got with the current assignments. With load balancing,
+// the load of a reviewer is the number of replayed PRs they are assigned,
+// not their outstanding review requests.
+func (b *Bot) Simulate(ctx context.Context, candidate *review.Assignments, numbers []int, since time.Time, until time.Time, w io.Writer) error {
+	if candidate == nil {
+		return trace.BadParameter("missing candidate reviewer assignments")
+	}
+	numbers, err := b.simulatedPullNumbers(ctx, numbers, since, until)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if len(numbers) == 0 {
+		return trace.NotFound("no PRs to simulate")
+	}
+
+	s := &simulator{
+		b:        b,
+		internal: make(map[string]bool),
+		sizes:    make(map[string]*sizeConfig),
+	}
+	current := &simulatedConfig{assignments: b.c.Review, load: make(review.Load)}
+	candidates := &simulatedConfig{assignments: candidate, load: make(review.Load)}
+
+	var pulls []simulatedPull
+	for _, number := range numbers {
+		pull, err := s.replay(ctx, number, current, candidates)
+		if err != nil {
+			return trace.Wrap(err, "simulating PR %v", number)
+		}
+		pulls = append(pulls, pull)
+	}
+
+	writeSimulationReport(w, pulls, current.load, candidates.load)
+	return nil
+}
+
+// simulatedPullNumbers returns numbers, or the PRs opened between since and
+// until in ascending order if there are none. A zero until is now.
+func (b *Bot) simulatedPullNumbers(ctx context.Context, numbers []int, since time.Time, until time.Time) ([]int, error) {
+	if len(numbers) > 0 {
+		return numbers, nil
+	}
+	if since.IsZero() {
+		return nil, trace.BadParameter("PR numbers or a start date are required")
+	}
+	if until.IsZero() {
+		until = b.now()
+	}
+
+	pulls, err := b.c.GitHub.ListPullRequestsCreatedSince(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		since)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, pull := range pulls {
+		if !pull.CreatedAt.Before(until) {
+			continue
+		}
+		numbers = append(numbers, pull.Number)
+	}
+	sort.Ints(numbers)
+	return numbers, nil
+}
+
+// simulatedConfig is a set of reviewer assignments being simulated.
+type simulatedConfig struct {
+	// assignments are the reviewer assignments.
+	assignments *review.Assignments
+	// load is the number of replayed PRs each reviewer is assigned.
+	load review.Load
+}
+
+// simulator replays PRs, caching what doesn't change between them.
+type simulator struct {
+	b *Bot
+	// internal caches whether authors that aren't listed as reviewers are
+	// organization members.
+	internal map[string]bool
+	// sizes caches the size configs of base branches.
+	sizes map[string]*sizeConfig
+}
+
+// replay replays a PR with the current and candidate assignments.
+func (s *simulator) replay(ctx context.Context, number int, current *simulatedConfig, candidate *simulatedConfig) (simulatedPull, error) {
+	gh := s.b.c.GitHub
+	organization := s.b.c.Environment.Organization
+	repository := s.b.c.Environment.Repository
+
+	pull, err := gh.GetPullRequest(ctx, organization, repository, number)
+	if err != nil {
+		return simulatedPull{}, trace.Wrap(err)
+	}
+	files, err := gh.ListFiles(ctx, organization, repository, number)
+	if err != nil {
+		return simulatedPull{}, trace.Wrap(err)
+	}
+	reviews, err := gh.ListReviews(ctx, organization, repository, number)
+	if err != nil {
+		return simulatedPull{}, trace.Wrap(err)
+	}
+
+	e := &env.Environment{
+		Organization: organization,
+		Repository:   repository,
+		Number:       number,
+		Author:       pull.Author,
+		UnsafeBase:   pull.UnsafeBase.Ref,
+		UnsafeHead:   pull.UnsafeHead.Ref,
+	}
+	sizes, ok := s.sizes[e.UnsafeBase]
+	if !ok {
+		sizes = s.b.loadSizeConfig(ctx, e.UnsafeBase)
+		s.sizes[e.UnsafeBase] = sizes
+	}
+
+	result := simulatedPull{Number: number, Author: pull.Author}
+	for _, sim := range []struct {
+		config  *simulatedConfig
+		outcome *simulatedOutcome
+	}{
+		{config: current, outcome: &result.Current},
+		{config: candidate, outcome: &result.Candidate},
+	} {
+		// Both configs pick from the same random sequence for each PR, so
+		// only the configs make their reviewers differ.
+		assignments := sim.config.assignments.WithRand(rand.New(rand.NewSource(int64(number))))
+		if s.b.c.CodeOwnersPath != "" {
+			assignments, err = s.b.withCodeOwners(ctx, assignments, pull.UnsafeBase.SHA)
+			if err != nil {
+				return simulatedPull{}, trace.Wrap(err)
+			}
+		}
+
+		// Changes are classified with the approval policies of the
+		// assignments being simulated.
+		c := *s.b.c
+		c.Environment = e
+		c.Review = assignments
+		changes := classifyChanges(&c, files, sizes)
+
+		var load review.Load
+		if assignments.LoadBalance() {
+			load = sim.config.load
+		}
+		reviewers := assignments.Get(e, changes, files, load)
+		for _, reviewer := range reviewers {
+			sim.config.load[reviewer]++
+		}
+		sim.outcome.Reviewers = slices.Sorted(slices.Values(reviewers))
+
+		internal, err := s.isInternal(ctx, assignments, e.Author)
+		if err != nil {
+			return simulatedPull{}, trace.Wrap(err)
+		}
+		if internal {
+			err = assignments.CheckInternal(e, reviews, changes, files)
+		} else {
+			err = assignments.CheckExternal(e.Author, reviews)
+		}
+		sim.outcome.Approval = simulatedApproved
+		if err != nil {
+			sim.outcome.Approval = trace.UserMessage(err)
+		}
+	}
+	return result, nil
+}
+
+// isInternal returns true if author is listed in the assignments or is an
+// organization member, like Bot.isInternal.
+func (s *simulator) isInternal(ctx context.Context, assignments *review.Assignments, author string) (bool, error) {
+	if assignments.IsInternal(author) {
+		return true, nil
+	}
+	if member, ok := s.internal[author]; ok {
+		return member, nil
+	}
+	member, err := s.b.c.GitHub.IsOrgMember(ctx, author, "gravitational")
+	if err != nil {
+		return false, trace.Wrap(err)
+	}
+	s.internal[author] = member
+	return member, nil
+}
+
+// writeSimulationReport writes the results of a simulation as Markdown.
+func writeSimulationReport(w io.Writer, pulls []simulatedPull, currentLoad review.Load, candidateLoad review.Load) {
+	var reassigned int
+	var approvals []simulatedPull
+	for _, pull := range pulls {
+		if !slices.Equal(pull.Current.Reviewers, pull.Candidate.Reviewers) {
+			reassigned++
+		}
+		if pull.Current.Approval != pull.Candidate.Approval {
+			approvals = append(approvals, pull)
+		}
+	}
+
+	fmt.Fprintf(w, "# Simulation of %v PRs\n\n", len(pulls))
+	fmt.Fprintf(w, "The candidate config assigns different reviewers to %v PRs and changes the approval result of %v PRs.\n\n", reassigned, len(approvals))
+
+	fmt.Fprintf(w, "## Assignments\n\n")
+	fmt.Fprintf(w, "| PR | Author | Current | Candidate |\n")
+	fmt.Fprintf(w, "| --- | --- | --- | --- |\n")
+	for _, pull := range pulls {
+		candidate := strings.Join(pull.Candidate.Reviewers, ", ")
+		if !slices.Equal(pull.Current.Reviewers, pull.Candidate.Reviewers) {
+			candidate = "**" + candidate + "**"
+		}
+		fmt.Fprintf(w, "| #%v | %v | %v | %v |\n",
+			pull.Number,
+			pull.Author,
+			strings.Join(pull.Current.Reviewers, ", "),
+			candidate)
+	}
+
+	reviewers := make(map[string]struct{})
+	for reviewer := range currentLoad {
+		reviewers[reviewer] = struct{}{}
+	}
+	for reviewer := range candidateLoad {
+		reviewers[reviewer] = struct{}{}
+	}
+	fmt.Fprintf(w, "\n## Reviewer load\n\n")
+	fmt.Fprintf(w, "| Reviewer | Current | Candidate |\n")
+	fmt.Fprintf(w, "| --- | --- | --- |\n")
+	for _, reviewer := range slices.Sorted(maps.Keys(reviewers)) {
+		fmt.Fprintf(w, "| %v | %v | %v |\n", reviewer, currentLoad[reviewer], candidateLoad[reviewer])
+	}
+
+	fmt.Fprintf(w, "\n## Approval differences\n\n")
+	if len(approvals) == 0 {
+		fmt.Fprintf(w, "No differences.\n")
+		return
+	}
+	fmt.Fprintf(w, "| PR | Current | Candidate |\n")
+	fmt.Fprintf(w, "| --- | --- | --- |\n")
+	for _, pull := range approvals {
+		fmt.Fprintf(w, "| #%v | %v | %v |\n",
+			pull.Number,
+			markdownTableCell(pull.Current.Approval),
+			markdownTableCell(pull.Candidate.Approval))
+	}
+}
+
+// markdownTableCell escapes text for a cell of a Markdown table.
+func markdownTableCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\r", "", "\n", " ").Replace(s)
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bot
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/shared-workflows/bot/internal/env"
+	"github.com/gravitational/shared-workflows/bot/internal/github"
+	"github.com/gravitational/shared-workflows/bot/internal/review"
+)
+
+// simulateGithub serves the files and reviews of several PRs.
+type simulateGithub struct {
+	*fakeGithub
+	pullFiles   map[int][]github.PullRequestFile
+	pullReviews map[int][]github.Review
+}
+
+func (f *simulateGithub) GetPullRequest(ctx context.Context, organization string, repository string, number int) (github.PullRequest, error) {
+	for _, pull := range f.pulls {
+		if pull.Number == number {
+			return pull, nil
+		}
+	}
+	return github.PullRequest{}, nil
+}
+
+func (f *simulateGithub) ListFiles(ctx context.Context, organization string, repository string, number int) ([]github.PullRequestFile, error) {
+	return f.pullFiles[number], nil
+}
+
+func (f *simulateGithub) ListReviews(ctx context.Context, organization string, repository string, number int) ([]github.Review, error) {
+	return f.pullReviews[number], nil
+}
+
+func TestSimulate(t *testing.T) {
+	newAssignments := func(reviewers map[string]review.Reviewer) *review.Assignments {
+		a, err := review.New(&review.Config{
+			Admins:            []string{"admin1", "admin2"},
+			RepoReviewers:     map[string]map[string]review.Reviewer{"bar": reviewers},
+			CoreReviewers:     map[string]review.Reviewer{},
+			CloudReviewers:    map[string]review.Reviewer{},
+			CodeReviewersOmit: map[string]bool{},
+			DocsReviewers:     map[string]review.Reviewer{},
+			DocsReviewersOmit: map[string]bool{},
+		})
+		require.NoError(t, err)
+		return a
+	}
+	// The candidate replaces bob with carol.
+	current := newAssignments(map[string]review.Reviewer{
+		"alice": {Owner: true},
+		"bob":   {},
+		"dev":   {},
+	})
+	candidate := newAssignments(map[string]review.Reviewer{
+		"alice": {Owner: true},
+		"carol": {},
+		"dev":   {},
+	})
+
+	opened := func(day int) time.Time {
+		return time.Date(2026, 3, day, 12, 0, 0, 0, time.UTC)
+	}
+	code := []github.PullRequestFile{{Name: "lib/auth.go", Additions: 10}}
+	gh := &simulateGithub{
+		fakeGithub: &fakeGithub{
+			pulls: []github.PullRequest{
+				{Number: 1, Author: "dev", CreatedAt: opened(1)},
+				{Number: 2, Author: "dev", CreatedAt: opened(2)},
+				{Number: 3, Author: "stranger", CreatedAt: opened(3)},
+				{Number: 4, Author: "dev", CreatedAt: opened(20)},
+			},
+		},
+		pullFiles: map[int][]github.PullRequestFile{1: code, 2: code, 3: code, 4: code},
+		pullReviews: map[int][]github.Review{
+			1: {
+				{Author: "alice", State: review.Approved},
+				{Author: "bob", State: review.Approved},
+			},
+			2: {
+				{Author: "alice", State: review.Approved},
+			},
+			3: {
+				{Author: "admin1", State: review.Approved},
+			},
+		},
+	}
+	b := &Bot{
+		c: &Config{
+			Environment: &env.Environment{Organization: "foo", Repository: "bar"},
+			GitHub:      gh,
+			Review:      current,
+			Now:         func() time.Time { return opened(31) },
+		},
+	}
+
+	var out bytes.Buffer
+	err := b.Simulate(context.Background(), candidate, nil, opened(1).Truncate(24*time.Hour), opened(10), &out)
+	require.NoError(t, err)
+	report := out.String()
+
+	require.Contains(t, report, "# Simulation of 3 PRs")
+	require.Contains(t, report, "assigns different reviewers to 2 PRs and changes the approval result of 2 PRs")
+	require.Contains(t, report, "| #1 | dev | alice, bob | **alice, carol** |")
+	require.Contains(t, report, "| #3 | stranger | admin1, admin2 | admin1, admin2 |")
+	require.NotContains(t, report, "#4")
+
+	// Load is the number of replayed PRs each reviewer is assigned.
+	require.Contains(t, report, "| alice | 2 | 2 |")
+	require.Contains(t, report, "| bob | 2 | 0 |")
+	require.Contains(t, report, "| carol | 0 | 2 |")
+
+	// bob's approval doesn't count with the candidate config.
+	require.Contains(t, report, "| #1 | approved | missing approver from g2 set: [carol] |")
+	require.Contains(t, report, "| #2 | missing approver from g2 set: [bob] | missing approver from g2 set: [carol] |")
+	require.NotContains(t, report, "| #3 | approved")
+
+	// PR numbers are replayed instead of a date range.
+	out.Reset()
+	err = b.Simulate(context.Background(), current, []int{4}, time.Time{}, time.Time{}, &out)
+	require.NoError(t, err)
+	require.Contains(t, out.String(), "# Simulation of 1 PRs")
+	require.Contains(t, out.String(), "No differences.")
+
+	// Identical configs pick the same reviewers from sets with several
+	// reviewers.
+	several := map[string]review.Reviewer{
+		"alice": {Owner: true},
+		"amy":   {Owner: true},
+		"anna":  {Owner: true},
+		"bob":   {},
+		"carol": {},
+		"erin":  {},
+		"dev":   {},
+	}
+	b.c.Review = newAssignments(several)
+	out.Reset()
+	err = b.Simulate(context.Background(), newAssignments(several), nil, opened(1).Truncate(24*time.Hour), opened(10), &out)
+	require.NoError(t, err)
+	require.Contains(t, out.String(), "assigns different reviewers to 0 PRs and changes the approval result of 0 PRs")
+	require.NotContains(t, out.String(), "**")
+
+	// PRs must be selected.
+	err = b.Simulate(context.Background(), candidate, nil, time.Time{}, time.Time{}, &out)
+	require.Error(t, err)
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bot
+
+import (
+	"bufio"
+	"context"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/shared-workflows/bot/internal/match"
+)
+
+// gitAttributesPath is the path of the git attributes file that marks
+// generated files with linguist-generated.
+const gitAttributesPath = ".gitattributes"
+
+// defaultSizeThresholds are the sizes used by repositories that don't
+// configure their own.
+var defaultSizeThresholds = sizeThresholds{
+	Medium: 100,
+	Large:  600,
+	XLarge: 1500,
+}
+
+// sizeConfig configures how the size of a PR is measured.
+type sizeConfig struct {
+	// Thresholds are the number of changed lines at which a PR moves to a
+	// larger size bucket.
+	Thresholds sizeThresholds `yaml:"thresholds"`
+	// Generated are gitattributes-style patterns of generated files, which
+	// don't count towards the size of a PR.
+	Generated []string `yaml:"generated"`
+
+	// generated are the compiled patterns of Generated and of the files
+	// marked linguist-generated in .gitattributes, in order.
+	generated []generatedPattern
+}
+
+// sizeThresholds are the smallest number of changed lines of the medium,
+// large and xlarge size buckets.
+type sizeThresholds struct {
+	Medium int `yaml:"md"`
+	Large  int `yaml:"lg"`
+	XLarge int `yaml:"xl"`
+}
+
+// generatedPattern marks the files matching re as generated or, for
+// -linguist-generated attributes, not generated.
+type generatedPattern struct {
+	re        *regexp.Regexp
+	generated bool
+}
+
+// check validates the config and sets the defaults.
+func (s *sizeConfig) check() error {
+	if s.Thresholds.Medium == 0 {
+		s.Thresholds.Medium = defaultSizeThresholds.Medium
+	}
+	if s.Thresholds.Large == 0 {
+		s.Thresholds.Large = defaultSizeThresholds.Large
+	}
+	if s.Thresholds.XLarge == 0 {
+		s.Thresholds.XLarge = defaultSizeThresholds.XLarge
+	}
+	t := s.Thresholds
+	if t.Medium < 0 || t.Medium >= t.Large || t.Large >= t.XLarge {
+		return trace.BadParameter("size thresholds must increase from md to xl, got md=%v lg=%v xl=%v", t.Medium, t.Large, t.XLarge)
+	}
+
+	for _, pattern := range s.Generated {
+		re, err := match.CompilePath(pattern)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		s.generated = append(s.generated, generatedPattern{re: re, generated: true})
+	}
+	return nil
+}
+
+// thresholds returns the configured thresholds, or the defaults for a nil
+// config.
+func (s *sizeConfig) thresholds() sizeThresholds {
+	if s == nil {
+		return defaultSizeThresholds
+	}
+	return s.Thresholds
+}
+
+// isGenerated returns true if the last pattern that matches name marks it as
+// generated.
+func (s *sizeConfig) isGenerated(name string) bool {
+	if s == nil {
+		return false
+	}
+	generated := false
+	for _, p := range s.generated {
+		if p.re.MatchString(name) {
+			generated = p.generated
+		}
+	}
+	return generated
+}
+
+// addGitAttributes adds the linguist-generated patterns of a .gitattributes
+// file. They take precedence over Generated, like later lines of the file
+// take precedence over earlier ones.
+func (s *sizeConfig) addGitAttributes(data string) error {
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], `"`) {
+			continue
+		}
+		for _, attr := range fields[1:] {
+			var generated bool
+			switch attr {
+			case "linguist-generated", "linguist-generated=true":
+				generated = true
+			case "-linguist-generated", "!linguist-generated", "linguist-generated=false":
+				generated = false
+			default:
+				continue
+			}
+			re, err := match.CompilePath(fields[0])
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			s.generated = append(s.generated, generatedPattern{re: re, generated: generated})
+		}
+	}
+	return trace.Wrap(scanner.Err())
+}
+
+// loadSizeConfig returns the size config of the labeler config on ref,
+// including the generated files from .gitattributes. A config that can't be
+// loaded is logged and the default sizes are used instead, so a broken
+// labeler config doesn't block reviews.
+func (b *Bot) loadSizeConfig(ctx context.Context, ref string) *sizeConfig {
+	config, err := b.loadLabelConfig(ctx, ref)
+	if err != nil {
+		log.Printf("Failed to load the size config, using the default sizes: %v.", err)
+		return nil
+	}
+	return &config.Size
+}
+
+// loadGitAttributes adds the generated files of the .gitattributes file on
+// ref to sizes.
+func (b *Bot) loadGitAttributes(ctx context.Context, ref string, sizes *sizeConfig) error {
+	data, err := b.c.GitHub.GetContents(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		gitAttributesPath,
+		ref)
+	switch {
+	case trace.IsNotFound(err):
+		return nil
+	case err != nil:
+		return trace.Wrap(err)
+	}
+
+	if err := sizes.addGitAttributes(string(data)); err != nil {
+		return trace.Wrap(err, "parsing %v", gitAttributesPath)
+	}
+	log.Printf("Loaded generated files from %v.", gitAttributesPath)
+	return nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/shared-workflows/bot/internal/env"
+	"github.com/gravitational/shared-workflows/bot/internal/github"
+)
+
+func TestSizeConfig(t *testing.T) {
+	config, err := parseLabelConfig([]byte(`
+size:
+  thresholds: {md: 10, lg: 20}
+  generated: ["*.gen.go", "docs/api/"]
+`))
+	require.NoError(t, err)
+	sizes := &config.Size
+	require.Equal(t, sizeThresholds{Medium: 10, Large: 20, XLarge: 1500}, sizes.Thresholds)
+
+	require.NoError(t, sizes.addGitAttributes(`
+# Generated code
+*.pb.ts linguist-generated
+lib/gen/** linguist-generated=true text eol=lf
+docs/api/index.md -linguist-generated
+"quoted file" linguist-generated
+`))
+
+	for name, generated := range map[string]bool{
+		"lib/a.gen.go":      true,
+		"web/a.pb.ts":       true,
+		"lib/gen/a/b.go":    true,
+		"docs/api/a.md":     true,
+		"docs/api/index.md": false,
+		"lib/a.go":          false,
+	} {
+		require.Equal(t, generated, sizes.isGenerated(name), name)
+	}
+
+	files := []github.PullRequestFile{
+		{Name: "lib/a.go", Additions: 15},
+		{Name: "lib/a.gen.go", Additions: 5000},
+		{Name: "lib/gen/a.go", Additions: 5000},
+	}
+	require.Equal(t, medium, prSize(files, sizes))
+	require.Equal(t, xlarge, prSize(files, nil))
+	require.False(t, xlargeRequiresAdminApproval(files, sizes))
+
+	for _, invalid := range []string{
+		"size: {thresholds: {md: 700}}",
+		"size: {thresholds: {md: -1}}",
+		"size: {thresholds: {lg: 100, xl: 50}}",
+		"size: {generated: ['a[b']}",
+	} {
+		_, err := parseLabelConfig([]byte(invalid))
+		require.Error(t, err, invalid)
+	}
+}
+
+// TestLoadSizeConfig checks that the size config and .gitattributes are read
+// from the repository.
+func TestLoadSizeConfig(t *testing.T) {
+	gh := &fakeGithub{
+		contents: map[string][]byte{
+			defaultLabelConfigPath: []byte("size: {thresholds: {xl: 2000}}"),
+			gitAttributesPath:      []byte("*.gen.go linguist-generated"),
+		},
+	}
+	b := &Bot{
+		c: &Config{
+			Environment: &env.Environment{Organization: "foo", Repository: "teleport"},
+			GitHub:      gh,
+		},
+	}
+
+	sizes := b.loadSizeConfig(context.Background(), "master")
+	require.Equal(t, 2000, sizes.thresholds().XLarge)
+	require.True(t, sizes.isGenerated("a.gen.go"))
+
+	delete(gh.contents, defaultLabelConfigPath)
+	sizes = b.loadSizeConfig(context.Background(), "master")
+	require.Equal(t, defaultSizeThresholds, sizes.thresholds())
+	require.True(t, sizes.isGenerated("a.gen.go"))
+
+	// Broken configs fall back to the default sizes.
+	gh.contents[defaultLabelConfigPath] = []byte("size: {thresholds: {md: 50, lg: 10}}")
+	sizes = b.loadSizeConfig(context.Background(), "master")
+	require.Equal(t, defaultSizeThresholds, sizes.thresholds())
+	require.False(t, sizes.isGenerated("a.gen.go"))
+}
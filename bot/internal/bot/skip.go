@@ -2,9 +2,6 @@ package bot
 
 import (
 	"context"
-	"log"
-	"slices"
-	"strings"
 
 	"github.com/gravitational/trace"
 )
@@ -18,35 +15,18 @@ func (b *Bot) skipItems(ctx context.Context, skipPrefix string) ([]string, error
 		return nil, nil
 	}
 
-	comments, err := b.c.GitHub.ListComments(ctx,
-		b.c.Environment.Organization,
-		b.c.Environment.Repository,
-		b.c.Environment.Number,
-	)
+	invocations, err := b.commandInvocations(ctx, command{
+		name:       skipPrefix,
+		permission: adminPermission,
+		prefix:     true,
+	})
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
 
 	var itemsToSkip []string
-
-	admins := b.c.Review.GetAdminCheckers(b.c.Environment.Author)
-	for _, c := range comments {
-		if !slices.Contains(admins, c.Author) {
-			log.Printf("ignoring comment from non-admin %v", c.Author)
-			continue
-		}
-		// non-admins can edit comments from admins, so we only
-		// consider comments that have not been updated
-		if !c.CreatedAt.IsZero() && c.CreatedAt != c.UpdatedAt {
-			log.Printf("ignoring edited comment from %v", c.Author)
-			continue
-		}
-		if strings.HasPrefix(c.Body, skipPrefix) {
-			for _, testName := range strings.Fields(c.Body)[1:] {
-				itemsToSkip = append(itemsToSkip, testName)
-			}
-		}
+	for _, args := range invocations {
+		itemsToSkip = append(itemsToSkip, args...)
 	}
-
 	return itemsToSkip, nil
 }
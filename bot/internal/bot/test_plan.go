@@ -10,6 +10,15 @@ import (
 	"github.com/gravitational/trace"
 )
 
+const (
+	// noTestPlanLabel is the label that skips the manual test plan check.
+	noTestPlanLabel = "no-test-plan"
+
+	// testPlanCommentMarker identifies the comment that lists the missing
+	// required test cases of the PR.
+	testPlanCommentMarker = "<!-- bot:test-plan -->"
+)
+
 var (
 	testPlanHeadingRegex  = regexp.MustCompile(`(?mi)^## Manual Test Plan\s*$`)
 	nextHeadingRegex      = regexp.MustCompile(`(?m)^#{1,2} `)
@@ -44,16 +53,28 @@ func (b *Bot) ValidateManualTestPlan(ctx context.Context) error {
 		return trace.Wrap(err, "failed to retrieve pull request for https://github.com/%s/%s/pull/%d", b.c.Environment.Organization, b.c.Environment.Repository, b.c.Environment.Number)
 	}
 
-	const noTestPlanLabel string = "no-test-plan"
 	if slices.Contains(pull.UnsafeLabels, noTestPlanLabel) {
 		log.Printf("PR contains %q label, skipping test plan check", noTestPlanLabel)
 		return nil
 	}
 
+	required, err := b.requiredTestCases(ctx, pull)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	missing := missingTestCases(pull.UnsafeBody, required)
+	if err := b.upsertComment(ctx, testPlanCommentMarker, renderMissingTestCases(missing), len(missing) > 0); err != nil {
+		log.Printf("Failed to update the test plan comment: %v", err)
+	}
+
 	if err := validateTestPlanContents(pull.UnsafeBody); err != nil {
 		return trace.Wrap(err)
 	}
 
+	if len(missing) > 0 {
+		return trace.BadParameter("The manual test plan is missing %v required test cases", len(missing))
+	}
+
 	return nil
 }
 
@@ -65,12 +86,7 @@ func validateTestPlanContents(body string) error {
 		return trace.BadParameter(`The PR description must contain a "Manual Test Plan" section, please add one, or a "no-test-plan" label if a test plan does not apply to this change`)
 	}
 
-	// Extract section content from after the heading to the next same-level or higher heading.
-	sectionStart := loc[1]
-	section := body[sectionStart:]
-	if nextLoc := nextHeadingRegex.FindStringIndex(section); nextLoc != nil {
-		section = section[:nextLoc[0]]
-	}
+	section := testPlanSection(body, loc)
 
 	// Find the ### Test Environment sub-heading and verify it has content.
 	envLoc := testEnvHeadingRegex.FindStringIndex(section)
@@ -105,3 +121,13 @@ func validateTestPlanContents(body string) error {
 
 	return nil
 }
+
+// testPlanSection returns the content of the manual test plan section, from
+// after its heading at loc to the next same-level or higher heading.
+func testPlanSection(body string, loc []int) string {
+	section := body[loc[1]:]
+	if nextLoc := nextHeadingRegex.FindStringIndex(section); nextLoc != nil {
+		section = section[:nextLoc[0]]
+	}
+	return section
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bot
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/gravitational/trace"
+	"gopkg.in/yaml.v3"
+
+	"github.com/gravitational/shared-workflows/bot/internal/github"
+	"github.com/gravitational/shared-workflows/bot/internal/match"
+)
+
+// testPlanConfigPath is the path of the test plan templates in the target
+// repository.
+const testPlanConfigPath = ".github/test-plans.yaml"
+
+// testCaseRegex matches the test case checkboxes of a test plan, capturing
+// the check mark and the description.
+var testCaseRegex = regexp.MustCompile(`(?m)^\s*[-*] \[([ xX])\][ \t]*(.*)$`)
+
+// testPlanConfig holds the test plan templates of a repository.
+type testPlanConfig struct {
+	// Templates are the test plan templates, the cases of every template
+	// that matches the PR are required.
+	Templates []testPlanTemplate `yaml:"templates"`
+}
+
+// testPlanTemplate requires test cases from PRs that change matching files.
+type testPlanTemplate struct {
+	// Name describes the template, for example "auth".
+	Name string `yaml:"name"`
+	// Paths are gitignore-style patterns of the changed files the template
+	// applies to.
+	Paths []string `yaml:"paths"`
+	// Cases are the test cases that must be in the manual test plan and be
+	// checked.
+	Cases []string `yaml:"cases"`
+}
+
+// requiredTestCase is a test case that a template requires.
+type requiredTestCase struct {
+	// Template is the name of the template requiring the case.
+	Template string
+	// Case is the description of the test case.
+	Case string
+	// Unchecked is true if the case is in the test plan but isn't checked.
+	Unchecked bool
+}
+
+// parseTestPlanConfig parses and validates the test plan templates.
+func parseTestPlanConfig(data []byte) (*testPlanConfig, error) {
+	var config testPlanConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for i, template := range config.Templates {
+		if len(template.Paths) == 0 || len(template.Cases) == 0 {
+			return nil, trace.BadParameter("template %v: paths and cases are required", i)
+		}
+		if err := match.CheckPaths(template.Paths...); err != nil {
+			return nil, trace.Wrap(err, "template %v", i)
+		}
+	}
+	return &config, nil
+}
+
+// requiredTestCases returns the test cases that the templates on the base
+// branch require for the files changed by the PR. The templates are read
+// from the base branch so a PR can't change its own requirements.
+func (b *Bot) requiredTestCases(ctx context.Context, pull github.PullRequest) ([]requiredTestCase, error) {
+	data, err := b.c.GitHub.GetContents(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		testPlanConfigPath,
+		pull.UnsafeBase.SHA)
+	switch {
+	case trace.IsNotFound(err):
+		return nil, nil
+	case err != nil:
+		return nil, trace.Wrap(err)
+	}
+	config, err := parseTestPlanConfig(data)
+	if err != nil {
+		return nil, trace.Wrap(err, "parsing %v", testPlanConfigPath)
+	}
+
+	files, err := b.c.GitHub.ListFiles(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		b.c.Environment.Number)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	var required []requiredTestCase
+	for _, template := range config.Templates {
+		if !slices.ContainsFunc(files, template.matchFile) {
+			continue
+		}
+		for _, c := range template.Cases {
+			required = append(required, requiredTestCase{Template: template.Name, Case: c})
+		}
+	}
+	return required, nil
+}
+
+// matchFile returns true if file matches one of the paths of the template.
+func (t *testPlanTemplate) matchFile(file github.PullRequestFile) bool {
+	return slices.ContainsFunc(t.Paths, func(pattern string) bool {
+		return match.Path(pattern, file.Name)
+	})
+}
+
+// missingTestCases returns the required test cases that aren't checked in
+// the manual test plan of body. A case is covered by a checked test case
+// that contains its description, ignoring case and spacing.
+func missingTestCases(body string, required []requiredTestCase) []requiredTestCase {
+	if len(required) == 0 {
+		return nil
+	}
+
+	var checked, unchecked []string
+	if loc := testPlanHeadingRegex.FindStringIndex(body); loc != nil {
+		for _, m := range testCaseRegex.FindAllStringSubmatch(testPlanSection(body, loc), -1) {
+			if strings.TrimSpace(m[1]) == "" {
+				unchecked = append(unchecked, normalizeTestCase(m[2]))
+			} else {
+				checked = append(checked, normalizeTestCase(m[2]))
+			}
+		}
+	}
+
+	var missing []requiredTestCase
+	for _, r := range required {
+		want := normalizeTestCase(r.Case)
+		contains := func(c string) bool { return strings.Contains(c, want) }
+		if slices.ContainsFunc(checked, contains) {
+			continue
+		}
+		r.Unchecked = slices.ContainsFunc(unchecked, contains)
+		missing = append(missing, r)
+	}
+	return missing
+}
+
+// normalizeTestCase lower cases a test case and collapses its spacing.
+func normalizeTestCase(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// renderMissingTestCases renders the missing required test cases as a
+// markdown list.
+func renderMissingTestCases(missing []requiredTestCase) string {
+	var sb strings.Builder
+	sb.WriteString("### Required test cases\n\n")
+	if len(missing) == 0 {
+		sb.WriteString("All required test cases are covered by the manual test plan.\n")
+		return sb.String()
+	}
+	sb.WriteString("The files changed by this PR require the following test cases in the manual test plan. " +
+		"Add them to the \"Test Cases\" section and check them once they pass:\n\n")
+	for _, m := range missing {
+		status := "missing"
+		if m.Unchecked {
+			status = "not checked"
+		}
+		fmt.Fprintf(&sb, "- [ ] %v (%v, %v)\n", m.Case, m.Template, status)
+	}
+	return sb.String()
+}
@@ -286,3 +286,74 @@ func TestValidateManualTestPlan(t *testing.T) {
 		})
 	}
 }
+
+func TestRequiredTestCases(t *testing.T) {
+	gh := &fakeGithub{
+		contents: map[string][]byte{
+			"base:" + testPlanConfigPath: []byte(`
+templates:
+  - name: auth
+    paths: ["lib/auth/"]
+    cases: ["Upgrade from previous major version", "Login with SSO"]
+  - name: web
+    paths: ["web/**"]
+    cases: ["Check the web UI"]
+`),
+		},
+		files: []github.PullRequestFile{
+			{Name: "lib/auth/auth.go", Status: github.StatusModified},
+		},
+		pull: github.PullRequest{
+			UnsafeBase: github.Branch{Ref: "master", SHA: "base"},
+			UnsafeBody: strings.Join([]string{
+				"## Manual Test Plan",
+				"",
+				"### Test Environment",
+				"",
+				"staging",
+				"",
+				"### Test Cases",
+				"- [x] Verify login works",
+				"- [ ] Login  with sso and GitHub",
+			}, "\n"),
+		},
+	}
+	b := &Bot{
+		c: &Config{
+			Environment: &env.Environment{Organization: "foo", Repository: "bar"},
+			GitHub:      gh,
+		},
+	}
+
+	require.ErrorContains(t, b.ValidateManualTestPlan(context.Background()), "All test cases have not yet been completed")
+	require.Len(t, gh.comments, 1)
+	require.Contains(t, gh.comments[0].Body, testPlanCommentMarker)
+	require.Contains(t, gh.comments[0].Body, "- [ ] Upgrade from previous major version (auth, missing)")
+	require.Contains(t, gh.comments[0].Body, "- [ ] Login with SSO (auth, not checked)")
+	require.NotContains(t, gh.comments[0].Body, "web UI")
+
+	gh.pull.UnsafeBody = strings.Replace(gh.pull.UnsafeBody, "- [ ]", "- [x]", 1)
+	require.ErrorContains(t, b.ValidateManualTestPlan(context.Background()), "missing 1 required test cases")
+	require.Len(t, gh.comments, 1)
+	require.NotContains(t, gh.comments[0].Body, "Login with SSO")
+
+	gh.pull.UnsafeBody = strings.Join([]string{
+		"## Manual Test Plan",
+		"",
+		"### Test Environment",
+		"",
+		"staging",
+		"",
+		"### Test Cases",
+		"- [x] Upgrade from previous major version",
+		"- [x] Login with SSO and GitHub",
+	}, "\n")
+	require.NoError(t, b.ValidateManualTestPlan(context.Background()))
+	require.Len(t, gh.comments, 1)
+	require.Contains(t, gh.comments[0].Body, "All required test cases are covered")
+
+	_, err := parseTestPlanConfig([]byte("templates: [{name: a, paths: ['a[b'], cases: [x]}]"))
+	require.Error(t, err)
+	_, err = parseTestPlanConfig([]byte("templates: [{name: a, paths: [lib/]}]"))
+	require.Error(t, err)
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/shared-workflows/bot/internal/github"
+	"github.com/gravitational/shared-workflows/bot/internal/review"
+)
+
+// ValidateReviewers checks the reviewers config, the JSON the reviewer
+// assignments were loaded from, for problems that make the bot ignore parts
+// of it or assign reviews to people who can't review. Problems are reported
+// as GitHub Actions error annotations written to w:
+//
+//   - keys that don't match a config field
+//   - reviewers that are both omitted and preferred-only
+//   - groups of code reviewers with fewer than two owners that can be
+//     assigned
+//   - preferred reviewer paths that match no file on branch of the
+//     repository, the default branch if branch is empty
+//   - logins that aren't members of the organization
+func (b *Bot) ValidateReviewers(ctx context.Context, reviewers string, branch string, w io.Writer) error {
+	unknown, err := review.UnknownKeys(reviewers)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	var problems []string
+	for _, key := range unknown {
+		problems = append(problems, fmt.Sprintf("%v: unknown key", key))
+	}
+	problems = append(problems, b.c.Review.Lint()...)
+
+	paths, err := b.lintPreferredPaths(ctx, branch)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	problems = append(problems, paths...)
+
+	for _, login := range b.c.Review.Logins() {
+		// Bots aren't organization members.
+		if strings.HasSuffix(login, "[bot]") {
+			continue
+		}
+		member, err := b.c.GitHub.IsOrgMember(ctx, login, b.c.Environment.Organization)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if !member {
+			problems = append(problems, fmt.Sprintf("%v: not a member of %v", login, b.c.Environment.Organization))
+		}
+	}
+
+	for _, problem := range problems {
+		fmt.Fprintf(w, "::error::%v\n", escapeAnnotationData(problem))
+	}
+	if len(problems) > 0 {
+		return trace.BadParameter("found %v problems in the reviewers config", len(problems))
+	}
+	return nil
+}
+
+// lintPreferredPaths returns the preferred reviewer paths that match no file
+// on branch of the repository. The check fails if the files of the branch
+// can't all be listed.
+func (b *Bot) lintPreferredPaths(ctx context.Context, branch string) ([]string, error) {
+	if branch == "" {
+		var err error
+		branch, err = b.c.GitHub.GetDefaultBranch(ctx,
+			b.c.Environment.Organization,
+			b.c.Environment.Repository)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+	ref, err := b.c.GitHub.GetRef(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		"heads/"+branch)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	files, err := b.c.GitHub.ListCommitFiles(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		ref.SHA,
+		"")
+	if errors.Is(err, github.ErrTruncatedTree) {
+		return nil, trace.Wrap(err, "the tree of %v is too big to check preferred reviewer paths", branch)
+	}
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return b.c.Review.LintPreferredPaths(b.c.Environment, files), nil
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bot
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/shared-workflows/bot/internal/env"
+	"github.com/gravitational/shared-workflows/bot/internal/github"
+	"github.com/gravitational/shared-workflows/bot/internal/review"
+)
+
+func TestValidateReviewers(t *testing.T) {
+	tests := []struct {
+		desc      string
+		reviewers string
+		problems  []string
+	}{
+		{
+			desc: "valid",
+			reviewers: `{
+				"coreReviewers": {
+					"alice": {"owner": true, "preferredReviewerFor": ["lib/auth/"]},
+					"bob": {"owner": true}
+				},
+				"cloudReviewers": {},
+				"codeReviewersOmit": {},
+				"docsReviewers": {},
+				"docsReviewersOmit": {},
+				"admins": ["alice", "dependabot[bot]"]
+			}`,
+		},
+		{
+			desc: "problems",
+			reviewers: `{
+				"coreReviewers": {
+					"alice": {"owner": true, "preferredReviewerFor": ["lib/gone/"]},
+					"bob": {"owner": false, "preferredOnly": true},
+					"mallory": {"owner": true}
+				},
+				"cloudReviewers": {},
+				"codeReviewersOmit": {"bob": true},
+				"docsReviewers": {},
+				"docsReviewersOmit": {},
+				"admins": ["alice"],
+				"loadBalancing": true
+			}`,
+			problems: []string{
+				"::error::loadBalancing: unknown key",
+				"::error::coreReviewers.bob: reviewer is both omitted and preferred-only, so preferredOnly has no effect",
+				`::error::coreReviewers.alice: preferred path "lib/gone/" matches no file in teleport`,
+				"::error::mallory: not a member of gravitational",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			r, err := review.FromString(test.reviewers)
+			require.NoError(t, err)
+			b := &Bot{
+				c: &Config{
+					Environment: &env.Environment{Organization: "gravitational", Repository: "teleport"},
+					GitHub: &fakeGithub{
+						defaultBranch: "master",
+						ref:           github.Reference{SHA: "abc123"},
+						commitFiles:   []string{"lib/auth/auth.go"},
+						orgMembers:    map[string]struct{}{"alice": {}, "bob": {}},
+					},
+					Review: r,
+				},
+			}
+
+			var out bytes.Buffer
+			err = b.ValidateReviewers(context.Background(), test.reviewers, "", &out)
+			if len(test.problems) == 0 {
+				require.NoError(t, err)
+				require.Empty(t, out.String())
+				return
+			}
+			require.Error(t, err)
+			require.Equal(t, test.problems, strings.Split(strings.TrimSpace(out.String()), "\n"))
+		})
+	}
+
+	// Preferred paths can't be checked against a truncated tree.
+	r, err := review.FromString(tests[0].reviewers)
+	require.NoError(t, err)
+	b := &Bot{
+		c: &Config{
+			Environment: &env.Environment{Organization: "gravitational", Repository: "teleport"},
+			GitHub: &fakeGithub{
+				ref:        github.Reference{SHA: "abc123"},
+				commitErr:  github.ErrTruncatedTree,
+				orgMembers: map[string]struct{}{"alice": {}, "bob": {}},
+			},
+			Review: r,
+		},
+	}
+	err = b.ValidateReviewers(context.Background(), tests[0].reviewers, "master", io.Discard)
+	require.ErrorIs(t, err, github.ErrTruncatedTree)
+}
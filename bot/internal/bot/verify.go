@@ -3,6 +3,7 @@ package bot
 import (
 	"context"
 	"errors"
+	"io"
 	"log"
 	"path/filepath"
 	"sort"
@@ -14,54 +15,69 @@ import (
 	"github.com/gravitational/trace"
 )
 
-// Verify is a catch-all for verifying the PR doesn't have any issues.
-func (b *Bot) Verify(ctx context.Context) error {
-	var err error
-	switch b.c.Environment.Repository {
-	case env.AccessGraphRepo:
-		err = b.verifyAccessGraph(ctx)
-	case env.CloudRepo:
-		err = b.verifyCloud(ctx)
+// Verify is a catch-all for verifying the PR doesn't have any issues. It runs
+// the rules of the verify config of the repository, or the built-in rules if
+// the repository has none. Warnings are written to w as workflow annotations.
+func (b *Bot) Verify(ctx context.Context, w io.Writer) error {
+	if b.c.Environment.Number == 0 {
+		// manually skip merge queue runs
+		log.Print("Verify: skipping PR 0")
+		return nil
 	}
-	return trace.Wrap(err)
-}
 
-// verifyAccessGraph runs verification checks for the access-graph repo.
-func (b *Bot) verifyAccessGraph(ctx context.Context) error {
-	// exec DB migration verification
-	return trace.Wrap(b.verifyDBMigrations(ctx))
-}
-
-// verifyCloud runs verification checks for cloud repos.
-// E.g. it is used to verify DB migration files are ordered properly in the Cloud repo.
-func (b *Bot) verifyCloud(ctx context.Context) error {
-	// exec DB migration verification
-	return trace.Wrap(b.verifyDBMigrations(ctx))
-}
+	config, err := b.loadVerifyConfig(ctx, b.c.Environment.UnsafeBase)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if len(config.Rules) == 0 {
+		return nil
+	}
 
-// migrationConfig enables the DB migration verification for a repo/path.
-//
-//	map[repo]: [...path]
-var migrationConfig = map[string][]string{
-	env.AccessGraphRepo: {"migrations/public", "migrations/tenant"},
-	env.CloudRepo:       {"db/migrations", "db/redshift/migrations"},
-}
+	pull, err := b.c.GitHub.GetPullRequest(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		b.c.Environment.Number)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	files, err := b.c.GitHub.ListFiles(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		b.c.Environment.Number)
+	if err != nil {
+		return trace.Wrap(err)
+	}
 
-// verifyDBMigrations runs verifyDBMigration for each
-// of the migration paths defined for the current repository
-func (b *Bot) verifyDBMigrations(ctx context.Context) error {
-	for _, path := range migrationConfig[b.c.Environment.Repository] {
-		err := b.verifyDBMigration(ctx, path)
-		if err != nil {
-			return trace.Wrap(err)
+	var errs []error
+	for _, rule := range config.Rules {
+		log.Printf("Verify: running rule %v", rule.name())
+		if err := verifiers[rule.Type].run(ctx, b, &rule, pull, files, w); err != nil {
+			errs = append(errs, trace.Wrap(err, "rule %v", rule.name()))
 		}
 	}
-	return nil
+	return trace.NewAggregate(errs...)
+}
+
+// migrationConfig enables the DB migration verification for a repo/path in
+// repositories without a verify config.
+//
+//	map[repo]: [...rule]
+var migrationConfig = map[string][]verifyRule{
+	env.AccessGraphRepo: {
+		{Type: dbMigrationsRule, Paths: []string{"migrations/public", "migrations/tenant"}, SQLChecks: true},
+	},
+	env.CloudRepo: {
+		{Type: dbMigrationsRule, Paths: []string{"db/migrations"}, SQLChecks: true},
+		// The SQL checks suggest Postgres statements that Redshift doesn't
+		// support.
+		{Type: dbMigrationsRule, Paths: []string{"db/redshift/migrations"}},
+	},
 }
 
 // verifyDBMigration ensures the DB migration files in a PR have a timestamp
-// that is more recent than the migration files in the base branch.
-func (b *Bot) verifyDBMigration(ctx context.Context, pathPrefix string) error {
+// that is more recent than the migration files in the base branch. With
+// sqlChecks, the SQL of the up migrations is checked too.
+func (b *Bot) verifyDBMigration(ctx context.Context, pull github.PullRequest, pathPrefix string, sqlChecks bool, w io.Writer) error {
 	if b.c.Environment.Number == 0 {
 		// manually skip merge queue runs
 		// TODO(michellescripts) identify temporary branch to pull files changes in merge queue
@@ -102,6 +118,21 @@ func (b *Bot) verifyDBMigration(ctx context.Context, pathPrefix string) error {
 		return nil
 	}
 
+	// every new up migration needs a down migration and IDs must not repeat
+	if err := checkDownMigrations(pathPrefix, prFiles); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := checkUniqueMigrationIDs(prIDs); err != nil {
+		return trace.Wrap(err)
+	}
+
+	// flag dangerous SQL in the up migrations
+	if sqlChecks {
+		if err := b.verifyMigrationSQL(ctx, pull, pathPrefix, prFiles, w); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+
 	// get base branch ref
 	branchRef, err := b.c.GitHub.GetRef(ctx,
 		b.c.Environment.Organization,
@@ -194,6 +225,42 @@ func parseMigrationFileID(file string) (int, error) {
 	return id, nil
 }
 
+// checkDownMigrations ensures that every up migration added by the PR comes
+// with its down migration.
+//
+//	202301031500_subscription-alter.up.sql => 202301031500_subscription-alter.down.sql
+func checkDownMigrations(pathPrefix string, files []github.PullRequestFile) error {
+	names := make(map[string]bool, len(files))
+	for _, f := range files {
+		names[f.Name] = true
+	}
+
+	var missing []string
+	for _, f := range files {
+		if f.Status != github.StatusAdded || !strings.HasPrefix(f.Name, pathPrefix) || !strings.HasSuffix(f.Name, ".up.sql") {
+			continue
+		}
+		if down := strings.TrimSuffix(f.Name, ".up.sql") + ".down.sql"; !names[down] {
+			missing = append(missing, down)
+		}
+	}
+	if len(missing) > 0 {
+		return trace.BadParameter("pull request is missing down migrations: %v", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// checkUniqueMigrationIDs ensures that the sorted migration IDs of the PR are
+// unique.
+func checkUniqueMigrationIDs(ids []int) error {
+	for i := 1; i < len(ids); i++ {
+		if ids[i] == ids[i-1] {
+			return trace.BadParameter("pull request has several migrations with ID %d; each migration needs a unique ID", ids[i])
+		}
+	}
+	return nil
+}
+
 // excludeDownMigrationFiles returns the same list of names
 // excluding files whose suffix is '.down.sql'.
 func excludeDownMigrationFiles(names []string) []string {
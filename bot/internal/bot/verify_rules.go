@@ -0,0 +1,361 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bot
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/gravitational/trace"
+	"gopkg.in/yaml.v3"
+
+	"github.com/gravitational/shared-workflows/bot/internal/github"
+	"github.com/gravitational/shared-workflows/bot/internal/match"
+)
+
+// verifyConfigPath is the path of the verify config in the target
+// repository.
+const verifyConfigPath = ".github/verify.yaml"
+
+const (
+	// dbMigrationsRule checks that new DB migrations are more recent than
+	// the migrations of the base branch.
+	dbMigrationsRule = "db-migrations"
+	// forbiddenPathsRule forbids external contributors from changing some
+	// files.
+	forbiddenPathsRule = "forbidden-paths"
+	// pairedFilesRule requires files to change together, for example
+	// generated code with its source.
+	pairedFilesRule = "paired-files"
+	// maxFileSizeRule limits the size of added and changed files.
+	maxFileSizeRule = "max-file-size"
+	// lockfileRule requires lockfiles to change with their manifest.
+	lockfileRule = "lockfile"
+)
+
+// defaultLockfiles maps manifests to their lockfile for lockfile rules that
+// don't set lockfiles.
+var defaultLockfiles = map[string]string{
+	"go.mod": "go.sum",
+}
+
+// verifier is a type of verify rule.
+type verifier struct {
+	// check validates and compiles the config of a rule.
+	check func(rule *verifyRule) error
+	// run verifies the PR, files are all the files changed by the PR.
+	// Warnings are written to w as workflow annotations.
+	run func(ctx context.Context, b *Bot, rule *verifyRule, pull github.PullRequest, files []github.PullRequestFile, w io.Writer) error
+}
+
+// verifiers are the verify rule types, by name.
+var verifiers = map[string]verifier{
+	dbMigrationsRule: {
+		check: requirePaths,
+		run:   verifyDBMigrationsRule,
+	},
+	forbiddenPathsRule: {
+		check: requirePaths,
+		run:   verifyForbiddenPaths,
+	},
+	pairedFilesRule: {
+		check: checkPairedFiles,
+		run:   verifyPairedFiles,
+	},
+	maxFileSizeRule: {
+		check: checkMaxFileSize,
+		run:   verifyMaxFileSize,
+	},
+	lockfileRule: {
+		check: func(rule *verifyRule) error { return nil },
+		run:   verifyLockfiles,
+	},
+}
+
+// verifyConfig holds the verify rules of a repository.
+type verifyConfig struct {
+	// Rules are all run for every PR.
+	Rules []verifyRule `yaml:"rules"`
+}
+
+// verifyRule configures a verifier. Which fields apply depends on the type.
+type verifyRule struct {
+	// Name describes the rule in errors, defaults to the type.
+	Name string `yaml:"name"`
+	// Type is the verifier type, for example "paired-files".
+	Type string `yaml:"type"`
+	// Paths are gitattributes-style patterns of the files the rule applies
+	// to. For db-migrations, they are the migration directories.
+	Paths []string `yaml:"paths"`
+	// When are patterns of the files that require the Require files to
+	// change too (paired-files).
+	When []string `yaml:"when"`
+	// Require are patterns that each need a matching changed file when a
+	// When file changes (paired-files).
+	Require []string `yaml:"require"`
+	// MaxBytes is the maximum size of a file (max-file-size).
+	MaxBytes int `yaml:"max_bytes"`
+	// Lockfiles maps manifest names to the lockfile next to them
+	// (lockfile), defaults to go.mod and go.sum.
+	Lockfiles map[string]string `yaml:"lockfiles"`
+	// SQLChecks checks the SQL of new up migrations for Postgres statements
+	// that lock tables or lose data (db-migrations).
+	SQLChecks bool `yaml:"sql_checks"`
+
+	paths   []*regexp.Regexp
+	when    []*regexp.Regexp
+	require []*regexp.Regexp
+}
+
+// name returns the name of the rule.
+func (r *verifyRule) name() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return r.Type
+}
+
+// matchPaths returns true if the rule has no paths or name matches one.
+func (r *verifyRule) matchPaths(name string) bool {
+	return len(r.paths) == 0 || matchAnyPattern(r.paths, name)
+}
+
+// parseVerifyConfig parses and validates a YAML verify config.
+func parseVerifyConfig(data []byte) (*verifyConfig, error) {
+	var config verifyConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for i := range config.Rules {
+		rule := &config.Rules[i]
+		v, ok := verifiers[rule.Type]
+		if !ok {
+			return nil, trace.BadParameter("rule %v: unknown type %q", i, rule.Type)
+		}
+		var err error
+		if rule.paths, err = compileGitPatterns(rule.Paths); err != nil {
+			return nil, trace.Wrap(err, "rule %v", i)
+		}
+		if err := v.check(rule); err != nil {
+			return nil, trace.Wrap(err, "rule %v", i)
+		}
+	}
+	return &config, nil
+}
+
+// defaultVerifyConfig returns the built-in rules used by repositories without
+// a verify config.
+func defaultVerifyConfig(repository string) *verifyConfig {
+	var config verifyConfig
+	config.Rules = append(config.Rules, migrationConfig[repository]...)
+	return &config
+}
+
+// loadVerifyConfig reads the verify config from ref, falling back to the
+// built-in rules if the repository has none.
+func (b *Bot) loadVerifyConfig(ctx context.Context, ref string) (*verifyConfig, error) {
+	data, err := b.c.GitHub.GetContents(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		verifyConfigPath,
+		ref)
+	switch {
+	case trace.IsNotFound(err):
+		log.Printf("No %v found, using the default verify rules.", verifyConfigPath)
+		return defaultVerifyConfig(b.c.Environment.Repository), nil
+	case err != nil:
+		return nil, trace.Wrap(err)
+	}
+	config, err := parseVerifyConfig(data)
+	if err != nil {
+		return nil, trace.Wrap(err, "parsing %v", verifyConfigPath)
+	}
+	return config, nil
+}
+
+// compileGitPatterns compiles gitattributes-style patterns.
+func compileGitPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	var res []*regexp.Regexp
+	for _, pattern := range patterns {
+		re, err := match.CompilePath(pattern)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+// matchAnyPattern returns true if name matches one of the patterns.
+func matchAnyPattern(patterns []*regexp.Regexp, name string) bool {
+	return slices.ContainsFunc(patterns, func(re *regexp.Regexp) bool {
+		return re.MatchString(name)
+	})
+}
+
+// requirePaths checks that a rule has paths.
+func requirePaths(rule *verifyRule) error {
+	if len(rule.Paths) == 0 {
+		return trace.BadParameter("%v rules require paths", rule.Type)
+	}
+	return nil
+}
+
+// verifyDBMigrationsRule checks that new DB migrations in each of the
+// migration directories of the rule are more recent than the base branch.
+func verifyDBMigrationsRule(ctx context.Context, b *Bot, rule *verifyRule, pull github.PullRequest, _ []github.PullRequestFile, w io.Writer) error {
+	for _, path := range rule.Paths {
+		if err := b.verifyDBMigration(ctx, pull, path, rule.SQLChecks, w); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// verifyForbiddenPaths fails if an external contributor changes a file that
+// matches the rule paths.
+func verifyForbiddenPaths(ctx context.Context, b *Bot, rule *verifyRule, _ github.PullRequest, files []github.PullRequestFile, _ io.Writer) error {
+	var forbidden []string
+	for _, file := range files {
+		if matchAnyPattern(rule.paths, file.Name) {
+			forbidden = append(forbidden, file.Name)
+		}
+	}
+	if len(forbidden) == 0 {
+		return nil
+	}
+
+	internal, err := b.isInternal(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if internal {
+		return nil
+	}
+	return trace.AccessDenied("external contributors can't change %v", strings.Join(forbidden, ", "))
+}
+
+// checkPairedFiles checks and compiles the patterns of a paired-files rule.
+func checkPairedFiles(rule *verifyRule) error {
+	if len(rule.When) == 0 || len(rule.Require) == 0 {
+		return trace.BadParameter("%v rules require when and require", rule.Type)
+	}
+	var err error
+	if rule.when, err = compileGitPatterns(rule.When); err != nil {
+		return trace.Wrap(err)
+	}
+	rule.require, err = compileGitPatterns(rule.Require)
+	return trace.Wrap(err)
+}
+
+// verifyPairedFiles fails if the PR changes a file matching When without
+// changing a file matching each of the Require patterns.
+func verifyPairedFiles(_ context.Context, _ *Bot, rule *verifyRule, _ github.PullRequest, files []github.PullRequestFile, _ io.Writer) error {
+	var trigger string
+	for _, file := range files {
+		if file.Status != github.StatusRemoved && matchAnyPattern(rule.when, file.Name) {
+			trigger = file.Name
+			break
+		}
+	}
+	if trigger == "" {
+		return nil
+	}
+
+	var missing []string
+	for i, re := range rule.require {
+		if !slices.ContainsFunc(files, func(file github.PullRequestFile) bool {
+			return re.MatchString(file.Name)
+		}) {
+			missing = append(missing, rule.Require[i])
+		}
+	}
+	if len(missing) > 0 {
+		return trace.BadParameter("%v changed, but no file matching %v changed with it", trigger, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// checkMaxFileSize checks the size limit of a max-file-size rule.
+func checkMaxFileSize(rule *verifyRule) error {
+	if rule.MaxBytes <= 0 {
+		return trace.BadParameter("%v rules require a positive max_bytes", rule.Type)
+	}
+	return nil
+}
+
+// verifyMaxFileSize fails if an added or changed file matching the rule paths
+// is larger than the limit at the head of the PR.
+func verifyMaxFileSize(ctx context.Context, b *Bot, rule *verifyRule, pull github.PullRequest, files []github.PullRequestFile, _ io.Writer) error {
+	var tooLarge []string
+	for _, file := range files {
+		// Binary files have no additions, so only removed files are skipped.
+		if file.Status == github.StatusRemoved || !rule.matchPaths(file.Name) {
+			continue
+		}
+		size, err := b.c.GitHub.GetFileSize(ctx,
+			b.c.Environment.Organization,
+			b.c.Environment.Repository,
+			file.Name,
+			pull.UnsafeHead.SHA)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if size > rule.MaxBytes {
+			tooLarge = append(tooLarge, fmt.Sprintf("%v (%v bytes)", file.Name, size))
+		}
+	}
+	if len(tooLarge) > 0 {
+		return trace.BadParameter("files must not be larger than %v bytes: %v", rule.MaxBytes, strings.Join(tooLarge, ", "))
+	}
+	return nil
+}
+
+// verifyLockfiles fails if a manifest changes without the lockfile in the
+// same directory, for example go.mod without go.sum.
+func verifyLockfiles(_ context.Context, _ *Bot, rule *verifyRule, _ github.PullRequest, files []github.PullRequestFile, _ io.Writer) error {
+	lockfiles := rule.Lockfiles
+	if len(lockfiles) == 0 {
+		lockfiles = defaultLockfiles
+	}
+
+	changed := make(map[string]bool, len(files))
+	for _, file := range files {
+		changed[file.Name] = true
+	}
+
+	var missing []string
+	for _, file := range files {
+		lockfile, ok := lockfiles[path.Base(file.Name)]
+		if !ok || file.Status == github.StatusRemoved || !rule.matchPaths(file.Name) {
+			continue
+		}
+		if name := path.Join(path.Dir(file.Name), lockfile); !changed[name] {
+			missing = append(missing, fmt.Sprintf("%v changed without %v", file.Name, name))
+		}
+	}
+	if len(missing) > 0 {
+		return trace.BadParameter("lockfiles are out of date: %v", strings.Join(missing, ", "))
+	}
+	return nil
+}
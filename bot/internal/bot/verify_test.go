@@ -2,11 +2,15 @@ package bot
 
 import (
 	"context"
+	"io"
+	"maps"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/gravitational/shared-workflows/bot/internal/env"
 	"github.com/gravitational/shared-workflows/bot/internal/github"
+	"github.com/gravitational/shared-workflows/bot/internal/review"
 	"github.com/stretchr/testify/require"
 )
 
@@ -106,6 +110,7 @@ func TestVerifyCloudDBMigration(t *testing.T) {
 	bot, err := New(&Config{
 		GitHub: fgh,
 		Environment: &env.Environment{
+			Number:     1,
 			UnsafeBase: "master",
 		},
 	})
@@ -116,13 +121,17 @@ func TestVerifyCloudDBMigration(t *testing.T) {
 	cases := []struct {
 		prFiles     []string
 		branchFiles []string
+		sql         map[string]string
+		noSQLChecks bool
 		status      github.FileStatus
 		expectErr   bool
+		expectOut   string
 	}{
 		{}, // 0    no migration files in branch or pr
 		{ // 1 OK   no migration files in base branch
 			prFiles: []string{
 				"db/202301031501_adding.up.sql",
+				"db/202301031501_adding.down.sql",
 			},
 		},
 		{ // 2 OK   no migration files in PR
@@ -168,30 +177,89 @@ func TestVerifyCloudDBMigration(t *testing.T) {
 				"db/202301031500_exists.up.sql",
 			},
 		},
+		{ // 7 FAIL  up migration without down migration
+			prFiles: []string{
+				"db/202301031501_adding.up.sql",
+			},
+			expectErr: true,
+		},
+		{ // 8 FAIL  duplicate migration IDs in PR
+			prFiles: []string{
+				"db/202301031501_adding.up.sql",
+				"db/202301031501_adding.down.sql",
+				"db/202301031501_other.up.sql",
+				"db/202301031501_other.down.sql",
+			},
+			expectErr: true,
+		},
+		{ // 9 FAIL  dangerous SQL in up migration
+			prFiles: []string{
+				"db/202301031501_adding.up.sql",
+				"db/202301031501_adding.down.sql",
+			},
+			sql: map[string]string{
+				"db/202301031501_adding.up.sql": "CREATE INDEX users_email ON users (email);",
+			},
+			expectErr: true,
+		},
+		{ // 10 OK  dangerous SQL in down migration
+			prFiles: []string{
+				"db/202301031501_adding.up.sql",
+				"db/202301031501_adding.down.sql",
+			},
+			sql: map[string]string{
+				"db/202301031501_adding.down.sql": "DROP TABLE users;",
+			},
+		},
+		{ // 11 OK  warning for irreversible SQL in up migration
+			prFiles: []string{
+				"db/202301031501_adding.up.sql",
+				"db/202301031501_adding.down.sql",
+			},
+			sql: map[string]string{
+				"db/202301031501_adding.up.sql": "SELECT 1;\nDROP TABLE users;",
+			},
+			expectOut: "::warning file=db/202301031501_adding.up.sql,line=2::dropping a table is not reversible, its data is lost\n",
+		},
+		{ // 12 OK  dangerous SQL without SQL checks
+			prFiles: []string{
+				"db/202301031501_adding.up.sql",
+				"db/202301031501_adding.down.sql",
+			},
+			sql: map[string]string{
+				"db/202301031501_adding.up.sql": "CREATE INDEX users_email ON users (email);",
+			},
+			noSQLChecks: true,
+		},
 	}
 	fghBaseline := *fgh
 	for i, test := range cases {
 		if test.status == github.StatusUnknown {
 			test.status = defaultStatus
 		}
+		fgh.contents = make(map[string][]byte)
 		for _, f := range test.prFiles {
 			fgh.files = append(fgh.files, github.PullRequestFile{
 				Name:   f,
 				Status: test.status,
 			})
+			fgh.contents[f] = []byte("SELECT 1;")
+		}
+		for f, sql := range test.sql {
+			fgh.contents[f] = []byte(sql)
 		}
 		fgh.commitFiles = append(fgh.commitFiles, test.branchFiles...)
 
-		err = bot.verifyDBMigration(context.Background(), "db")
-		if err != nil {
-			if test.expectErr == (err == nil) {
-				if test.expectErr {
-					t.Fatalf("[%d] expected error", i)
-				} else {
-					t.Fatalf("[%d] unexpected error: %v", i, err)
-				}
+		var out strings.Builder
+		err = bot.verifyDBMigration(context.Background(), fgh.pull, "db", !test.noSQLChecks, &out)
+		if test.expectErr == (err == nil) {
+			if test.expectErr {
+				t.Fatalf("[%d] expected error", i)
+			} else {
+				t.Fatalf("[%d] unexpected error: %v", i, err)
 			}
 		}
+		require.Equal(t, test.expectOut, out.String(), "[%d]", i)
 
 		*fgh = fghBaseline
 	}
@@ -212,3 +280,177 @@ func TestFilterDownMigrationFiles(t *testing.T) {
 		require.Equal(t, test.expect, got)
 	}
 }
+
+func TestVerifyRules(t *testing.T) {
+	r, err := review.New(&review.Config{
+		Admins:            []string{"admin"},
+		CoreReviewers:     make(map[string]review.Reviewer),
+		CloudReviewers:    make(map[string]review.Reviewer),
+		CodeReviewersOmit: make(map[string]bool),
+		DocsReviewers:     make(map[string]review.Reviewer),
+		DocsReviewersOmit: make(map[string]bool),
+	})
+	require.NoError(t, err)
+
+	config := []byte(`
+rules:
+  - type: forbidden-paths
+    paths: [".github/workflows/"]
+  - name: generated protos
+    type: paired-files
+    when: ["api/proto/**/*.proto"]
+    require: ["api/gen/"]
+  - type: max-file-size
+    max_bytes: 10
+    paths: ["*.png"]
+  - type: lockfile
+`)
+
+	tests := []struct {
+		desc         string
+		author       string
+		files        []github.PullRequestFile
+		contents     map[string][]byte
+		errorMessage string
+	}{
+		{
+			desc:   "valid",
+			author: "external",
+			files: []github.PullRequestFile{
+				{Name: "api/proto/teleport/a.proto", Status: github.StatusModified, Additions: 1},
+				{Name: "api/gen/proto/go/a.pb.go", Status: github.StatusModified, Additions: 1},
+				{Name: "go.mod", Status: github.StatusModified, Additions: 1},
+				{Name: "go.sum", Status: github.StatusModified, Additions: 1},
+				{Name: "web/a.png", Status: github.StatusAdded, Additions: 1},
+			},
+			contents: map[string][]byte{"head:web/a.png": []byte("small")},
+		},
+		{
+			desc:   "forbidden-path",
+			author: "external",
+			files: []github.PullRequestFile{
+				{Name: ".github/workflows/ci.yaml", Status: github.StatusModified},
+			},
+			errorMessage: "external contributors can't change .github/workflows/ci.yaml",
+		},
+		{
+			desc:   "forbidden-path-internal",
+			author: "member",
+			files: []github.PullRequestFile{
+				{Name: ".github/workflows/ci.yaml", Status: github.StatusModified},
+			},
+		},
+		{
+			desc:   "missing-generated-files",
+			author: "member",
+			files: []github.PullRequestFile{
+				{Name: "api/proto/teleport/a.proto", Status: github.StatusModified, Additions: 1},
+			},
+			errorMessage: "api/proto/teleport/a.proto changed, but no file matching api/gen/ changed with it",
+		},
+		{
+			desc:   "file-too-large",
+			author: "member",
+			files: []github.PullRequestFile{
+				{Name: "web/a.png", Status: github.StatusAdded},
+				{Name: "web/b.png", Status: github.StatusModified},
+				{Name: "web/c.png", Status: github.StatusRemoved},
+			},
+			contents: map[string][]byte{
+				"head:web/a.png": []byte("a large image"),
+				"head:web/b.png": []byte("a large image"),
+			},
+			errorMessage: "files must not be larger than 10 bytes: web/a.png (13 bytes), web/b.png (13 bytes)",
+		},
+		{
+			desc:   "missing-lockfile",
+			author: "member",
+			files: []github.PullRequestFile{
+				{Name: "go.mod", Status: github.StatusModified, Additions: 1},
+				{Name: "api/go.mod", Status: github.StatusModified, Additions: 1},
+				{Name: "go.sum", Status: github.StatusModified, Additions: 1},
+			},
+			errorMessage: "api/go.mod changed without api/go.sum",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			gh := &fakeGithub{
+				files:      test.files,
+				contents:   map[string][]byte{"master:" + verifyConfigPath: config},
+				orgMembers: map[string]struct{}{"member": {}},
+				pull:       github.PullRequest{UnsafeHead: github.Branch{SHA: "head"}},
+			}
+			maps.Copy(gh.contents, test.contents)
+			b := &Bot{
+				c: &Config{
+					Environment: &env.Environment{
+						Organization: "foo",
+						Repository:   "bar",
+						Number:       1,
+						Author:       test.author,
+						UnsafeBase:   "master",
+					},
+					Review: r,
+					GitHub: gh,
+				},
+			}
+
+			err := b.Verify(context.Background(), io.Discard)
+			if test.errorMessage == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, test.errorMessage)
+		})
+	}
+}
+
+func TestParseVerifyConfig(t *testing.T) {
+	config, err := parseVerifyConfig([]byte("rules: [{type: db-migrations, paths: [db/migrations]}]"))
+	require.NoError(t, err)
+	require.Equal(t, "db-migrations", config.Rules[0].name())
+
+	for _, invalid := range []string{
+		"rules: [{type: unknown}]",
+		"rules: [{type: db-migrations}]",
+		"rules: [{type: forbidden-paths, paths: ['a[b']}]",
+		"rules: [{type: paired-files, when: [a.proto]}]",
+		"rules: [{type: max-file-size}]",
+	} {
+		_, err := parseVerifyConfig([]byte(invalid))
+		require.Error(t, err, invalid)
+	}
+
+	require.Equal(t, migrationConfig[env.CloudRepo], defaultVerifyConfig(env.CloudRepo).Rules)
+	require.Empty(t, defaultVerifyConfig("teleport").Rules)
+}
+
+func TestCheckMigrationSQL(t *testing.T) {
+	sql := `-- Adds sessions.
+CREATE TABLE sessions (
+	id TEXT PRIMARY KEY, -- the session ID; not a secret
+	note TEXT DEFAULT 'a;b'
+);
+CREATE INDEX sessions_note ON sessions (note);
+CREATE INDEX users_email ON users USING btree (email);
+CREATE UNIQUE INDEX CONCURRENTLY users_name ON users (name);
+/* verify:ignore, small table */ CREATE INDEX teams_name ON teams (name);
+CREATE FUNCTION f() RETURNS void AS $$ BEGIN DROP TABLE tmp; END; $$ LANGUAGE plpgsql;
+ALTER TABLE users
+	DROP COLUMN legacy;
+ALTER TABLE users ALTER COLUMN age TYPE BIGINT;
+ALTER TABLE users ALTER COLUMN age DROP NOT NULL;
+ALTER TABLE users ADD CONSTRAINT users_team FOREIGN KEY (team) REFERENCES teams (id);
+ALTER TABLE users ADD CONSTRAINT users_age CHECK (age > 0) NOT VALID;
+DROP TABLE legacy;
+`
+	require.Equal(t, []sqlFinding{
+		{Line: 7, Error: true, Reason: sqlChecks[0].reason},
+		{Line: 11, Reason: sqlChecks[3].reason},
+		{Line: 13, Error: true, Reason: sqlChecks[1].reason},
+		{Line: 15, Error: true, Reason: sqlChecks[2].reason},
+		{Line: 17, Reason: sqlChecks[4].reason},
+	}, checkMigrationSQL(sql))
+}
@@ -85,6 +85,10 @@ type Environment struct {
 	//
 	// https://docs.github.com/en/actions/security-guides/security-hardening-for-github-actions#understanding-the-risk-of-script-injections
 	UnsafeBase string
+
+	// Comment is the comment that triggered the workflow. It is only set for
+	// issue_comment events.
+	Comment *Comment
 }
 
 // New returns a new execution environment for the workflow.
@@ -97,7 +101,7 @@ func New() (*Environment, error) {
 	// If the event does not have a action associated with it (for example a cron
 	// run), read in organization/repository from the environment.
 	if event.Action == "" {
-		organization, repository, err := readEnvironment()
+		organization, repository, err := ReadRepository()
 		if err != nil {
 			return nil, trace.Wrap(err)
 		}
@@ -112,7 +116,7 @@ func New() (*Environment, error) {
 		return nil, trace.Wrap(err)
 	}
 
-	return &Environment{
+	environment := &Environment{
 		Organization: event.Repository.Owner.Login,
 		Repository:   event.Repository.Name,
 		Number:       event.PullRequest.Number,
@@ -122,7 +126,19 @@ func New() (*Environment, error) {
 		Deletions:    event.PullRequest.Deletions,
 		UnsafeHead:   event.PullRequest.UnsafeHead.UnsafeRef,
 		UnsafeBase:   event.PullRequest.UnsafeBase.UnsafeRef,
-	}, nil
+	}
+
+	// issue_comment events don't include the pull request, only the issue
+	// it is attached to. The branches have to be fetched separately.
+	if event.Comment.ID != 0 {
+		environment.Comment = &event.Comment
+		if event.Issue.PullRequest != nil {
+			environment.Number = event.Issue.Number
+			environment.Author = event.Issue.User.Login
+		}
+	}
+
+	return environment, nil
 }
 
 // IsCloudDeployBranch returns true when the environment's repository is cloud
@@ -155,7 +171,9 @@ func readEvent() (*Event, error) {
 	return &event, nil
 }
 
-func readEnvironment() (string, string, error) {
+// ReadRepository returns the organization and repository of the workflow run
+// from the GITHUB_REPOSITORY environment variable.
+func ReadRepository() (string, string, error) {
 	repository := os.Getenv(githubRepository)
 	if repository == "" {
 		return "", "", trace.BadParameter("%v environment variable missing", githubRepository)
@@ -34,6 +34,7 @@ func TestEnvironment(t *testing.T) {
 		author       string
 		unsafeBranch string
 		isLarge      bool
+		comment      string
 		err          bool
 	}{
 		{
@@ -64,6 +65,15 @@ func TestEnvironment(t *testing.T) {
 			unsafeBranch: "jane/ci",
 			isLarge:      true,
 		},
+		{
+			desc:         "comment-event",
+			path:         "testdata/comment.json",
+			organization: "Codertocat",
+			repository:   "Hello-World",
+			number:       2,
+			author:       "Codertocat",
+			comment:      "/backport branch/v17",
+		},
 		{
 			desc:         "schedule-event",
 			path:         "testdata/schedule.json",
@@ -100,6 +110,13 @@ func TestEnvironment(t *testing.T) {
 				require.Equal(t, environment.Number, test.number)
 				require.Equal(t, environment.Author, test.author)
 				require.Equal(t, environment.UnsafeHead, test.unsafeBranch)
+				if test.comment != "" {
+					require.NotNil(t, environment.Comment)
+					require.Equal(t, test.comment, environment.Comment.UnsafeBody)
+					require.Equal(t, "Octocat", environment.Comment.User.Login)
+				} else {
+					require.Nil(t, environment.Comment)
+				}
 			}
 		})
 	}
@@ -16,6 +16,8 @@ limitations under the License.
 
 package env
 
+import "time"
+
 // Event is a GitHub event. See the following more more details:
 // https://docs.github.com/en/developers/webhooks-and-events/webhooks/webhook-events-and-payloads
 type Event struct {
@@ -23,6 +25,11 @@ type Event struct {
 
 	Repository  Repository  `json:"repository"`
 	PullRequest PullRequest `json:"pull_request"`
+
+	// Issue and Comment are only set for issue_comment events. Comments on
+	// pull requests are delivered as comments on the underlying issue.
+	Issue   Issue   `json:"issue"`
+	Comment Comment `json:"comment"`
 }
 
 type Repository struct {
@@ -58,6 +65,31 @@ type PullRequest struct {
 	Deletions int `json:"deletions"`
 }
 
+type Issue struct {
+	Number int  `json:"number"`
+	User   User `json:"user"`
+
+	// PullRequest is only set if the issue is a pull request.
+	PullRequest *struct{} `json:"pull_request"`
+}
+
+// Comment is a comment on an issue or pull request.
+type Comment struct {
+	ID   int64 `json:"id"`
+	User User  `json:"user"`
+
+	// UnsafeBody can be attacker controlled and should not be used in any
+	// security sensitive context. For example, don't use it when crafting a URL
+	// to send a request to or an access decision. See the following link for
+	// more details:
+	//
+	// https://docs.github.com/en/actions/security-guides/security-hardening-for-github-actions#understanding-the-risk-of-script-injections
+	UnsafeBody string `json:"body"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 type User struct {
 	Login string `json:"login"`
 }
@@ -0,0 +1,66 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+
+	"github.com/gravitational/trace"
+
+	libgithub "github.com/gravitational/shared-workflows/libs/github"
+)
+
+// AppConfig configures the authentication of a GitHub App.
+type AppConfig struct {
+	// AppID is the ID of the App.
+	AppID int64
+	// InstallationID is the ID of the installation of the App. When it is 0,
+	// the installation on Organization is used.
+	InstallationID int64
+	// Organization is the organization or user the App is installed on,
+	// used to find the installation when InstallationID is 0.
+	Organization string
+	// PrivateKey is the PEM encoded private key of the App.
+	PrivateKey []byte
+}
+
+// CheckAndSetDefaults checks and sets defaults.
+func (c *AppConfig) CheckAndSetDefaults() error {
+	if c.AppID == 0 {
+		return trace.BadParameter("missing App ID")
+	}
+	if c.InstallationID == 0 && c.Organization == "" {
+		return trace.BadParameter("missing installation ID or organization")
+	}
+	if len(c.PrivateKey) == 0 {
+		return trace.BadParameter("missing private key")
+	}
+	return nil
+}
+
+// NewForApp returns a new GitHub Client that acts as a GitHub App, with the
+// installation tokens of the App. Tokens are refreshed before they expire.
+func NewForApp(ctx context.Context, config AppConfig) (*Client, error) {
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	ts, err := libgithub.NewInstallationTokenSource(ctx, config.AppID, config.InstallationID, config.Organization, config.PrivateKey)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return newClient(ctx, ts), nil
+}
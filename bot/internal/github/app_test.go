@@ -0,0 +1,22 @@
+package github
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewForAppConfig checks that invalid App configs are rejected before
+// any request is made.
+func TestNewForAppConfig(t *testing.T) {
+	for _, config := range []AppConfig{
+		{Organization: "gravitational", PrivateKey: []byte("key")},
+		{AppID: 7, PrivateKey: []byte("key")},
+		{AppID: 7, Organization: "gravitational"},
+		{AppID: 7, Organization: "gravitational", PrivateKey: []byte("not a key")},
+	} {
+		_, err := NewForApp(context.Background(), config)
+		require.Error(t, err)
+	}
+}
@@ -19,6 +19,8 @@ package github
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"net/url"
@@ -46,17 +48,37 @@ const (
 
 type Client struct {
 	client *go_github.Client
+	// tokens returns the token that requests are authenticated with.
+	tokens oauth2.TokenSource
 }
 
 // New returns a new GitHub Client.
 func New(ctx context.Context, token string) (*Client, error) {
-	clt := oauth2.NewClient(ctx, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}))
+	return newClient(ctx, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})), nil
+}
+
+// newClient returns a new GitHub Client that authenticates requests with the
+// tokens of ts.
+func newClient(ctx context.Context, ts oauth2.TokenSource) *Client {
+	clt := oauth2.NewClient(ctx, ts)
 
 	clt.Timeout = ClientTimeout
 
 	return &Client{
 		client: go_github.NewClient(clt),
-	}, nil
+		tokens: ts,
+	}
+}
+
+// Token returns the token that requests are currently authenticated with,
+// for example to authenticate git. Installation tokens of GitHub Apps are
+// refreshed before they expire.
+func (c *Client) Token() (string, error) {
+	token, err := c.tokens.Token()
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	return token.AccessToken, nil
 }
 
 // RequestReviewers is used to assign reviewers to a Pull Requests.
@@ -196,11 +218,32 @@ type PullRequest struct {
 	UnsafeLabels []string
 	// Fork determines if the pull request is from a fork.
 	Fork bool
+	// Merged is true if the pull request has been merged.
+	Merged bool
+	// CreatedAt is the time the pull request was opened.
+	CreatedAt time.Time
+	// Draft is true if the pull request is a draft.
+	Draft bool
+	// RequestedReviewers are the users that were requested to review and
+	// have yet to submit a review.
+	RequestedReviewers []string
 	// Commits is a list of commit SHAs for the pull request.
 	//
 	// It is only populated if the pull request was fetched using
 	// GetPullRequestWithCommits method.
 	Commits []string
+	// UnsafeCommitMessages are the messages of Commits, in the same order.
+	//
+	// UnsafeCommitMessages can be attacker controlled and should not be used
+	// in any security sensitive context. For example, don't use it when
+	// crafting a URL to send a request to or an access decision. See the
+	// following link for more details:
+	//
+	// https://docs.github.com/en/actions/security-guides/security-hardening-for-github-actions#understanding-the-risk-of-script-injections
+	//
+	// It is only populated if the pull request was fetched using
+	// GetPullRequestWithCommits method.
+	UnsafeCommitMessages []string
 }
 
 // Branch is a git Branch.
@@ -242,6 +285,61 @@ func (c *Client) ListReviewers(ctx context.Context, organization string, reposit
 	return reviewers, nil
 }
 
+// TimelineEvent is an event of the timeline of an Issue or Pull Request.
+type TimelineEvent struct {
+	// Event is the type of the event, for example "review_requested".
+	Event string
+	// CreatedAt is when the event happened.
+	CreatedAt time.Time
+	// RequestedReviewer is the user requested to review by a
+	// "review_requested" event. It is empty for team review requests.
+	RequestedReviewer string
+}
+
+// ListTimeline returns the events of the timeline of an Issue or Pull
+// Request.
+//
+// https://docs.github.com/en/rest/issues/timeline#list-timeline-events-for-an-issue
+func (c *Client) ListTimeline(ctx context.Context, organization string, repository string, number int) ([]TimelineEvent, error) {
+	var events []TimelineEvent
+
+	// The timeline of go-github v37 doesn't have the requested reviewer.
+	page := 1
+	for {
+		u := fmt.Sprintf("repos/%v/%v/issues/%v/timeline?per_page=%v&page=%v", organization, repository, number, perPage, page)
+		req, err := c.client.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		var timeline []struct {
+			Event             string    `json:"event"`
+			CreatedAt         time.Time `json:"created_at"`
+			RequestedReviewer struct {
+				Login string `json:"login"`
+			} `json:"requested_reviewer"`
+		}
+		resp, err := c.client.Do(ctx, req, &timeline)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+
+		for _, e := range timeline {
+			events = append(events, TimelineEvent{
+				Event:             e.Event,
+				CreatedAt:         e.CreatedAt,
+				RequestedReviewer: e.RequestedReviewer.Login,
+			})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		page = resp.NextPage
+	}
+
+	return events, nil
+}
+
 // FileStatus indicates the operation that led to the current state of the file,
 // based on the value reported by the GitHub API.
 type FileStatus string
@@ -330,6 +428,7 @@ func (c *Client) GetPullRequestWithCommits(ctx context.Context, organization str
 	for _, commit := range commits {
 		if len(commit.Parents) <= 1 { // Skip merge commits.
 			pull.Commits = append(pull.Commits, *commit.SHA)
+			pull.UnsafeCommitMessages = append(pull.UnsafeCommitMessages, commit.GetCommit().GetMessage())
 		}
 	}
 
@@ -368,6 +467,11 @@ func (c *Client) GetPullRequest(ctx context.Context, organization string, reposi
 		UnsafeBody:   pull.GetBody(),
 		UnsafeLabels: labels,
 		Fork:         pull.GetHead().GetRepo().GetFork(),
+		Merged:       pull.GetMerged(),
+		CreatedAt:    pull.GetCreatedAt(),
+		Draft:        pull.GetDraft(),
+
+		RequestedReviewers: requestedReviewers(pull),
 	}, nil
 }
 
@@ -392,29 +496,47 @@ func (c *Client) ListPullRequests(ctx context.Context, organization string, repo
 		}
 
 		for _, pull := range page {
-			var labels []string
-			for _, label := range pull.Labels {
-				labels = append(labels, label.GetName())
-			}
+			pulls = append(pulls, listedPullRequest(repository, pull))
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
 
-			pulls = append(pulls, PullRequest{
-				Author:     pull.GetUser().GetLogin(),
-				Repository: repository,
-				Number:     pull.GetNumber(),
-				State:      pull.GetState(),
-				UnsafeBase: Branch{
-					Ref: pull.GetBase().GetRef(),
-					SHA: pull.GetBase().GetSHA(),
-				},
-				UnsafeHead: Branch{
-					Ref: pull.GetHead().GetRef(),
-					SHA: pull.GetHead().GetSHA(),
-				},
-				UnsafeTitle:  pull.GetTitle(),
-				UnsafeBody:   pull.GetBody(),
-				UnsafeLabels: labels,
-				Fork:         pull.GetHead().GetRepo().GetFork(),
-			})
+	return pulls, nil
+}
+
+// ListPullRequestsCreatedSince returns the Pull Requests in any state that
+// were created at or after since, newest first. Pages are listed by creation
+// time, so listing stops at the first older Pull Request instead of reading
+// the whole history of the repository.
+func (c *Client) ListPullRequestsCreatedSince(ctx context.Context, organization string, repository string, since time.Time) ([]PullRequest, error) {
+	var pulls []PullRequest
+
+	opts := &go_github.PullRequestListOptions{
+		State:     "all",
+		Sort:      "created",
+		Direction: "desc",
+		ListOptions: go_github.ListOptions{
+			Page:    0,
+			PerPage: perPage,
+		},
+	}
+	for {
+		page, resp, err := c.client.PullRequests.List(ctx,
+			organization,
+			repository,
+			opts)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+
+		for _, pull := range page {
+			if pull.GetCreatedAt().Before(since) {
+				return pulls, nil
+			}
+			pulls = append(pulls, listedPullRequest(repository, pull))
 		}
 		if resp.NextPage == 0 {
 			break
@@ -425,6 +547,47 @@ func (c *Client) ListPullRequests(ctx context.Context, organization string, repo
 	return pulls, nil
 }
 
+// listedPullRequest converts a Pull Request returned by the list API.
+func listedPullRequest(repository string, pull *go_github.PullRequest) PullRequest {
+	var labels []string
+	for _, label := range pull.Labels {
+		labels = append(labels, label.GetName())
+	}
+
+	return PullRequest{
+		Author:     pull.GetUser().GetLogin(),
+		Repository: repository,
+		Number:     pull.GetNumber(),
+		State:      pull.GetState(),
+		UnsafeBase: Branch{
+			Ref: pull.GetBase().GetRef(),
+			SHA: pull.GetBase().GetSHA(),
+		},
+		UnsafeHead: Branch{
+			Ref: pull.GetHead().GetRef(),
+			SHA: pull.GetHead().GetSHA(),
+		},
+		UnsafeTitle:  pull.GetTitle(),
+		UnsafeBody:   pull.GetBody(),
+		UnsafeLabels: labels,
+		Fork:         pull.GetHead().GetRepo().GetFork(),
+		CreatedAt:    pull.GetCreatedAt(),
+		Draft:        pull.GetDraft(),
+
+		RequestedReviewers: requestedReviewers(pull),
+	}
+}
+
+// requestedReviewers returns the logins of the users requested to review a
+// pull request.
+func requestedReviewers(pull *go_github.PullRequest) []string {
+	var reviewers []string
+	for _, user := range pull.RequestedReviewers {
+		reviewers = append(reviewers, user.GetLogin())
+	}
+	return reviewers
+}
+
 // ListFiles is used to list all the files within a Pull Request.
 func (c *Client) ListFiles(ctx context.Context, organization string, repository string, number int) ([]PullRequestFile, error) {
 	var files []PullRequestFile
@@ -476,6 +639,92 @@ func (c *Client) AddLabels(ctx context.Context, organization string, repository
 	return nil
 }
 
+// RemoveLabel will remove a label from an Issue or Pull Request.
+func (c *Client) RemoveLabel(ctx context.Context, organization string, repository string, number int, label string) error {
+	_, err := c.client.Issues.RemoveLabelForIssue(ctx,
+		organization,
+		repository,
+		number,
+		label)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	return nil
+}
+
+// GetContents returns the contents of a file at a git reference.
+func (c *Client) GetContents(ctx context.Context, organization string, repository string, path string, ref string) ([]byte, error) {
+	file, _, resp, err := c.client.Repositories.GetContents(ctx,
+		organization,
+		repository,
+		path,
+		&go_github.RepositoryContentGetOptions{
+			Ref: ref,
+		})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, trace.NotFound("%v not found at %v", path, ref)
+		}
+		return nil, trace.Wrap(err)
+	}
+	if file == nil {
+		return nil, trace.BadParameter("%v is a directory", path)
+	}
+
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return []byte(content), nil
+}
+
+// GetFileSize returns the size in bytes of a file at a git reference. Unlike
+// GetContents, it works for files larger than 1 MB.
+func (c *Client) GetFileSize(ctx context.Context, organization string, repository string, path string, ref string) (int, error) {
+	file, _, resp, err := c.client.Repositories.GetContents(ctx,
+		organization,
+		repository,
+		path,
+		&go_github.RepositoryContentGetOptions{
+			Ref: ref,
+		})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return 0, trace.NotFound("%v not found at %v", path, ref)
+		}
+		return 0, trace.Wrap(err)
+	}
+	if file == nil {
+		return 0, trace.BadParameter("%v is a directory", path)
+	}
+	return file.GetSize(), nil
+}
+
+// ListDirectory returns the names of the entries of a directory at a git
+// reference.
+func (c *Client) ListDirectory(ctx context.Context, organization string, repository string, path string, ref string) ([]string, error) {
+	_, entries, resp, err := c.client.Repositories.GetContents(ctx,
+		organization,
+		repository,
+		path,
+		&go_github.RepositoryContentGetOptions{
+			Ref: ref,
+		})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, trace.NotFound("%v not found at %v", path, ref)
+		}
+		return nil, trace.Wrap(err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.GetName())
+	}
+	return names, nil
+}
+
 // Workflow contains information about a workflow.
 type Workflow struct {
 	// ID of the workflow.
@@ -532,6 +781,18 @@ type Run struct {
 	CreatedAt time.Time
 }
 
+// RerunWorkflowRun re-runs a workflow run.
+func (c *Client) RerunWorkflowRun(ctx context.Context, organization string, repository string, runID int64) error {
+	_, err := c.client.Actions.RerunWorkflowByID(ctx,
+		organization,
+		repository,
+		runID)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
 // ListWorkflowRuns is used to list all workflow runs for an ID.
 func (c *Client) ListWorkflowRuns(ctx context.Context, organization string, repository string, branch string, workflowID int64) ([]Run, error) {
 	var runs []Run
@@ -573,6 +834,118 @@ func (c *Client) ListWorkflowRuns(ctx context.Context, organization string, repo
 	return runs, nil
 }
 
+// GetLatestWorkflowRun returns the most recent run of a workflow on a branch
+// with the given status, for example "success".
+func (c *Client) GetLatestWorkflowRun(ctx context.Context, organization string, repository string, branch string, workflowID int64, status string) (Run, error) {
+	page, _, err := c.client.Actions.ListWorkflowRunsByID(ctx,
+		organization,
+		repository,
+		workflowID,
+		&go_github.ListWorkflowRunsOptions{
+			Branch: branch,
+			Status: status,
+			ListOptions: go_github.ListOptions{
+				PerPage: 1,
+			},
+		})
+	if err != nil {
+		return Run{}, trace.Wrap(err)
+	}
+	if len(page.WorkflowRuns) == 0 {
+		return Run{}, trace.NotFound("no %v runs of workflow %v on %v", status, workflowID, branch)
+	}
+
+	run := page.WorkflowRuns[0]
+	return Run{
+		ID:        run.GetID(),
+		CreatedAt: run.GetCreatedAt().Time,
+	}, nil
+}
+
+// Artifact is a file archive uploaded by a workflow run.
+type Artifact struct {
+	// ID of the artifact.
+	ID int64
+	// Name of the artifact.
+	Name string
+	// Expired is true if the artifact can no longer be downloaded.
+	Expired bool
+}
+
+// ListWorkflowRunArtifacts lists the artifacts uploaded by a workflow run.
+func (c *Client) ListWorkflowRunArtifacts(ctx context.Context, organization string, repository string, runID int64) ([]Artifact, error) {
+	var artifacts []Artifact
+
+	opts := &go_github.ListOptions{
+		Page:    0,
+		PerPage: perPage,
+	}
+	for {
+		page, resp, err := c.client.Actions.ListWorkflowRunArtifacts(ctx,
+			organization,
+			repository,
+			runID,
+			opts)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+
+		for _, artifact := range page.Artifacts {
+			artifacts = append(artifacts, Artifact{
+				ID:      artifact.GetID(),
+				Name:    artifact.GetName(),
+				Expired: artifact.GetExpired(),
+			})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return artifacts, nil
+}
+
+// maxArtifactSize is the largest artifact archive that is downloaded.
+const maxArtifactSize = 100 << 20
+
+// DownloadArtifact returns the zip archive of an artifact.
+func (c *Client) DownloadArtifact(ctx context.Context, organization string, repository string, artifactID int64) ([]byte, error) {
+	u, _, err := c.client.Actions.DownloadArtifact(ctx,
+		organization,
+		repository,
+		artifactID,
+		true)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	// The archive is served from a pre-signed URL on another host, the
+	// GitHub token must not be sent along.
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, trace.BadParameter("downloading artifact %v failed: %v", artifactID, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxArtifactSize+1))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if len(data) > maxArtifactSize {
+		return nil, trace.LimitExceeded("artifact %v is larger than %v bytes", artifactID, maxArtifactSize)
+	}
+	return data, nil
+}
+
 // DeleteWorkflowRun is directly implemented because it is missing from go-github.
 //
 // https://docs.github.com/en/rest/reference/actions#delete-a-workflow-run
@@ -637,9 +1010,40 @@ func (c *Client) CreateComment(ctx context.Context, organization string, reposit
 	return nil
 }
 
+// EditComment replaces the body of an existing comment on an Issue or Pull
+// Request.
+func (c *Client) EditComment(ctx context.Context, organization string, repository string, id int64, comment string) error {
+	_, _, err := c.client.Issues.EditComment(ctx,
+		organization,
+		repository,
+		id,
+		&go_github.IssueComment{
+			Body: &comment,
+		})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// CreateCommentReaction adds a reaction, for example "+1" or "eyes", to a
+// comment on an Issue or Pull Request.
+func (c *Client) CreateCommentReaction(ctx context.Context, organization string, repository string, id int64, reaction string) error {
+	_, _, err := c.client.Reactions.CreateIssueCommentReaction(ctx,
+		organization,
+		repository,
+		id,
+		reaction)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
 // Comment represents an "issue comment" on a GitHub issue or pull request.
 // This does not include comments that are part of reviews.
 type Comment struct {
+	ID     int64  // the ID of the comment, used to edit it
 	Author string // the GitHub username of the author
 	Body   string // the text of the comment
 
@@ -671,6 +1075,7 @@ func (c *Client) ListComments(ctx context.Context, organization string, reposito
 
 		for _, comment := range comments {
 			result = append(result, Comment{
+				ID:        comment.GetID(),
 				Body:      comment.GetBody(),
 				Author:    comment.GetUser().GetLogin(),
 				CreatedAt: comment.GetCreatedAt(),
@@ -754,6 +1159,15 @@ type Reference struct {
 // rare error because the GitHub API supports up wo 100K tree entries.
 var ErrTruncatedTree = errors.New("truncated tree")
 
+// GetDefaultBranch returns the name of the default branch of a repository.
+func (c *Client) GetDefaultBranch(ctx context.Context, organization string, repository string) (string, error) {
+	repo, _, err := c.client.Repositories.Get(ctx, organization, repository)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	return repo.GetDefaultBranch(), nil
+}
+
 // GetRef returns a Reference representing the provided ref name.
 func (c *Client) GetRef(ctx context.Context, organization string, repository string, ref string) (Reference, error) {
 	r, _, err := c.client.Git.GetRef(ctx, organization, repository, ref)
@@ -798,6 +1212,33 @@ func findTreeBlobEntries(tree *go_github.Tree, pathPrefix string) []string {
 	return files
 }
 
+const (
+	// CommitStatusSuccess is the state of a commit status that passed.
+	CommitStatusSuccess = "success"
+	// CommitStatusFailure is the state of a commit status that failed.
+	CommitStatusFailure = "failure"
+	// CommitStatusPending is the state of a commit status that is running.
+	CommitStatusPending = "pending"
+)
+
+// CreateStatus sets the commit status with the given context on a commit.
+// Statuses with the same context replace each other.
+func (c *Client) CreateStatus(ctx context.Context, organization string, repository string, sha string, state string, context string, description string) error {
+	_, _, err := c.client.Repositories.CreateStatus(ctx,
+		organization,
+		repository,
+		sha,
+		&go_github.RepoStatus{
+			State:       &state,
+			Context:     &context,
+			Description: &description,
+		})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
 // Job is a job within a workflow run.
 type Job struct {
 	// Name of the workflow job.
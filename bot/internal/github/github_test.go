@@ -1,10 +1,16 @@
 package github
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	go_github "github.com/google/go-github/v37/github"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFindTreeBlobEntries(t *testing.T) {
@@ -97,3 +103,90 @@ func TestFindTreeBlobEntries(t *testing.T) {
 		}
 	}
 }
+
+func TestListPullRequestsCreatedSince(t *testing.T) {
+	since := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	pages := [][]int{{5, 4}, {3, 2}, {1}}
+	var requested []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "created", r.URL.Query().Get("sort"))
+		require.Equal(t, "desc", r.URL.Query().Get("direction"))
+		require.Equal(t, "all", r.URL.Query().Get("state"))
+		page := r.URL.Query().Get("page")
+		requested = append(requested, page)
+
+		var i int
+		fmt.Sscan(page, &i)
+		i = max(i, 1)
+		if i < len(pages) {
+			w.Header().Set("Link", fmt.Sprintf(`<%v?page=%v>; rel="next"`, r.URL.Path, i+1))
+		}
+		var pulls []map[string]any
+		for _, number := range pages[i-1] {
+			pulls = append(pulls, map[string]any{
+				"number":     number,
+				"created_at": since.AddDate(0, 0, number-3),
+			})
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(pulls))
+	}))
+	t.Cleanup(srv.Close)
+
+	c := &Client{client: go_github.NewClient(srv.Client())}
+	baseURL, err := c.client.BaseURL.Parse(srv.URL + "/")
+	require.NoError(t, err)
+	c.client.BaseURL = baseURL
+
+	pulls, err := c.ListPullRequestsCreatedSince(context.Background(), "gravitational", "teleport", since)
+	require.NoError(t, err)
+	var numbers []int
+	for _, pull := range pulls {
+		numbers = append(numbers, pull.Number)
+	}
+	require.Equal(t, []int{5, 4, 3}, numbers)
+	// The last page is never read.
+	require.Equal(t, []string{"", "2"}, requested)
+}
+
+func TestListTimeline(t *testing.T) {
+	requested := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/repos/gravitational/teleport/issues/1/timeline", r.URL.Path)
+		fmt.Fprintf(w, `[
+			{"event": "ready_for_review", "created_at": %[1]q},
+			{"event": "review_requested", "created_at": %[1]q, "requested_reviewer": {"login": "alice"}},
+			{"event": "review_requested", "created_at": %[1]q, "requested_team": {"name": "core"}}
+		]`, requested.Format(time.RFC3339))
+	}))
+	t.Cleanup(srv.Close)
+
+	c := &Client{client: go_github.NewClient(srv.Client())}
+	baseURL, err := c.client.BaseURL.Parse(srv.URL + "/")
+	require.NoError(t, err)
+	c.client.BaseURL = baseURL
+
+	events, err := c.ListTimeline(context.Background(), "gravitational", "teleport", 1)
+	require.NoError(t, err)
+	require.Equal(t, []TimelineEvent{
+		{Event: "ready_for_review", CreatedAt: requested},
+		{Event: "review_requested", CreatedAt: requested, RequestedReviewer: "alice"},
+		{Event: "review_requested", CreatedAt: requested},
+	}, events)
+}
+
+func TestGetDefaultBranch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/repos/gravitational/teleport", r.URL.Path)
+		fmt.Fprint(w, `{"name": "teleport", "default_branch": "main"}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	c := &Client{client: go_github.NewClient(srv.Client())}
+	baseURL, err := c.client.BaseURL.Parse(srv.URL + "/")
+	require.NoError(t, err)
+	c.client.BaseURL = baseURL
+
+	branch, err := c.GetDefaultBranch(context.Background(), "gravitational", "teleport")
+	require.NoError(t, err)
+	require.Equal(t, "main", branch)
+}
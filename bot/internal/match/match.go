@@ -0,0 +1,145 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package match implements the patterns used by the configs of the bot to
+// match authors, branches and files.
+package match
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/gravitational/trace"
+)
+
+// Glob returns true if s is pattern or matches it as a glob. Bot logins
+// such as "dependabot[bot]" contain brackets, so an exact match is checked
+// first.
+func Glob(pattern, s string) bool {
+	if pattern == s {
+		return true
+	}
+	ok, err := filepath.Match(pattern, s)
+	return err == nil && ok
+}
+
+// CheckGlobs returns an error for the first malformed glob of patterns.
+func CheckGlobs(patterns ...string) error {
+	for _, pattern := range patterns {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return trace.BadParameter("invalid glob %q: %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+// compiledPaths caches the path patterns compiled by Path.
+var compiledPaths sync.Map
+
+// Path returns true if name matches the gitignore-style path pattern, see
+// CompilePath. Invalid patterns match nothing, configs are expected to be
+// validated with CheckPaths.
+func Path(pattern, name string) bool {
+	re, ok := compiledPaths.Load(pattern)
+	if !ok {
+		compiled, err := CompilePath(pattern)
+		if err != nil {
+			return false
+		}
+		re, _ = compiledPaths.LoadOrStore(pattern, compiled)
+	}
+	return re.(*regexp.Regexp).MatchString(name)
+}
+
+// CheckPaths returns an error for the first malformed path pattern of
+// patterns.
+func CheckPaths(patterns ...string) error {
+	for _, pattern := range patterns {
+		if _, err := CompilePath(pattern); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// CompilePath converts a gitignore-style path pattern to a regular
+// expression, as used by CODEOWNERS and .gitattributes files.
+//
+// Patterns that contain a slash are anchored to the root of the repository,
+// patterns without one match at any depth, a trailing slash only matches
+// directories, "*" does not cross directory boundaries and "**" does. A
+// pattern that matches a directory matches everything inside of it, except
+// for patterns ending with "/*" which only match files directly in the
+// directory.
+//
+// https://git-scm.com/docs/gitignore#_pattern_format
+func CompilePath(pattern string) (*regexp.Regexp, error) {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	p := strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+	if p == "" {
+		return nil, trace.BadParameter("empty pattern")
+	}
+
+	var sb strings.Builder
+	sb.WriteString("^")
+	if !anchored {
+		sb.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case p[i] == '*':
+			sb.WriteString("[^/]*")
+		case p[i] == '?':
+			sb.WriteString("[^/]")
+		case p[i] == '[':
+			end := strings.IndexByte(p[i+1:], ']')
+			if end < 0 {
+				return nil, trace.BadParameter("invalid pattern %q: unclosed character class", pattern)
+			}
+			class := p[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(p[i])))
+		}
+	}
+	switch {
+	case dirOnly:
+		sb.WriteString("/.*")
+	case !strings.HasSuffix(p, "/*"):
+		sb.WriteString("(?:/.*)?")
+	}
+	sb.WriteString("$")
+
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return nil, trace.BadParameter("invalid pattern %q: %v", pattern, err)
+	}
+	return re, nil
+}
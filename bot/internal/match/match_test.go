@@ -0,0 +1,136 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package match
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		s       string
+		match   bool
+	}{
+		{pattern: "dependabot[bot]", s: "dependabot[bot]", match: true},
+		{pattern: "dependabot[bot]", s: "dependabotb", match: true},
+		{pattern: "renovate*", s: "renovate-bot", match: true},
+		{pattern: "branch/v*", s: "branch/v17", match: true},
+		{pattern: "branch/v*", s: "master", match: false},
+		{pattern: "a[b", s: "ab", match: false},
+	}
+	for _, test := range tests {
+		t.Run(test.pattern+" "+test.s, func(t *testing.T) {
+			require.Equal(t, test.match, Glob(test.pattern, test.s))
+		})
+	}
+}
+
+func TestCheckGlobs(t *testing.T) {
+	require.NoError(t, CheckGlobs())
+	require.NoError(t, CheckGlobs("dependabot[bot]", "branch/v*"))
+	require.ErrorContains(t, CheckGlobs("ok", "a[b"), `invalid glob "a[b"`)
+}
+
+func TestCompilePath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		match   []string
+		noMatch []string
+	}{
+		{
+			pattern: "*.gen.go",
+			match:   []string{"a.gen.go", "lib/a/b.gen.go"},
+			noMatch: []string{"a.go", "gen.go/a.go"},
+		},
+		{
+			pattern: "/api/gen/*.go",
+			match:   []string{"api/gen/a.go"},
+			noMatch: []string{"api/gen/b/a.go", "lib/api/gen/a.go"},
+		},
+		{
+			pattern: "api/gen/**",
+			match:   []string{"api/gen/a.go", "api/gen/b/a.go"},
+			noMatch: []string{"api/a.go"},
+		},
+		{
+			pattern: "**/zz_generated.*.go",
+			match:   []string{"zz_generated.deepcopy.go", "a/b/zz_generated.deepcopy.go"},
+			noMatch: []string{"a/zz_generated.go"},
+		},
+		{
+			pattern: "web/**/*_pb.ts",
+			match:   []string{"web/a_pb.ts", "web/a/b/a_pb.ts"},
+			noMatch: []string{"lib/web/a_pb.ts"},
+		},
+		{
+			pattern: "testdata/",
+			match:   []string{"testdata/a.json", "lib/testdata/a.json"},
+			noMatch: []string{"testdata.go"},
+		},
+		{
+			pattern: "lib/auth",
+			match:   []string{"lib/auth", "lib/auth/auth.go", "lib/auth/a/b.go"},
+			noMatch: []string{"lib/authz/authz.go", "api/lib/auth/auth.go"},
+		},
+		{
+			pattern: "docs/*",
+			match:   []string{"docs/a.md"},
+			noMatch: []string{"docs/a/b.md"},
+		},
+		{
+			pattern: "v[!a-z].txt",
+			match:   []string{"v1.txt"},
+			noMatch: []string{"va.txt"},
+		},
+		{
+			pattern: "v[0-9].txt",
+			match:   []string{"v1.txt"},
+			noMatch: []string{"va.txt"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.pattern, func(t *testing.T) {
+			re, err := CompilePath(test.pattern)
+			require.NoError(t, err)
+			for _, name := range test.match {
+				require.True(t, re.MatchString(name), "%v should match %v", test.pattern, name)
+			}
+			for _, name := range test.noMatch {
+				require.False(t, re.MatchString(name), "%v should not match %v", test.pattern, name)
+			}
+		})
+	}
+
+	for _, pattern := range []string{"a[b", "", "/"} {
+		_, err := CompilePath(pattern)
+		require.Error(t, err, pattern)
+	}
+}
+
+// TestPath checks matching paths against uncompiled patterns.
+func TestPath(t *testing.T) {
+	require.True(t, Path("lib/auth", "lib/auth/auth.go"))
+	require.True(t, Path("lib/auth", "lib/auth/auth.go"), "cached pattern")
+	require.False(t, Path("lib/auth", "lib/authz/authz.go"))
+	require.False(t, Path("a[b", "a[b"))
+
+	require.NoError(t, CheckPaths("lib/auth/", "*.go"))
+	require.Error(t, CheckPaths("lib/", "a[b"))
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package review
+
+import (
+	"encoding/json"
+	"log"
+	"maps"
+	"time"
+
+	"github.com/gravitational/trace"
+)
+
+// Date is a calendar date in the YYYY-MM-DD format.
+type Date struct {
+	time.Time
+}
+
+// UnmarshalJSON parses a date in the YYYY-MM-DD format.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return trace.Wrap(err)
+	}
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return trace.BadParameter("invalid date %q, expected YYYY-MM-DD", s)
+	}
+	d.Time = t
+	return nil
+}
+
+// MarshalJSON formats a date in the YYYY-MM-DD format.
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Format(time.DateOnly))
+}
+
+// Unavailability is a period of time during which a reviewer is away and
+// should not be assigned reviews. Both dates are inclusive.
+type Unavailability struct {
+	// From is the first day the reviewer is away.
+	From Date `json:"from"`
+	// To is the last day the reviewer is away.
+	To Date `json:"to"`
+}
+
+// check validates the unavailability window.
+func (u Unavailability) check() error {
+	if u.From.IsZero() || u.To.IsZero() {
+		return trace.BadParameter("unavailability must have both from and to dates")
+	}
+	if u.To.Before(u.From.Time) {
+		return trace.BadParameter("unavailability ends (%v) before it starts (%v)", u.To.Format(time.DateOnly), u.From.Format(time.DateOnly))
+	}
+	return nil
+}
+
+// contains returns true if the calendar day of now falls within the window.
+func (u Unavailability) contains(now time.Time) bool {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return !day.Before(u.From.Time) && !day.After(u.To.Time)
+}
+
+// isAway returns true if the reviewer is unavailable on the given date.
+func (r Reviewer) isAway(now time.Time) bool {
+	for _, u := range r.Unavailable {
+		if u.contains(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAway returns true if name is listed as unavailable today in any of the
+// reviewer maps.
+func (r *Assignments) isAway(name string) bool {
+	now := r.c.now()
+
+	reviewerMaps := []map[string]Reviewer{r.c.CoreReviewers, r.c.CloudReviewers, r.c.DocsReviewers}
+	for _, reviewers := range r.c.RepoReviewers {
+		reviewerMaps = append(reviewerMaps, reviewers)
+	}
+	for _, reviewers := range reviewerMaps {
+		if reviewer, ok := reviewers[name]; ok && reviewer.isAway(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// omitAway returns a copy of omit that also includes all reviewers from
+// reviewers that are away today.
+func (r *Assignments) omitAway(reviewers map[string]Reviewer, omit map[string]bool) map[string]bool {
+	m := maps.Clone(omit)
+	if m == nil {
+		m = make(map[string]bool)
+	}
+	for name := range reviewers {
+		if r.isAway(name) {
+			log.Printf("Assign: Skipping %v, reviewer is away.", name)
+			m[name] = true
+		}
+	}
+	return m
+}
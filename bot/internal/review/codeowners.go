@@ -0,0 +1,231 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package review
+
+import (
+	"bufio"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/gravitational/shared-workflows/bot/internal/github"
+	"github.com/gravitational/shared-workflows/bot/internal/match"
+	"github.com/gravitational/trace"
+)
+
+// CodeOwners is a parsed CODEOWNERS file.
+//
+// See https://docs.github.com/en/repositories/managing-your-repositorys-settings-and-features/customizing-your-repository/about-code-owners
+type CodeOwners struct {
+	rules []codeOwnersRule
+}
+
+// codeOwnersRule is a single line of a CODEOWNERS file.
+type codeOwnersRule struct {
+	// pattern is the pattern as written in the CODEOWNERS file.
+	pattern string
+	// re matches file paths covered by pattern.
+	re *regexp.Regexp
+	// owners are the GitHub logins that own matching paths. Team handles
+	// are already expanded.
+	owners []string
+}
+
+// ParseCodeOwners parses the contents of a CODEOWNERS file. Team handles
+// (@org/team) are expanded to their members using teams, which is keyed by
+// "org/team". Email owners are not supported and are ignored.
+func ParseCodeOwners(data string, teams map[string][]string) (*CodeOwners, error) {
+	var c CodeOwners
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i != -1 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		re, err := match.CompilePath(fields[0])
+		if err != nil {
+			return nil, trace.BadParameter("invalid pattern %q on line %d: %v", fields[0], n, err)
+		}
+
+		var owners []string
+		for _, owner := range fields[1:] {
+			if !strings.HasPrefix(owner, "@") {
+				log.Printf("CODEOWNERS: Ignoring owner %q on line %d, only GitHub handles are supported.", owner, n)
+				continue
+			}
+			owner = strings.TrimPrefix(owner, "@")
+			if !strings.Contains(owner, "/") {
+				owners = append(owners, owner)
+				continue
+			}
+			members, ok := teams[owner]
+			if !ok {
+				log.Printf("CODEOWNERS: Ignoring unknown team %q on line %d.", owner, n)
+				continue
+			}
+			owners = append(owners, members...)
+		}
+		slices.Sort(owners)
+
+		c.rules = append(c.rules, codeOwnersRule{
+			pattern: fields[0],
+			re:      re,
+			owners:  slices.Compact(owners),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	return &c, nil
+}
+
+// Owners returns the owners of path. The last matching rule wins, so a
+// later rule without owners leaves the path unowned.
+func (c *CodeOwners) Owners(path string) []string {
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if c.rules[i].re.MatchString(path) {
+			return c.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// WithCodeOwners returns a copy of the assignments that uses the contents of
+// a CODEOWNERS file as an additional source of preferred reviewers. With
+// code owners, an approval from a code owner is required for every owned
+// file in the PR. The file should be read from the base of the PR, so a PR
+// can't change its own code owners.
+func (r *Assignments) WithCodeOwners(data string) (*Assignments, error) {
+	c, err := ParseCodeOwners(data, r.c.Teams)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	log.Printf("Loaded %d CODEOWNERS rules.", len(c.rules))
+	assignments := *r
+	assignments.codeOwners = c
+	return &assignments, nil
+}
+
+// getPreferredCodeOwners returns a code owner from set for each changed file
+// that is not already owned by one of the reviewers in assigned.
+func (r *Assignments) getPreferredCodeOwners(set []string, files []github.PullRequestFile, assigned []string, load Load) []string {
+	if r.codeOwners == nil {
+		return nil
+	}
+
+	var preferredReviewers []string
+	for _, file := range files {
+		owners := r.codeOwners.Owners(file.Name)
+		if len(owners) == 0 {
+			continue
+		}
+		covered := slices.ContainsFunc(owners, func(owner string) bool {
+			return slices.Contains(assigned, owner) || slices.Contains(preferredReviewers, owner)
+		})
+		if covered {
+			continue
+		}
+		candidates := intersect(set, owners)
+		if len(candidates) == 0 {
+			continue
+		}
+		reviewer := r.pickReviewer(candidates, load)
+		log.Printf("Picking %v as code owner for %v.", reviewer, file.Name)
+		preferredReviewers = append(preferredReviewers, reviewer)
+	}
+	return preferredReviewers
+}
+
+// getAllCodeOwners returns all code owners from set for the changed files
+// that are not in assigned.
+func (r *Assignments) getAllCodeOwners(set []string, files []github.PullRequestFile, assigned []string) []string {
+	if r.codeOwners == nil {
+		return nil
+	}
+
+	var owners []string
+	for _, file := range files {
+		for _, owner := range intersect(set, r.codeOwners.Owners(file.Name)) {
+			if !slices.Contains(assigned, owner) && !slices.Contains(owners, owner) {
+				owners = append(owners, owner)
+			}
+		}
+	}
+	return owners
+}
+
+// codeOwnerGroup is a set of files owned by the same code owners.
+type codeOwnerGroup struct {
+	owners []string
+	files  []string
+	// authorOnly is true if the author is the only owner of the files, so
+	// owners are the admins instead.
+	authorOnly bool
+}
+
+// codeOwnerGroups groups owned files by their code owners, other than the
+// author. Files only owned by the author need an approval from an admin
+// instead. Groups are returned in the order they first appear in files.
+func (r *Assignments) codeOwnerGroups(author string, files []github.PullRequestFile) []codeOwnerGroup {
+	if r.codeOwners == nil {
+		return nil
+	}
+
+	var groups []codeOwnerGroup
+	index := make(map[string]int)
+	for _, file := range files {
+		owners := r.codeOwners.Owners(file.Name)
+		if len(owners) == 0 {
+			continue
+		}
+		owners = slices.DeleteFunc(slices.Clone(owners), func(owner string) bool {
+			return owner == author
+		})
+		key := strings.Join(owners, " ")
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			group := codeOwnerGroup{owners: owners}
+			if len(owners) == 0 {
+				group = codeOwnerGroup{owners: r.GetAdminCheckers(author), authorOnly: true}
+			}
+			groups = append(groups, group)
+		}
+		groups[i].files = append(groups[i].files, file.Name)
+	}
+	return groups
+}
+
+// intersect returns the elements of set that are also in other.
+func intersect(set, other []string) []string {
+	var out []string
+	for _, s := range set {
+		if slices.Contains(other, s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package review
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/shared-workflows/bot/internal/env"
+	"github.com/gravitational/shared-workflows/bot/internal/github"
+)
+
+const codeOwners = `
+# Default owners for everything in the repo.
+*       @global-owner
+
+# Order is important; the last matching pattern takes precedence.
+*.js    @js-owner
+/build/logs/ @doctocat
+docs/*  @docs-owner
+apps/   @gravitational/apps
+**/logs @logs-owner
+/scripts/ @doctocat @unknown-team/ops octocat@example.com
+
+# No owners, the files are unowned.
+/apps/github
+`
+
+// TestCodeOwners checks that CODEOWNERS patterns follow GitHub semantics.
+func TestCodeOwners(t *testing.T) {
+	c, err := ParseCodeOwners(codeOwners, map[string][]string{
+		"gravitational/apps": {"app-2", "app-1"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		path   string
+		owners []string
+	}{
+		{path: "main.go", owners: []string{"global-owner"}},
+		{path: "lib/web/app.js", owners: []string{"js-owner"}},
+		{path: "build/logs/out.txt", owners: []string{"logs-owner"}},
+		{path: "build/logs", owners: []string{"logs-owner"}},
+		{path: "build/logs/nested/out.txt", owners: []string{"logs-owner"}},
+		{path: "docs/getting-started.md", owners: []string{"docs-owner"}},
+		{path: "docs/build-app/troubleshooting.md", owners: []string{"global-owner"}},
+		{path: "lib/docs/readme.md", owners: []string{"global-owner"}},
+		{path: "apps/main.go", owners: []string{"app-1", "app-2"}},
+		{path: "lib/apps/main.go", owners: []string{"app-1", "app-2"}},
+		{path: "apps/github/main.go", owners: nil},
+		{path: "deeply/nested/logs/file", owners: []string{"logs-owner"}},
+		{path: "scripts/run.sh", owners: []string{"doctocat"}},
+		{path: "lib/scripts/run.sh", owners: []string{"global-owner"}},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			require.Equal(t, test.owners, c.Owners(test.path))
+		})
+	}
+}
+
+// TestCodeOwnersAssignments checks that code owners are picked as preferred
+// reviewers and are required to approve owned files.
+func TestCodeOwnersAssignments(t *testing.T) {
+	base := &Assignments{
+		c: &Config{
+			Rand: &randStatic{},
+			CoreReviewers: map[string]Reviewer{
+				"1": {Owner: true},
+				"2": {Owner: true},
+				"3": {Owner: false},
+				"4": {Owner: false},
+				"5": {Owner: false},
+			},
+			DocsReviewers: map[string]Reviewer{
+				"6": {Owner: true},
+			},
+			Teams: map[string][]string{
+				"gravitational/db": {"2", "4"},
+			},
+			Admins: []string{"7", "8"},
+		},
+	}
+	assignments, err := base.WithCodeOwners(`
+lib/srv/db/ @gravitational/db
+docs/       @3
+lib/auth/   @5
+`)
+	require.NoError(t, err)
+	require.Nil(t, base.codeOwners, "the assignments code owners were loaded into are unchanged")
+
+	e := &env.Environment{Repository: env.TeleportRepo, Author: "5"}
+
+	files := []github.PullRequestFile{{Name: "lib/srv/db/engine.go"}}
+	require.ElementsMatch(t, []string{"2", "4"}, assignments.getCodeReviewers(e, files, nil))
+
+	files = []github.PullRequestFile{{Name: "lib/service/service.go"}}
+	require.ElementsMatch(t, []string{"1", "3"}, assignments.getCodeReviewers(e, files, nil))
+
+	files = []github.PullRequestFile{{Name: "docs/pages/index.mdx"}}
+	require.ElementsMatch(t, []string{"3", "6"}, assignments.getDocsReviewers(e, files))
+
+	files = []github.PullRequestFile{
+		{Name: "lib/srv/db/engine.go"},
+		{Name: "lib/service/service.go"},
+	}
+	changes := env.Changes{Code: true, ApproverCount: env.DefaultApproverCount}
+
+	reviews := []github.Review{
+		{Author: "1", State: Approved},
+		{Author: "3", State: Approved},
+	}
+	err = assignments.checkInternalReviews(e, changes, reviews, files)
+	require.ErrorContains(t, err, "missing code owner approval: one of [2 4] for lib/srv/db/engine.go")
+
+	reviews = append(reviews, github.Review{Author: "4", State: Approved})
+	require.NoError(t, assignments.checkInternalReviews(e, changes, reviews, files))
+
+	// Files only owned by the author need an admin approval.
+	files = append(files, github.PullRequestFile{Name: "lib/auth/auth.go"})
+	err = assignments.checkInternalReviews(e, changes, reviews, files)
+	require.ErrorContains(t, err, "missing code owner approval: an admin of [7 8] for lib/auth/auth.go, which only the author owns")
+	requirements := assignments.ExplainInternal(e, reviews, changes, files)
+	last := requirements[len(requirements)-1]
+	require.Equal(t, "Admin approval for lib/auth/auth.go, which only the author owns", last.Name)
+	require.False(t, last.Met)
+	require.Equal(t, []string{"7", "8"}, last.Reviewers)
+
+	reviews = append(reviews, github.Review{Author: "7", State: Approved})
+	require.NoError(t, assignments.checkInternalReviews(e, changes, reviews, files))
+}
@@ -0,0 +1,258 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package review
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/shared-workflows/bot/internal/env"
+	"github.com/gravitational/shared-workflows/bot/internal/github"
+)
+
+// Requirement is a single review requirement of a PR, used to explain to
+// authors why a PR can or can't be merged.
+type Requirement struct {
+	// Name describes the requirement.
+	Name string
+	// Met is true if the requirement is satisfied.
+	Met bool
+	// Reviewers are the reviewers that can satisfy the requirement.
+	Reviewers []string
+	// Approvals are the approvals counted towards the requirement.
+	Approvals []string
+
+	// err is the error the check fails with while the requirement is
+	// unmet.
+	err error
+}
+
+// Verdict returns nil if all requirements are met, otherwise the error of
+// the first unmet one. The checks are the verdicts of the requirements they
+// explain, so the explanation can't disagree with the check.
+func Verdict(requirements []Requirement) error {
+	for _, r := range requirements {
+		if r.Met {
+			continue
+		}
+		if r.err != nil {
+			return trace.Wrap(r.err)
+		}
+		return trace.BadParameter("requires %v: %v", strings.ToLower(r.Name[:1])+r.Name[1:], strings.Join(r.Reviewers, ", "))
+	}
+	return nil
+}
+
+// ExplainExternal returns the review requirements for a PR opened by an
+// external contributor, see CheckExternal.
+func (r *Assignments) ExplainExternal(author string, reviews []github.Review) []Requirement {
+	reviewers := r.GetAdminCheckers(author)
+	approvals := approvedBy(reviewers, reviews)
+	return []Requirement{{
+		Name:      "Two approvals from admins",
+		Met:       len(approvals) > 1,
+		Reviewers: reviewers,
+		Approvals: approvals,
+		err:       trace.BadParameter("at least two approvals required from %v", reviewers),
+	}}
+}
+
+// ExplainInternal returns the review requirements for a PR opened by an
+// internal author, see CheckInternal.
+func (r *Assignments) ExplainInternal(e *env.Environment, reviews []github.Review, changes env.Changes, files []github.PullRequestFile) []Requirement {
+	admins := r.GetAdminCheckers(e.Author)
+	adminApprovals := approvedBy(admins, reviews)
+
+	// Skip checks if admins have approved, unless a policy forbids it.
+	if len(adminApprovals) > 0 && r.adminBypass(e, files) {
+		return []Requirement{{
+			Name:      "Admin approval",
+			Met:       true,
+			Reviewers: admins,
+			Approvals: adminApprovals,
+		}}
+	}
+
+	var requirements []Requirement
+	if changes.Code && changes.Large {
+		requirements = append(requirements, Requirement{
+			Name:      "Admin approval for large PR",
+			Met:       len(adminApprovals) > 0,
+			Reviewers: admins,
+			Approvals: adminApprovals,
+			err:       trace.BadParameter("this PR is large and requires admin approval to merge"),
+		})
+	}
+
+	if changes.Release {
+		reviewers := r.getReleaseReviewers()
+		approvals := approvedBy(reviewers, reviews)
+		err := trace.BadParameter("requires at least one approval from %v", reviewers)
+		if len(reviewers) == 0 {
+			err = trace.BadParameter("list of release reviewers is empty, check releaseReviewers field in the reviewers map")
+		}
+		return append(requirements, Requirement{
+			Name:      "Approval from a release reviewer",
+			Met:       len(approvals) > 0,
+			Reviewers: reviewers,
+			Approvals: approvals,
+			err:       err,
+		})
+	}
+
+	// Strange state, an empty commit? Check admins.
+	if !changes.Docs && !changes.Code {
+		requirements = append(requirements, Requirement{
+			Name:      "Two admin approvals for a PR without code or docs changes",
+			Met:       len(adminApprovals) > 1,
+			Reviewers: admins,
+			Approvals: adminApprovals,
+			err:       trace.BadParameter("requires two admin approvals"),
+		})
+	}
+
+	return append(requirements, r.reviewerRequirements(e, changes, reviews, files)...)
+}
+
+// reviewerRequirements returns the requirements for approvals from the code
+// and docs reviewers, the code owners and the required groups of the
+// approval policies of a PR opened by an internal author.
+func (r *Assignments) reviewerRequirements(e *env.Environment, changes env.Changes, reviews []github.Review, files []github.PullRequestFile) []Requirement {
+	setA, setB := getReviewerSets(e.Author, r.repoReviewers(e), map[string]bool{})
+
+	// If this PR touches docs, then approvals from docs reviewers also count.
+	// Add them to set B, as docs reviewers are not required so long as we get
+	// the appropriate number of approvals.
+	group2 := "code reviewers"
+	if changes.Docs {
+		setB = append(setB, r.getDocsCheckers(e, files)...)
+		group2 = "code or docs reviewers"
+	}
+	slices.Sort(setA)
+	slices.Sort(setB)
+	a := approvedBy(setA, reviews)
+	b := approvedBy(setB, reviews)
+
+	// PRs can be approved if you either have multiple code owners that approve
+	// or code owner and code reviewer. An exception is for PRs that
+	// only modify paths that require a single approver.
+	var setsErr error
+	switch {
+	case len(b) > 0 && len(a) == 0:
+		setsErr = trace.BadParameter("missing approver from g1 set: %v", setA)
+	case len(a) > 0 && len(b) == 0:
+		setsErr = trace.BadParameter("missing approver from g2 set: %v", setB)
+	default:
+		setsErr = trace.BadParameter("at least one approval required from each set %v %v", setA, setB)
+	}
+
+	var requirements []Requirement
+	if changes.ApproverCount == 1 {
+		requirements = append(requirements, Requirement{
+			Name:      "Approval from an owner or one of the " + group2,
+			Met:       len(a)+len(b) > 0,
+			Reviewers: append(slices.Clone(setA), setB...),
+			Approvals: append(slices.Clone(a), b...),
+			err:       setsErr,
+		})
+	} else {
+		requirements = append(requirements,
+			Requirement{
+				Name:      "Approval from an owner",
+				Met:       len(a) > 0,
+				Reviewers: setA,
+				Approvals: a,
+				err:       setsErr,
+			},
+			Requirement{
+				Name:      fmt.Sprintf("%d approvals from owners or %v", changes.ApproverCount, group2),
+				Met:       len(a)+len(b) >= changes.ApproverCount,
+				Reviewers: append(slices.Clone(setA), setB...),
+				Approvals: append(slices.Clone(a), b...),
+				err:       setsErr,
+			},
+		)
+	}
+
+	// Files listed in CODEOWNERS additionally require an approval from one
+	// of their owners.
+	for _, group := range r.codeOwnerGroups(e.Author, files) {
+		name := "Code owner approval for " + strings.Join(group.files, ", ")
+		err := trace.BadParameter("missing code owner approval: one of [%v] for %v", strings.Join(group.owners, " "), strings.Join(group.files, ", "))
+		if group.authorOnly {
+			name = "Admin approval for " + strings.Join(group.files, ", ") + ", which only the author owns"
+			err = trace.BadParameter("missing code owner approval: an admin of [%v] for %v, which only the author owns", strings.Join(group.owners, " "), strings.Join(group.files, ", "))
+		}
+		approvals := approvedBy(group.owners, reviews)
+		requirements = append(requirements, Requirement{
+			Name:      name,
+			Met:       len(approvals) > 0,
+			Reviewers: group.owners,
+			Approvals: approvals,
+			err:       err,
+		})
+	}
+
+	// Approval policies can require approvals from specific groups. The
+	// author never counts towards a group.
+	for _, policy := range r.matchingPolicies(e, files) {
+		for _, group := range policy.RequiredGroups {
+			reviewers := slices.DeleteFunc(slices.Clone(group.Reviewers), func(reviewer string) bool {
+				return reviewer == e.Author
+			})
+			approvals := approvedBy(reviewers, reviews)
+			requirements = append(requirements, Requirement{
+				Name:      fmt.Sprintf("%d approval(s) from the %v group (policy %q)", group.count(), group.Name, policy.Name),
+				Met:       len(approvals) >= group.count(),
+				Reviewers: reviewers,
+				Approvals: approvals,
+				err:       trace.BadParameter("policy %q requires %d approval(s) from the %v group: %v", policy.Name, group.count(), group.Name, reviewers),
+			})
+		}
+	}
+
+	return requirements
+}
+
+// ExplainAnyApprover returns a requirement for an approval from one of
+// reviewers.
+func ExplainAnyApprover(name string, reviewers []string, reviews []github.Review) Requirement {
+	approvals := approvedBy(reviewers, reviews)
+	return Requirement{
+		Name:      name,
+		Met:       len(approvals) > 0,
+		Reviewers: reviewers,
+		Approvals: approvals,
+	}
+}
+
+// approvedBy returns the reviewers that approved the PR, as counted by
+// checkN.
+func approvedBy(reviewers []string, reviews []github.Review) []string {
+	r := reviewsByAuthor(reviews)
+
+	var approvals []string
+	for _, reviewer := range reviewers {
+		if state, ok := r[reviewer]; ok && state == Approved {
+			approvals = append(approvals, reviewer)
+		}
+	}
+	return approvals
+}
@@ -0,0 +1,231 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package review
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/shared-workflows/bot/internal/env"
+)
+
+// minEligibleOwners is the number of owners a group of code reviewers needs
+// so that owners can review each other's PRs.
+const minEligibleOwners = 2
+
+// unmarshalerType is the type of json.Unmarshaler.
+var unmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+
+// UnknownKeys returns the paths of the keys of a JSON reviewers config that
+// don't match a config field, for example "coreReviewers.alice.prefered".
+// Unknown keys are ignored when the config is loaded, so a misspelled key
+// silently has no effect.
+func UnknownKeys(reviewers string) ([]string, error) {
+	if !json.Valid([]byte(reviewers)) {
+		return nil, trace.BadParameter("reviewers config is not valid JSON")
+	}
+	return unknownKeys(json.RawMessage(reviewers), reflect.TypeFor[Config](), ""), nil
+}
+
+// unknownKeys returns the paths of the keys of data, which is decoded into a
+// value of type t, that don't match a field.
+func unknownKeys(data json.RawMessage, t reflect.Type, path string) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	// Types that decode themselves, such as dates, have no fields.
+	if reflect.PointerTo(t).Implements(unmarshalerType) {
+		return nil
+	}
+
+	var unknown []string
+	switch t.Kind() {
+	case reflect.Struct:
+		// Values of the wrong type fail to load, so they aren't reported.
+		var values map[string]json.RawMessage
+		if err := json.Unmarshal(data, &values); err != nil {
+			return nil
+		}
+		// Keys match fields case-insensitively, like when the config is
+		// loaded.
+		fields := make(map[string]reflect.Type)
+		for i := range t.NumField() {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name != "" && name != "-" {
+				fields[strings.ToLower(name)] = field.Type
+			}
+		}
+		for _, key := range slices.Sorted(maps.Keys(values)) {
+			fieldType, ok := fields[strings.ToLower(key)]
+			if !ok {
+				unknown = append(unknown, joinKeyPath(path, key))
+				continue
+			}
+			unknown = append(unknown, unknownKeys(values[key], fieldType, joinKeyPath(path, key))...)
+		}
+	case reflect.Map:
+		var values map[string]json.RawMessage
+		if err := json.Unmarshal(data, &values); err != nil {
+			return nil
+		}
+		for _, key := range slices.Sorted(maps.Keys(values)) {
+			unknown = append(unknown, unknownKeys(values[key], t.Elem(), joinKeyPath(path, key))...)
+		}
+	case reflect.Slice:
+		var values []json.RawMessage
+		if err := json.Unmarshal(data, &values); err != nil {
+			return nil
+		}
+		for i, value := range values {
+			unknown = append(unknown, unknownKeys(value, t.Elem(), fmt.Sprintf("%v[%v]", path, i))...)
+		}
+	}
+	return unknown
+}
+
+// joinKeyPath appends a key to the path of a JSON value.
+func joinKeyPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// reviewerGroup is a group of reviewers of the config.
+type reviewerGroup struct {
+	// path is the path of the group in the config.
+	path string
+	// reviewers are the reviewers of the group.
+	reviewers map[string]Reviewer
+	// omit are the reviewers of the group that aren't assigned.
+	omit map[string]bool
+	// code is true if the group reviews code, so it needs owners.
+	code bool
+}
+
+// reviewerGroups returns the groups of reviewers of the config.
+func (r *Assignments) reviewerGroups() []reviewerGroup {
+	groups := []reviewerGroup{
+		{path: "coreReviewers", reviewers: r.c.CoreReviewers, omit: r.c.CodeReviewersOmit, code: true},
+		{path: "cloudReviewers", reviewers: r.c.CloudReviewers, omit: r.c.CodeReviewersOmit, code: true},
+	}
+	for _, repo := range slices.Sorted(maps.Keys(r.c.RepoReviewers)) {
+		groups = append(groups, reviewerGroup{
+			path:      "repoReviewers." + repo,
+			reviewers: r.c.RepoReviewers[repo],
+			omit:      r.c.CodeReviewersOmit,
+			code:      true,
+		})
+	}
+	return append(groups, reviewerGroup{path: "docsReviewers", reviewers: r.c.DocsReviewers, omit: r.c.DocsReviewersOmit})
+}
+
+// Lint returns the problems of the config that can be found without
+// GitHub: reviewers that are both omitted and preferred-only, and groups of
+// code reviewers with fewer than two owners that can be assigned.
+func (r *Assignments) Lint() []string {
+	var problems []string
+	for _, group := range r.reviewerGroups() {
+		owners := 0
+		for _, name := range slices.Sorted(maps.Keys(group.reviewers)) {
+			reviewer := group.reviewers[name]
+			_, omitted := group.omit[name]
+			if omitted && reviewer.PreferredOnly {
+				problems = append(problems, fmt.Sprintf("%v.%v: reviewer is both omitted and preferred-only, so preferredOnly has no effect", group.path, name))
+			}
+			if reviewer.Owner && !omitted && !reviewer.PreferredOnly {
+				owners++
+			}
+		}
+		if group.code && len(group.reviewers) > 0 && owners < minEligibleOwners {
+			problems = append(problems, fmt.Sprintf("%v: has %v owners that can be assigned, at least %v are needed so owners can review each other's PRs", group.path, owners, minEligibleOwners))
+		}
+	}
+	return problems
+}
+
+// LintPreferredPaths returns the PreferredReviewerFor paths of the code
+// reviewers of the repository of e that match none of files, the files of
+// the repository.
+func (r *Assignments) LintPreferredPaths(e *env.Environment, files []string) []string {
+	// Find the group of the repository like repoReviewers does.
+	var path string
+	switch _, ok := r.c.RepoReviewers[e.Repository]; {
+	case ok:
+		path = "repoReviewers." + e.Repository
+	case e.RepoOwnerTeam() == env.CloudTeam:
+		path = "cloudReviewers"
+	case e.RepoOwnerTeam() == env.CoreTeam:
+		path = "coreReviewers"
+	}
+	reviewers := r.repoReviewers(e)
+
+	var problems []string
+	for _, name := range slices.Sorted(maps.Keys(reviewers)) {
+		for _, preferred := range reviewers[name].PreferredReviewerFor {
+			prefix := strings.TrimPrefix(preferred, "!")
+			if prefix == "" {
+				continue
+			}
+			if !slices.ContainsFunc(files, func(file string) bool {
+				return strings.HasPrefix(file, prefix)
+			}) {
+				problems = append(problems, fmt.Sprintf("%v.%v: preferred path %q matches no file in %v", path, name, preferred, e.Repository))
+			}
+		}
+	}
+	return problems
+}
+
+// Logins returns the GitHub logins of the reviewers, admins and required
+// approvers in the config, sorted. Author patterns of approval policies
+// aren't included.
+func (r *Assignments) Logins() []string {
+	logins := make(map[string]struct{})
+	add := func(names ...string) {
+		for _, name := range names {
+			if name != "" {
+				logins[name] = struct{}{}
+			}
+		}
+	}
+	for _, group := range r.reviewerGroups() {
+		add(slices.Collect(maps.Keys(group.reviewers))...)
+	}
+	add(slices.Collect(maps.Keys(r.c.CodeReviewersOmit))...)
+	add(slices.Collect(maps.Keys(r.c.DocsReviewersOmit))...)
+	add(r.c.Admins...)
+	add(r.c.ReleaseReviewers...)
+	for _, members := range r.c.Teams {
+		add(members...)
+	}
+	for _, policies := range r.c.ApprovalPolicies {
+		for _, policy := range policies {
+			for _, group := range policy.RequiredGroups {
+				add(group.Reviewers...)
+			}
+		}
+	}
+	return slices.Sorted(maps.Keys(logins))
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package review
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/shared-workflows/bot/internal/env"
+)
+
+// lintConfig is a reviewers config with problems.
+const lintConfig = `{
+	"coreReviewers": {
+		"alice": {"owner": true, "preferredReviewerFor": ["lib/auth/", "lib/gone/", "!lib/auth/keys/"]},
+		"bob": {"Owner": true, "preferredOnly": true, "preferedReviewerFor": ["lib/web/"]},
+		"carol": {"owner": false, "preferredOnly": true},
+		"dave": {"owner": true, "unavailable": [{"from": "2026-01-01", "to": "2026-01-02", "reason": "vacation"}]}
+	},
+	"cloudReviewers": {},
+	"repoReviewers": {
+		"cloud": {
+			"erin": {"owner": true},
+			"frank": {"owner": true}
+		}
+	},
+	"codeReviewersOmit": {"carol": true, "dave": true},
+	"docsReviewers": {"gina": {"owner": true}},
+	"docsReviewersOmit": {},
+	"admins": ["admin"],
+	"releaseReviewers": ["release"],
+	"teams": {"gravitational/security": ["sec"]},
+	"approvalPolicies": {
+		"teleport": [{"authors": ["dependabot*"], "requiredGroups": [{"name": "db", "reviewers": ["dba"], "count": 1}]}]
+	},
+	"loadBalancing": true
+}`
+
+func TestUnknownKeys(t *testing.T) {
+	unknown, err := UnknownKeys(lintConfig)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"coreReviewers.bob.preferedReviewerFor",
+		"coreReviewers.dave.unavailable[0].reason",
+		"loadBalancing",
+	}, unknown)
+
+	_, err = UnknownKeys(`{"coreReviewers":`)
+	require.Error(t, err)
+}
+
+func TestLint(t *testing.T) {
+	r, err := FromString(lintConfig)
+	require.NoError(t, err)
+
+	// bob is preferred-only and dave is omitted, so alice is the only owner
+	// of the core reviewers that can be assigned.
+	require.Equal(t, []string{
+		"coreReviewers.carol: reviewer is both omitted and preferred-only, so preferredOnly has no effect",
+		"coreReviewers: has 1 owners that can be assigned, at least 2 are needed so owners can review each other's PRs",
+	}, r.Lint())
+
+	files := []string{"lib/auth/auth.go", "lib/auth/keys/keys.go", "lib/web/apiserver.go"}
+	require.Equal(t, []string{
+		`coreReviewers.alice: preferred path "lib/gone/" matches no file in teleport`,
+	}, r.LintPreferredPaths(&env.Environment{Repository: "teleport"}, files))
+	require.Empty(t, r.LintPreferredPaths(&env.Environment{Repository: "cloud"}, files))
+
+	require.Equal(t, []string{
+		"admin", "alice", "bob", "carol", "dave", "dba", "erin", "frank", "gina", "release", "sec",
+	}, r.Logins())
+}
@@ -0,0 +1,232 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package review
+
+import (
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/shared-workflows/bot/internal/env"
+	"github.com/gravitational/shared-workflows/bot/internal/github"
+	"github.com/gravitational/shared-workflows/bot/internal/match"
+)
+
+const (
+	// MatchAll applies a policy when every file in the PR matches one of
+	// its paths. This is the default.
+	MatchAll = "all"
+	// MatchAny applies a policy when at least one file in the PR matches
+	// one of its paths.
+	MatchAny = "any"
+)
+
+// ApprovalPolicy describes the approvals required for PRs that match a set
+// of paths and/or authors. A policy without paths or authors matches every
+// PR in the repository.
+type ApprovalPolicy struct {
+	// Name describes the policy in logs and error messages.
+	Name string `json:"name,omitempty"`
+	// Paths are glob patterns or path prefixes the changed files are
+	// matched against.
+	Paths []string `json:"paths,omitempty"`
+	// Match controls whether all (the default) or any of the changed files
+	// have to match Paths for the policy to apply.
+	Match string `json:"match,omitempty"`
+	// Authors are PR author logins or glob patterns. When set, the policy
+	// only applies to PRs opened by a matching author.
+	Authors []string `json:"authors,omitempty"`
+	// ApproverCount overrides the number of required approvers. When
+	// several policies set it, the last matching one wins.
+	ApproverCount int `json:"approverCount,omitempty"`
+	// RequiredGroups are groups of reviewers that must approve the PR in
+	// addition to the regular reviewers.
+	RequiredGroups []RequiredGroup `json:"requiredGroups,omitempty"`
+	// AdminBypass controls whether an admin approval satisfies all review
+	// requirements. Defaults to true.
+	AdminBypass *bool `json:"adminBypass,omitempty"`
+
+	// builtin is true for the built-in default policies, which are reviewed
+	// with the code and may lower the approver count for paths.
+	builtin bool
+}
+
+// RequiredGroup is a group of reviewers from which a number of approvals
+// is required, for example "one from security".
+type RequiredGroup struct {
+	// Name of the group, for example "security".
+	Name string `json:"name"`
+	// Reviewers are the GitHub logins of the group members.
+	Reviewers []string `json:"reviewers"`
+	// Count is the number of approvals required from the group. Defaults
+	// to 1.
+	Count int `json:"count,omitempty"`
+}
+
+// defaultApprovalPolicies are used for repositories without approval
+// policies in the reviewers config.
+var defaultApprovalPolicies = map[string][]ApprovalPolicy{
+	"cloud": {
+		{
+			Name: "fluxcd values",
+			Paths: []string{
+				"deploy/fluxcd/config/values.yaml",
+				"deploy/fluxcd/config/*/values.yaml",
+				"deploy/fluxcd/config/*/*/values.yaml",
+				"deploy/fluxcd/config/*/*/*/values.yaml",
+				"deploy/fluxcd/src/platform/*/*values.helm.yaml",
+				"deploy/fluxcd/src/platform/*/*helmrelease*.yaml",
+				"deploy/fluxcd/src/platform/*/*/*values.helm.yaml",
+				"deploy/fluxcd/src/platform/*/*/*helmrelease*.yaml",
+			},
+			ApproverCount: 1,
+			builtin:       true,
+		},
+		{
+			Name:          "dependency bots",
+			Authors:       []string{Dependabot, RenovateBotPrivate, RenovateBotPublic},
+			ApproverCount: 1,
+			builtin:       true,
+		},
+	},
+}
+
+// check validates the policy.
+func (p ApprovalPolicy) check() error {
+	switch p.Match {
+	case "", MatchAll, MatchAny:
+	default:
+		return trace.BadParameter("policy %q: unknown match %q, expected %q or %q", p.Name, p.Match, MatchAll, MatchAny)
+	}
+	if err := match.CheckGlobs(slices.Concat(p.Paths, p.Authors)...); err != nil {
+		return trace.Wrap(err, "policy %q", p.Name)
+	}
+	if p.ApproverCount < 0 {
+		return trace.BadParameter("policy %q: approverCount must not be negative", p.Name)
+	}
+	// Lowering the number of approvers is reserved for bots. Employees
+	// must never be able to skip reviews, and a policy without authors
+	// would lower it for everyone whose PR matches its paths.
+	if p.ApproverCount > 0 && p.ApproverCount < env.DefaultApproverCount && !p.builtin {
+		if len(p.Authors) == 0 {
+			return trace.BadParameter("policy %q: approverCount below %d requires authors, which must be bots", p.Name, env.DefaultApproverCount)
+		}
+		for _, author := range p.Authors {
+			if !isAllowedRobot(author) {
+				return trace.BadParameter("policy %q: %q is not allowed to be a single approver author (only bots)", p.Name, author)
+			}
+		}
+	}
+	for _, group := range p.RequiredGroups {
+		if group.Name == "" {
+			return trace.BadParameter("policy %q: required group is missing a name", p.Name)
+		}
+		if len(group.Reviewers) < group.count() {
+			return trace.BadParameter("policy %q: required group %q has fewer reviewers than required approvals", p.Name, group.Name)
+		}
+	}
+	return nil
+}
+
+// Matches returns true if the policy applies to a PR from author changing
+// files.
+func (p ApprovalPolicy) Matches(author string, files []github.PullRequestFile) bool {
+	if len(p.Authors) > 0 && !slices.ContainsFunc(p.Authors, func(pattern string) bool {
+		return match.Glob(pattern, author)
+	}) {
+		return false
+	}
+	if len(p.Paths) == 0 {
+		return true
+	}
+	if len(files) == 0 {
+		return false
+	}
+	matches := func(file github.PullRequestFile) bool {
+		return slices.ContainsFunc(p.Paths, func(pattern string) bool {
+			return matchPath(pattern, file.Name)
+		})
+	}
+	if p.Match == MatchAny {
+		return slices.ContainsFunc(files, matches)
+	}
+	for _, file := range files {
+		if !matches(file) {
+			return false
+		}
+	}
+	return true
+}
+
+// adminBypass returns true if admins can bypass the policy.
+func (p ApprovalPolicy) adminBypass() bool {
+	return p.AdminBypass == nil || *p.AdminBypass
+}
+
+func (g RequiredGroup) count() int {
+	if g.Count <= 0 {
+		return 1
+	}
+	return g.Count
+}
+
+// matchPath returns true if name matches the glob pattern or is under the
+// pattern used as a prefix.
+func matchPath(pattern, name string) bool {
+	return match.Glob(pattern, name) || strings.HasPrefix(name, pattern)
+}
+
+// ApprovalPolicies returns the approval policies for repository. Policies
+// from the reviewers config replace the built-in defaults.
+func (r *Assignments) ApprovalPolicies(repository string) []ApprovalPolicy {
+	if policies, ok := r.c.ApprovalPolicies[repository]; ok {
+		return policies
+	}
+	return defaultApprovalPolicies[repository]
+}
+
+// DefaultApprovalPolicies returns the built-in approval policies for
+// repository.
+func DefaultApprovalPolicies(repository string) []ApprovalPolicy {
+	return defaultApprovalPolicies[repository]
+}
+
+// matchingPolicies returns the policies for the repository that apply to
+// the PR.
+func (r *Assignments) matchingPolicies(e *env.Environment, files []github.PullRequestFile) []ApprovalPolicy {
+	var policies []ApprovalPolicy
+	for _, p := range r.ApprovalPolicies(e.Repository) {
+		if p.Matches(e.Author, files) {
+			policies = append(policies, p)
+		}
+	}
+	return policies
+}
+
+// adminBypass returns true if an admin approval satisfies the review
+// requirements of the PR.
+func (r *Assignments) adminBypass(e *env.Environment, files []github.PullRequestFile) bool {
+	for _, p := range r.matchingPolicies(e, files) {
+		if !p.adminBypass() {
+			log.Printf("Check: Policy %q does not allow admins to bypass reviews.", p.Name)
+			return false
+		}
+	}
+	return true
+}
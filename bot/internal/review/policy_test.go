@@ -0,0 +1,196 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package review
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/shared-workflows/bot/internal/env"
+	"github.com/gravitational/shared-workflows/bot/internal/github"
+)
+
+// TestApprovalPolicyMatches checks path and author matching of policies.
+func TestApprovalPolicyMatches(t *testing.T) {
+	files := []github.PullRequestFile{
+		{Name: "lib/auth/auth.go"},
+		{Name: "lib/web/apiserver.go"},
+	}
+
+	tests := []struct {
+		desc   string
+		policy ApprovalPolicy
+		author string
+		expect bool
+	}{
+		{
+			desc:   "empty policy matches everything",
+			expect: true,
+		},
+		{
+			desc:   "all files must match by default",
+			policy: ApprovalPolicy{Paths: []string{"lib/auth/"}},
+			expect: false,
+		},
+		{
+			desc:   "any file matches",
+			policy: ApprovalPolicy{Paths: []string{"lib/auth/"}, Match: MatchAny},
+			expect: true,
+		},
+		{
+			desc:   "all files match globs",
+			policy: ApprovalPolicy{Paths: []string{"lib/*/*.go"}},
+			expect: true,
+		},
+		{
+			desc:   "author matches exactly",
+			policy: ApprovalPolicy{Authors: []string{Dependabot}},
+			author: Dependabot,
+			expect: true,
+		},
+		{
+			desc:   "author matches glob",
+			policy: ApprovalPolicy{Authors: []string{"*-renovate*"}},
+			author: RenovateBotPublic,
+			expect: true,
+		},
+		{
+			desc:   "author and paths must both match",
+			policy: ApprovalPolicy{Authors: []string{Dependabot}, Paths: []string{"lib/"}},
+			author: "alice",
+			expect: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			require.Equal(t, test.expect, test.policy.Matches(test.author, files))
+		})
+	}
+}
+
+// TestFromStringApprovalPolicies checks that approval policies are parsed
+// and validated.
+func TestFromStringApprovalPolicies(t *testing.T) {
+	r, err := FromString(`{
+		"coreReviewers": {},
+		"cloudReviewers": {},
+		"codeReviewersOmit": {},
+		"docsReviewers": {},
+		"docsReviewersOmit": {},
+		"admins": [],
+		"approvalPolicies": {
+			"teleport": [{
+				"name": "auth",
+				"paths": ["lib/auth/"],
+				"match": "any",
+				"requiredGroups": [{"name": "security", "reviewers": ["alice", "bob"]}],
+				"adminBypass": false
+			}]
+		}
+	}`)
+	require.NoError(t, err)
+	policies := r.ApprovalPolicies(env.TeleportRepo)
+	require.Len(t, policies, 1)
+	require.Equal(t, "security", policies[0].RequiredGroups[0].Name)
+	require.False(t, policies[0].adminBypass())
+
+	// Repositories without policies use the defaults.
+	require.Equal(t, defaultApprovalPolicies[env.CloudRepo], r.ApprovalPolicies(env.CloudRepo))
+
+	_, err = FromString(`{
+		"coreReviewers": {},
+		"cloudReviewers": {},
+		"codeReviewersOmit": {},
+		"docsReviewers": {},
+		"docsReviewersOmit": {},
+		"admins": [],
+		"approvalPolicies": {
+			"teleport": [{"name": "yolo", "authors": ["alice"], "approverCount": 1}]
+		}
+	}`)
+	require.ErrorContains(t, err, "only bots")
+
+	// Policies without authors can't lower the approver count, even for
+	// paths.
+	for _, policy := range []string{
+		`{"name": "everyone", "approverCount": 1}`,
+		`{"name": "values", "paths": ["deploy/values.yaml"], "approverCount": 1}`,
+	} {
+		_, err = FromString(`{
+			"coreReviewers": {},
+			"cloudReviewers": {},
+			"codeReviewersOmit": {},
+			"docsReviewers": {},
+			"docsReviewersOmit": {},
+			"admins": [],
+			"approvalPolicies": {"teleport": [` + policy + `]}
+		}`)
+		require.ErrorContains(t, err, "requires authors, which must be bots")
+	}
+}
+
+// TestCheckApprovalPolicies checks that required groups and admin bypass
+// are enforced when checking internal reviews.
+func TestCheckApprovalPolicies(t *testing.T) {
+	noBypass := false
+	r := &Assignments{
+		c: &Config{
+			CoreReviewers: map[string]Reviewer{
+				"1": {Owner: true},
+				"2": {Owner: true},
+				"3": {Owner: false},
+			},
+			Admins: []string{"admin"},
+			ApprovalPolicies: map[string][]ApprovalPolicy{
+				env.TeleportRepo: {
+					{
+						Name:  "auth",
+						Paths: []string{"lib/auth/"},
+						Match: MatchAny,
+						RequiredGroups: []RequiredGroup{
+							{Name: "security", Reviewers: []string{"sec-1", "sec-2"}},
+						},
+						AdminBypass: &noBypass,
+					},
+				},
+			},
+		},
+	}
+	e := &env.Environment{Repository: env.TeleportRepo, Author: "3"}
+	files := []github.PullRequestFile{
+		{Name: "lib/auth/auth.go"},
+		{Name: "lib/web/apiserver.go"},
+	}
+	changes := env.Changes{Code: true, ApproverCount: env.DefaultApproverCount}
+
+	reviews := []github.Review{
+		{Author: "admin", State: Approved},
+		{Author: "1", State: Approved},
+		{Author: "2", State: Approved},
+	}
+	err := r.CheckInternal(e, reviews, changes, files)
+	require.ErrorContains(t, err, "security group")
+
+	reviews = append(reviews, github.Review{Author: "sec-2", State: Approved})
+	require.NoError(t, r.CheckInternal(e, reviews, changes, files))
+
+	// Policies that don't match the PR don't apply.
+	files = []github.PullRequestFile{{Name: "lib/web/apiserver.go"}}
+	reviews = []github.Review{{Author: "admin", State: Approved}}
+	require.NoError(t, r.CheckInternal(e, reviews, changes, files))
+}
@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"log"
 	"math/rand"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -47,40 +48,6 @@ const (
 	EmptyReviewers = "{}"
 )
 
-var (
-	// singleApproverPaths defines paths in cloud or core repos that only require a single approver.
-	// The map key is the repo (cloud|teleport|teleport.e) and the value is a list of paths.
-	singleApproverPaths = map[string][]string{
-		"cloud": {
-			"deploy/fluxcd/config/values.yaml",
-			"deploy/fluxcd/config/*/values.yaml",
-			"deploy/fluxcd/config/*/*/values.yaml",
-			"deploy/fluxcd/config/*/*/*/values.yaml",
-			"deploy/fluxcd/src/platform/*/*values.helm.yaml",
-			"deploy/fluxcd/src/platform/*/*helmrelease*.yaml",
-			"deploy/fluxcd/src/platform/*/*/*values.helm.yaml",
-			"deploy/fluxcd/src/platform/*/*/*helmrelease*.yaml",
-		},
-	}
-
-	// singleApproverAuthors defines pull request authors in cloud or core repos that only
-	// require a single approver. The map key is the repo (cloud|teleport|teleport.e) and the
-	// value is a list of authors. BOTS ONLY - DO NOT INCLUDE EMPLOYEE GITHUB HANDLES.
-	singleApproverAuthors = map[string][]string{
-		"cloud": {Dependabot, RenovateBotPrivate, RenovateBotPublic},
-	}
-)
-
-// SingleApproverPaths returns repository paths that only require a single approver.
-func SingleApproverPaths(repository string) []string {
-	return singleApproverPaths[repository]
-}
-
-// SingleApproverAuthors returns a list of authors that only require a single approver.
-func SingleApproverAuthors(repository string) []string {
-	return singleApproverAuthors[repository]
-}
-
 func isAllowedRobot(author string) bool {
 	switch author {
 	case Dependabot, RenovateBotPrivate, RenovateBotPublic, PostReleaseBot:
@@ -98,6 +65,9 @@ type Reviewer struct {
 	// PreferredReviewerFor contains a list of file paths that this reviewer
 	// should be selected to review.
 	PreferredReviewerFor []string `json:"preferredReviewerFor,omitempty"`
+	// Unavailable contains periods of time during which the reviewer is away
+	// and should not be assigned reviews. Approvals are still counted.
+	Unavailable []Unavailability `json:"unavailable,omitempty"`
 }
 
 // Rand allows to override randon number generator in tests.
@@ -111,6 +81,9 @@ type Config struct {
 	// operations.
 	Rand Rand
 
+	// Now returns the current time. Used to determine reviewer availability.
+	Now func() time.Time
+
 	// CodeReviewersOmit is a map of code reviews and code reviewers to omit.
 	CodeReviewersOmit map[string]bool `json:"codeReviewersOmit"`
 
@@ -132,6 +105,19 @@ type Config struct {
 
 	// Admins are assigned reviews when no others match.
 	Admins []string `json:"admins"`
+
+	// Teams maps GitHub team handles (org/team) used in CODEOWNERS files to
+	// the logins of the team members.
+	Teams map[string][]string `json:"teams,omitempty"`
+
+	// LoadBalance enables load-aware assignment. When set, reviewers with the
+	// fewest outstanding review requests on open PRs are preferred and
+	// randomness is only used to break ties.
+	LoadBalance bool `json:"loadBalance,omitempty"`
+
+	// ApprovalPolicies defines the approvals required for PRs by repo slug.
+	// Repositories without policies use the built-in defaults.
+	ApprovalPolicies map[string][]ApprovalPolicy `json:"approvalPolicies,omitempty"`
 }
 
 // CheckAndSetDefaults checks and sets defaults.
@@ -139,6 +125,9 @@ func (c *Config) CheckAndSetDefaults() error {
 	if c.Rand == nil {
 		c.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
 	}
+	if c.Now == nil {
+		c.Now = time.Now
+	}
 
 	if c.CoreReviewers == nil {
 		return trace.BadParameter("missing parameter CoreReviewers")
@@ -163,12 +152,58 @@ func (c *Config) CheckAndSetDefaults() error {
 		return trace.BadParameter("missing parameter Admins")
 	}
 
+	if err := c.checkUnavailability(); err != nil {
+		return trace.Wrap(err)
+	}
+
+	for repo, policies := range c.ApprovalPolicies {
+		for _, policy := range policies {
+			if err := policy.check(); err != nil {
+				return trace.Wrap(err, "approvalPolicies.%v", repo)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkUnavailability validates the unavailability windows of all reviewers.
+func (c *Config) checkUnavailability() error {
+	reviewerMaps := map[string]map[string]Reviewer{
+		"coreReviewers":  c.CoreReviewers,
+		"cloudReviewers": c.CloudReviewers,
+		"docsReviewers":  c.DocsReviewers,
+	}
+	for repo, reviewers := range c.RepoReviewers {
+		reviewerMaps["repoReviewers."+repo] = reviewers
+	}
+	for field, reviewers := range reviewerMaps {
+		for name, reviewer := range reviewers {
+			for _, u := range reviewer.Unavailable {
+				if err := u.check(); err != nil {
+					return trace.BadParameter("invalid unavailability for %v in %v: %v", name, field, err)
+				}
+			}
+		}
+	}
 	return nil
 }
 
+// now returns the current time.
+func (c *Config) now() time.Time {
+	if c.Now == nil {
+		return time.Now()
+	}
+	return c.Now()
+}
+
 // Assignments can be used to assign and check code reviewers.
 type Assignments struct {
 	c *Config
+
+	// codeOwners is an optional CODEOWNERS file used to find preferred
+	// reviewers and required approvers.
+	codeOwners *CodeOwners
 }
 
 // FromString parses JSON formatted configuration and returns assignments.
@@ -218,6 +253,31 @@ func emptyConfig() *Config {
 	return &c
 }
 
+// WithRand returns a copy of the assignments that picks reviewers with rnd.
+func (r *Assignments) WithRand(rnd Rand) *Assignments {
+	c := *r.c
+	c.Rand = rnd
+	assignments := *r
+	assignments.c = &c
+	return &assignments
+}
+
+// LoadBalance returns true if reviewers should be assigned based on their
+// outstanding review requests.
+func (r *Assignments) LoadBalance() bool {
+	return r.c.LoadBalance
+}
+
+// Load is the number of outstanding review requests for each reviewer.
+type Load map[string]int
+
+// IsOwner returns true if login is an owner (group 1 reviewer) of the
+// repository in the environment.
+func (r *Assignments) IsOwner(e *env.Environment, login string) bool {
+	reviewer, ok := r.repoReviewers(e)[login]
+	return ok && reviewer.Owner
+}
+
 // IsInternal checks whether the author of a PR is explicitly
 // listed as an internal code or docs reviewer.
 func (r *Assignments) IsInternal(author string) bool {
@@ -237,20 +297,20 @@ func (r *Assignments) IsInternal(author string) bool {
 }
 
 // Get will return a list of code reviewers for a given author.
-func (r *Assignments) Get(e *env.Environment, changes env.Changes, files []github.PullRequestFile) []string {
+//
+// If load is provided, reviewers with fewer outstanding review requests are
+// picked over more loaded ones. A nil load picks reviewers at random.
+func (r *Assignments) Get(e *env.Environment, changes env.Changes, files []github.PullRequestFile, load Load) []string {
 	var reviewers []string
 
-	// TODO: consider existing review assignments here
-	// https://github.com/gravitational/teleport/issues/10420
-
 	switch {
 	case changes.Docs && changes.Code:
 		log.Printf("Assign: Found docs and code changes.")
 		reviewers = append(reviewers, r.getDocsReviewers(e, files)...)
-		reviewers = append(reviewers, r.getCodeReviewers(e, files)...)
+		reviewers = append(reviewers, r.getCodeReviewers(e, files, load)...)
 	case !changes.Docs && changes.Code:
 		log.Printf("Assign: Found code changes.")
-		reviewers = append(reviewers, r.getCodeReviewers(e, files)...)
+		reviewers = append(reviewers, r.getCodeReviewers(e, files, load)...)
 	case changes.Docs && !changes.Code:
 		log.Printf("Assign: Found docs changes.")
 		reviewers = append(reviewers, r.getDocsReviewers(e, files)...)
@@ -281,16 +341,32 @@ func (r *Assignments) getReleaseReviewers() []string {
 	return r.c.ReleaseReviewers
 }
 
+// getDocsReviewers returns the docs reviewers to assign to a PR. Reviewers
+// that are omitted or away are skipped.
 func (r *Assignments) getDocsReviewers(e *env.Environment, files []github.PullRequestFile) []string {
+	repoReviewers := r.repoReviewers(e)
+	return r.docsReviewers(e, files,
+		r.omitAway(repoReviewers, r.c.CodeReviewersOmit),
+		r.omitAway(r.c.DocsReviewers, r.c.DocsReviewersOmit))
+}
+
+// getDocsCheckers returns the docs reviewers whose approvals count towards
+// the docs review. Unlike getDocsReviewers, reviewers that are away are
+// included.
+func (r *Assignments) getDocsCheckers(e *env.Environment, files []github.PullRequestFile) []string {
+	return r.docsReviewers(e, files, r.c.CodeReviewersOmit, r.c.DocsReviewersOmit)
+}
+
+func (r *Assignments) docsReviewers(e *env.Environment, files []github.PullRequestFile, codeOmit, docsOmit map[string]bool) []string {
 	// See if any code reviewers are designated preferred reviewers for one of
 	// the changed docs files. If so, add them as docs reviewers.
 	repoReviewers := r.repoReviewers(e)
-	a, b := getReviewerSets(e.Author, repoReviewers, r.c.CodeReviewersOmit)
+	a, b := getReviewerSets(e.Author, repoReviewers, codeOmit)
 	preferredCodeReviewers := r.getAllPreferredReviewers(repoReviewers, append(a, b...), files)
 
 	// Get the docs reviewer pool, which does not depend on the files
 	// changed by a pull request.
-	docsA, docsB := getReviewerSets(e.Author, r.c.DocsReviewers, r.c.DocsReviewersOmit)
+	docsA, docsB := getReviewerSets(e.Author, r.c.DocsReviewers, docsOmit)
 	reviewers := append(preferredCodeReviewers, append(docsA, docsB...)...)
 
 	// If no docs reviewers were assigned, assign admin reviews.
@@ -301,7 +377,7 @@ func (r *Assignments) getDocsReviewers(e *env.Environment, files []github.PullRe
 	return reviewers
 }
 
-func (r *Assignments) getCodeReviewers(e *env.Environment, files []github.PullRequestFile) []string {
+func (r *Assignments) getCodeReviewers(e *env.Environment, files []github.PullRequestFile, load Load) []string {
 	reviewers := r.repoReviewers(e)
 
 	// Obtain full sets of reviewers.
@@ -313,22 +389,22 @@ func (r *Assignments) getCodeReviewers(e *env.Environment, files []github.PullRe
 	sort.Strings(setB)
 
 	// See if there are preferred reviewers for the changeset.
-	preferredSetA := r.getPreferredReviewers(reviewers, setA, files)
-	preferredSetB := r.getPreferredReviewers(reviewers, setB, files)
+	preferredSetA := r.getPreferredReviewers(reviewers, setA, files, load)
+	preferredSetB := r.getPreferredReviewers(reviewers, setB, files, load)
 
 	// All preferred reviewers should be requested reviews. If there are none,
-	// pick from the overall set at random.
+	// pick from the overall set.
 	resultingSetA := preferredSetA
 	if len(resultingSetA) == 0 {
 		// Only include reviewers from setA whose preferredOnly field is false.
 		setA = filterPreferredOnly(reviewers, setA, false)
-		resultingSetA = append(resultingSetA, setA[r.c.Rand.Intn(len(setA))])
+		resultingSetA = append(resultingSetA, r.pickReviewer(setA, load))
 	}
 	resultingSetB := preferredSetB
 	if len(resultingSetB) == 0 {
 		// Only include reviewers from setB whose preferredOnly field is false.
 		setB = filterPreferredOnly(reviewers, setB, false)
-		resultingSetB = append(resultingSetB, setB[r.c.Rand.Intn(len(setB))])
+		resultingSetB = append(resultingSetB, r.pickReviewer(setB, load))
 	}
 
 	return append(resultingSetA, resultingSetB...)
@@ -337,7 +413,7 @@ func (r *Assignments) getCodeReviewers(e *env.Environment, files []github.PullRe
 // getPreferredReviewers returns a list of reviewers that would be preferrable
 // to review the provided changeset. Returns at most one preferred reviewer per
 // file path.
-func (r *Assignments) getPreferredReviewers(teamReviewers map[string]Reviewer, set []string, files []github.PullRequestFile) (preferredReviewers []string) {
+func (r *Assignments) getPreferredReviewers(teamReviewers map[string]Reviewer, set []string, files []github.PullRequestFile, load Load) (preferredReviewers []string) {
 	// To avoid assigning too many reviewers iterate over paths that we have
 	// preferred reviewers for and see if any of them are among the changeset.
 	coveredPaths := make(map[string]struct{})
@@ -361,7 +437,7 @@ func (r *Assignments) getPreferredReviewers(teamReviewers map[string]Reviewer, s
 					continue
 				}
 
-				reviewer := potentialReviewers[r.c.Rand.Intn(len(potentialReviewers))]
+				reviewer := r.pickReviewer(potentialReviewers, load)
 				log.Printf("Picking %v as preferred reviewer for %v which matches %v.", reviewer, file.Name, path)
 				preferredReviewers = append(preferredReviewers, reviewer)
 				for _, path := range teamReviewers[reviewer].PreferredReviewerFor {
@@ -371,7 +447,45 @@ func (r *Assignments) getPreferredReviewers(teamReviewers map[string]Reviewer, s
 			}
 		}
 	}
-	return preferredReviewers
+	return append(preferredReviewers, r.getPreferredCodeOwners(set, files, preferredReviewers, load)...)
+}
+
+// pickReviewer picks a single reviewer from set. If load is provided only the
+// least loaded reviewers are considered, and randomness is used to break ties.
+func (r *Assignments) pickReviewer(set []string, load Load) string {
+	candidates := set
+	if load != nil {
+		candidates = leastLoaded(set, load)
+		log.Printf("Assign: Reviewer load for %v is %v, least loaded: %v.", set, load.of(set), candidates)
+	}
+	return candidates[r.c.Rand.Intn(len(candidates))]
+}
+
+// leastLoaded returns the reviewers from set that have the fewest outstanding
+// review requests. Order of set is preserved.
+func leastLoaded(set []string, load Load) []string {
+	var least []string
+	fewest := -1
+	for _, reviewer := range set {
+		n := load[reviewer]
+		switch {
+		case fewest == -1 || n < fewest:
+			fewest = n
+			least = []string{reviewer}
+		case n == fewest:
+			least = append(least, reviewer)
+		}
+	}
+	return least
+}
+
+// of returns the load of the reviewers in set.
+func (l Load) of(set []string) map[string]int {
+	m := make(map[string]int, len(set))
+	for _, reviewer := range set {
+		m[reviewer] = l[reviewer]
+	}
+	return m
 }
 
 // getAllPreferredReviewers returns a list of reviewers that would be
@@ -402,7 +516,7 @@ func (r *Assignments) getAllPreferredReviewers(reviewers map[string]Reviewer, se
 			}
 		}
 	}
-	return preferredReviewers
+	return append(preferredReviewers, r.getAllCodeOwners(set, files, preferredReviewers)...)
 }
 
 type preferredReviewersIndex struct {
@@ -443,7 +557,7 @@ func (r *Assignments) indexPreferredReviewersMap(reviewers map[string]Reviewer,
 }
 
 // getAdminReviewers returns the list of admin reviewers. Respects code
-// reviewer omits and removes admins in omit list from reviews.
+// reviewer omits and removes admins in omit list or away from reviews.
 func (r *Assignments) getAdminReviewers(author string) []string {
 	var reviewers []string
 	for _, v := range r.c.Admins {
@@ -453,6 +567,10 @@ func (r *Assignments) getAdminReviewers(author string) []string {
 		if _, ok := r.c.CodeReviewersOmit[v]; ok {
 			continue
 		}
+		if r.isAway(v) {
+			log.Printf("Assign: Skipping admin %v, reviewer is away.", v)
+			continue
+		}
 		reviewers = append(reviewers, v)
 	}
 	return reviewers
@@ -466,108 +584,72 @@ func (r *Assignments) getCodeReviewerSets(e *env.Environment) ([]string, []strin
 		n := len(reviewers) / 2
 		return reviewers[:n], reviewers[n:]
 	}
-	return getReviewerSets(e.Author, r.repoReviewers(e), r.c.CodeReviewersOmit)
+	reviewers := r.repoReviewers(e)
+	return getReviewerSets(e.Author, reviewers, r.omitAway(reviewers, r.c.CodeReviewersOmit))
 }
 
-// CheckExternal requires two admins have approved.
-func (r *Assignments) CheckExternal(author string, reviews []github.Review) error {
-	log.Printf("Check: Found external author %q.", author)
-
-	reviewers := r.GetAdminCheckers(author)
-
-	if checkN(reviewers, reviews) > 1 {
-		return nil
-	}
-	return trace.BadParameter("at least two approvals required from %v", reviewers)
+// IsUnavailable returns true if login is omitted from code reviews or is
+// away, so they shouldn't be reminded of reviews.
+func (r *Assignments) IsUnavailable(login string) bool {
+	_, omitted := r.c.CodeReviewersOmit[login]
+	return omitted || r.isAway(login)
 }
 
-// CheckInternal will verify if required reviewers have approved. Checks if
-// docs and if each set of code reviews have approved. Admin approvals bypass
-// all checks.
-func (r *Assignments) CheckInternal(e *env.Environment, reviews []github.Review, changes env.Changes, files []github.PullRequestFile) error {
-	log.Printf("Check: Found internal author %v.", e.Author)
-
-	// Skip checks if admins have approved.
-	if check(r.GetAdminCheckers(e.Author), reviews) {
-		log.Println("Check: Detected admin approval, skipping the rest of checks.")
-		return nil
-	}
-
-	if changes.Code && changes.Large {
-		log.Println("Check: Detected large PR, requiring admin approval")
-		if !check(r.GetAdminCheckers(e.Author), reviews) {
-			return trace.BadParameter("this PR is large and requires admin approval to merge")
+// Alternate returns a reviewer to request instead of reviewer on the PR of
+// e. It is picked like Get picks code reviewers, from the same set as
+// reviewer: owners replace owners, other code reviewers replace code
+// reviewers and admins replace admins on PRs from external authors.
+// Reviewers in exclude, such as the ones already requested, aren't picked.
+func (r *Assignments) Alternate(e *env.Environment, reviewer string, exclude []string, load Load) (string, error) {
+	// getCodeReviewerSets skips omitted and away reviewers, and the author.
+	setA, setB := r.getCodeReviewerSets(e)
+	reviewers := r.repoReviewers(e)
+	var set []string
+	switch code, ok := reviewers[reviewer]; {
+	case !r.IsInternal(e.Author):
+		if !slices.Contains(r.c.Admins, reviewer) {
+			return "", trace.NotFound("%v is not an admin", reviewer)
 		}
+		set = append(setA, setB...)
+	case !ok:
+		return "", trace.NotFound("%v is not a code reviewer of %v", reviewer, e.Repository)
+	case code.Owner:
+		set = setA
+	default:
+		set = setB
 	}
 
-	if changes.Release {
-		log.Println("Check: Detected release PR.")
-		if err := r.checkInternalReleaseReviews(reviews); err != nil {
-			return trace.Wrap(err)
-		}
-		return nil
+	set = slices.DeleteFunc(filterPreferredOnly(reviewers, set, false), func(name string) bool {
+		return name == reviewer || slices.Contains(exclude, name)
+	})
+	if len(set) == 0 {
+		return "", trace.NotFound("no other reviewer can replace %v", reviewer)
 	}
+	sort.Strings(set)
+	return r.pickReviewer(set, load), nil
+}
 
-	// Strange state, an empty commit? Check admins.
-	if !changes.Docs && !changes.Code {
-		log.Printf("Check: Found no docs or code changes, requiring admin approvals")
-		if checkN(r.GetAdminCheckers(e.Author), reviews) < 2 {
-			return trace.BadParameter("requires two admin approvals")
-		}
-	}
+// CheckExternal requires two admins have approved.
+func (r *Assignments) CheckExternal(author string, reviews []github.Review) error {
+	log.Printf("Check: Found external author %q.", author)
 
-	return trace.Wrap(r.checkInternalReviews(e, changes, reviews, files))
+	return trace.Wrap(Verdict(r.ExplainExternal(author, reviews)))
 }
 
-func (r *Assignments) checkInternalReleaseReviews(reviews []github.Review) error {
-	reviewers := r.getReleaseReviewers()
-	if len(reviewers) == 0 {
-		return trace.BadParameter("list of release reviewers is empty, check releaseReviewers field in the reviewers map")
-	}
-
-	if check(reviewers, reviews) {
-		return nil
-	}
+// CheckInternal will verify if required reviewers have approved. Checks if
+// docs and if each set of code reviews have approved. Admin approvals bypass
+// all checks. The result is the verdict of the requirements of
+// ExplainInternal.
+func (r *Assignments) CheckInternal(e *env.Environment, reviews []github.Review, changes env.Changes, files []github.PullRequestFile) error {
+	log.Printf("Check: Found internal author %v.", e.Author)
 
-	return trace.BadParameter("requires at least one approval from %v", reviewers)
+	return trace.Wrap(Verdict(r.ExplainInternal(e, reviews, changes, files)))
 }
 
 // checkInternalReviews checks whether review requirements are satisfied
 // for a PR authored by an internal employee
 func (r *Assignments) checkInternalReviews(e *env.Environment, changes env.Changes, reviews []github.Review, files []github.PullRequestFile) error {
-	setA, setB := getReviewerSets(e.Author, r.repoReviewers(e), map[string]bool{})
-
-	// If this PR touches docs, then approvals from docs reviewers also count.
-	// Add them to set B, as docs reviewers are not required so long as we get
-	// the appropriate number of approvals.
-	if changes.Docs {
-		log.Printf("Check: PR contains docs changes, adding docs reviewers to group 2")
-		setB = append(setB, r.getDocsReviewers(e, files)...)
-	}
-
-	// PRs can be approved if you either have multiple code owners that approve
-	// or code owner and code reviewer. An exception is for PRs that
-	// only modify paths that require a single approver.
-	a := checkN(setA, reviews)
-	b := checkN(setB, reviews)
-	switch {
-	case changes.ApproverCount == 1 && (a >= 1 || b >= 1):
-		return nil
-	case a >= changes.ApproverCount:
-		return nil
-	case a > 0 && b > 0 && a+b >= changes.ApproverCount:
-		return nil
-	}
-
-	if b > 0 && a == 0 {
-		return trace.BadParameter("missing approver from g1 set: %v", setA)
-	}
-
-	if a > 0 && b == 0 {
-		return trace.BadParameter("missing approver from g2 set: %v", setB)
-	}
-
-	return trace.BadParameter("at least one approval required from each set %v %v", setA, setB)
+	return trace.Wrap(Verdict(r.reviewerRequirements(e, changes, reviews, files)))
 }
 
 // GetAdminCheckers returns list of admins approvers.
@@ -611,15 +693,7 @@ func check(reviewers []string, reviews []github.Review) bool {
 }
 
 func checkN(reviewers []string, reviews []github.Review) int {
-	r := reviewsByAuthor(reviews)
-
-	var n int
-	for _, reviewer := range reviewers {
-		if state, ok := r[reviewer]; ok && state == Approved {
-			n++
-		}
-	}
-	return n
+	return len(approvedBy(reviewers, reviews))
 }
 
 func reviewsByAuthor(reviews []github.Review) map[string]string {
@@ -20,7 +20,9 @@ import (
 	"slices"
 	"sort"
 	"testing"
+	"time"
 
+	"github.com/gravitational/trace"
 	"github.com/stretchr/testify/require"
 
 	"github.com/gravitational/shared-workflows/bot/internal/env"
@@ -1196,6 +1198,13 @@ func TestCheckInternal(t *testing.T) {
 			} else {
 				require.Error(t, err)
 			}
+
+			// The explanation shown to authors must agree with the check.
+			requirements := r.ExplainInternal(e, test.reviews, changes, test.files)
+			met := !slices.ContainsFunc(requirements, func(r Requirement) bool {
+				return !r.Met
+			})
+			require.Equal(t, test.result, met, "requirements: %+v", requirements)
 		})
 	}
 }
@@ -1359,25 +1368,240 @@ func TestGetCodeReviewers(t *testing.T) {
 				Repository: env.TeleportRepo,
 				Author:     test.author,
 			}
-			actual := assignments.getCodeReviewers(e, test.files)
+			actual := assignments.getCodeReviewers(e, test.files, nil)
 			sort.Strings(actual)
 			require.ElementsMatch(t, test.expected, actual)
 		})
 	}
 }
 
-func TestSingleApproverAuthors(t *testing.T) {
-	name := func(authors []string, i int) string {
-		if i == -1 {
-			return ""
-		}
-		return authors[i]
+// TestGetCodeReviewersLoad checks that the least loaded reviewers are picked
+// when reviewer load is provided.
+func TestGetCodeReviewersLoad(t *testing.T) {
+	assignments := &Assignments{
+		c: &Config{
+			Rand: &randStatic{},
+			CoreReviewers: map[string]Reviewer{
+				"1": {Owner: true, PreferredReviewerFor: []string{"lib/srv/db"}},
+				"2": {Owner: true, PreferredReviewerFor: []string{"lib/srv/db"}},
+				"3": {Owner: true},
+				"4": {Owner: false},
+				"5": {Owner: false},
+				"6": {Owner: false},
+				"7": {Owner: false},
+			},
+			Admins: []string{},
+		},
+	}
+
+	tests := []struct {
+		description string
+		files       []github.PullRequestFile
+		load        Load
+		expected    []string
+	}{
+		{
+			description: "no load picks at random",
+			files:       []github.PullRequestFile{{Name: "lib/service/service.go"}},
+			load:        nil,
+			expected:    []string{"1", "4"},
+		},
+		{
+			description: "least loaded reviewers are picked",
+			files:       []github.PullRequestFile{{Name: "lib/service/service.go"}},
+			load:        Load{"1": 3, "2": 1, "3": 2, "4": 1, "5": 0, "6": 5},
+			expected:    []string{"2", "5"},
+		},
+		{
+			description: "ties are broken at random",
+			files:       []github.PullRequestFile{{Name: "lib/service/service.go"}},
+			load:        Load{"1": 3, "2": 1, "3": 1, "4": 2, "5": 2, "6": 2, "7": 2},
+			expected:    []string{"2", "4"},
+		},
+		{
+			description: "reviewers without outstanding requests are least loaded",
+			files:       []github.PullRequestFile{{Name: "lib/service/service.go"}},
+			load:        Load{"1": 3, "2": 1, "4": 2, "5": 2},
+			expected:    []string{"3", "6"},
+		},
+		{
+			description: "least loaded preferred reviewer is picked",
+			files:       []github.PullRequestFile{{Name: "lib/srv/db/engine.go"}},
+			load:        Load{"1": 4, "2": 2, "4": 1, "5": 0, "6": 5},
+			expected:    []string{"2", "5"},
+		},
 	}
-	for repo, authors := range singleApproverAuthors {
-		i := slices.IndexFunc(authors, func(author string) bool {
-			return !isAllowedRobot(author)
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			e := &env.Environment{
+				Repository: env.TeleportRepo,
+				Author:     "7",
+			}
+			actual := assignments.getCodeReviewers(e, test.files, test.load)
+			require.ElementsMatch(t, test.expected, actual)
 		})
-		require.Equal(t, -1, i, "%q is not allowed to be a single approver author in the %q repository (only bots)", name(authors, i), repo)
+	}
+}
+
+// TestUnavailableReviewers checks that reviewers that are away are not
+// assigned reviews, but their approvals are still counted.
+func TestUnavailableReviewers(t *testing.T) {
+	date := func(s string) Date {
+		d, err := time.Parse(time.DateOnly, s)
+		require.NoError(t, err)
+		return Date{Time: d}
+	}
+	away := []Unavailability{{From: date("2026-10-01"), To: date("2026-10-10")}}
+
+	assignments, err := New(&Config{
+		Rand: &randStatic{},
+		Now: func() time.Time {
+			return time.Date(2026, time.October, 10, 18, 0, 0, 0, time.UTC)
+		},
+		CoreReviewers: map[string]Reviewer{
+			"1": {Owner: true, Unavailable: away},
+			"2": {Owner: true},
+			"3": {Owner: false, Unavailable: away},
+			"4": {Owner: false},
+			"5": {Owner: false, Unavailable: []Unavailability{{From: date("2026-09-01"), To: date("2026-10-09")}}},
+			"8": {Owner: false},
+		},
+		CloudReviewers:    map[string]Reviewer{},
+		CodeReviewersOmit: map[string]bool{},
+		DocsReviewers: map[string]Reviewer{
+			"6": {Owner: true, Unavailable: away},
+			"7": {Owner: true},
+		},
+		DocsReviewersOmit: map[string]bool{},
+		Admins:            []string{"1", "2"},
+	})
+	require.NoError(t, err)
+
+	e := &env.Environment{Repository: env.TeleportRepo, Author: "8"}
+
+	require.ElementsMatch(t, []string{"2", "4"}, assignments.getCodeReviewers(e, nil, nil))
+	require.ElementsMatch(t, []string{"7"}, assignments.getDocsReviewers(e, nil))
+	require.ElementsMatch(t, []string{"2"}, assignments.getAdminReviewers("8"))
+
+	// Approvals from reviewers that are away still count.
+	changes := env.Changes{Code: true, Docs: true, ApproverCount: env.DefaultApproverCount}
+	reviews := []github.Review{
+		{Author: "3", State: Approved},
+		{Author: "1", State: Approved},
+	}
+	require.NoError(t, assignments.checkInternalReviews(e, changes, reviews, nil))
+	reviews = []github.Review{
+		{Author: "6", State: Approved},
+		{Author: "2", State: Approved},
+	}
+	require.NoError(t, assignments.checkInternalReviews(e, changes, reviews, nil))
+}
+
+// TestFromStringUnavailable checks that unavailability windows are parsed and
+// validated.
+func TestFromStringUnavailable(t *testing.T) {
+	r, err := FromString(`{
+		"coreReviewers": {"1": {"owner": true, "unavailable": [{"from": "2026-10-01", "to": "2026-10-10"}]}},
+		"cloudReviewers": {},
+		"codeReviewersOmit": {},
+		"docsReviewers": {},
+		"docsReviewersOmit": {},
+		"admins": []
+	}`)
+	require.NoError(t, err)
+	require.Equal(t, []Unavailability{{
+		From: Date{Time: time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)},
+		To:   Date{Time: time.Date(2026, time.October, 10, 0, 0, 0, 0, time.UTC)},
+	}}, r.c.CoreReviewers["1"].Unavailable)
+
+	_, err = FromString(`{
+		"coreReviewers": {"1": {"owner": true, "unavailable": [{"from": "10/01/2026", "to": "2026-10-10"}]}},
+		"cloudReviewers": {},
+		"codeReviewersOmit": {},
+		"docsReviewers": {},
+		"docsReviewersOmit": {},
+		"admins": []
+	}`)
+	require.Error(t, err)
+
+	_, err = FromString(`{
+		"coreReviewers": {"1": {"owner": true, "unavailable": [{"from": "2026-10-10", "to": "2026-10-01"}]}},
+		"cloudReviewers": {},
+		"codeReviewersOmit": {},
+		"docsReviewers": {},
+		"docsReviewersOmit": {},
+		"admins": []
+	}`)
+	require.ErrorContains(t, err, "ends")
+}
+
+// TestAlternate checks that reviewers are replaced by reviewers from the
+// same set.
+func TestAlternate(t *testing.T) {
+	assignments, err := New(&Config{
+		Rand: &randStatic{},
+		CoreReviewers: map[string]Reviewer{
+			"1": {Owner: true},
+			"2": {Owner: true},
+			"3": {Owner: true},
+			"4": {Owner: false},
+			"5": {Owner: false, PreferredOnly: true},
+			"6": {Owner: false},
+			"7": {Owner: false},
+		},
+		CloudReviewers:    map[string]Reviewer{},
+		CodeReviewersOmit: map[string]bool{"6": true},
+		DocsReviewers:     map[string]Reviewer{},
+		DocsReviewersOmit: map[string]bool{},
+		Admins:            []string{"1", "2", "9"},
+	})
+	require.NoError(t, err)
+
+	internal := &env.Environment{Repository: env.TeleportRepo, Author: "7"}
+	external := &env.Environment{Repository: env.TeleportRepo, Author: "stranger"}
+
+	tests := []struct {
+		desc     string
+		e        *env.Environment
+		reviewer string
+		exclude  []string
+		load     Load
+		expected string
+		err      bool
+	}{
+		{desc: "owner replaces owner", e: internal, reviewer: "1", expected: "2"},
+		{desc: "excluded owners are skipped", e: internal, reviewer: "1", exclude: []string{"2"}, expected: "3"},
+		{desc: "least loaded owner", e: internal, reviewer: "1", load: Load{"2": 3}, expected: "3"},
+		{desc: "no other owner", e: internal, reviewer: "1", exclude: []string{"2", "3"}, err: true},
+		// 5 is preferred-only, 6 is omitted and 7 is the author.
+		{desc: "reviewer replaces reviewer", e: internal, reviewer: "4", err: true},
+		{desc: "omitted reviewer is replaced", e: internal, reviewer: "6", expected: "4"},
+		{desc: "unknown reviewer", e: internal, reviewer: "8", err: true},
+		{desc: "admin replaces admin", e: external, reviewer: "1", expected: "2"},
+		{desc: "reviewers don't replace admins", e: external, reviewer: "4", err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			alternate, err := assignments.Alternate(test.e, test.reviewer, test.exclude, test.load)
+			if test.err {
+				require.True(t, trace.IsNotFound(err), "expected NotFound, got %v", err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, alternate)
+		})
+	}
+
+	require.True(t, assignments.IsUnavailable("6"))
+	require.False(t, assignments.IsUnavailable("4"))
+}
+
+func TestDefaultApprovalPolicies(t *testing.T) {
+	for repo, policies := range defaultApprovalPolicies {
+		for _, policy := range policies {
+			require.NoError(t, policy.check(), "invalid default policy in the %q repository", repo)
+		}
 	}
 }
 
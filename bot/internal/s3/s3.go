@@ -0,0 +1,109 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package s3 implements reading objects from S3 and S3-compatible storage.
+package s3
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/gravitational/trace"
+)
+
+const (
+	// defaultRegion is used when no region is configured.
+	defaultRegion = "us-east-1"
+	// maxObjectSize is the largest object that is read.
+	maxObjectSize = 100 << 20
+)
+
+// Config configures a Client. Credentials, region and endpoint are read
+// from the standard AWS environment variables and config files, the fields
+// override them.
+type Config struct {
+	// Endpoint is the URL of an S3-compatible service. Buckets are
+	// addressed in the path when an endpoint is set.
+	Endpoint string
+	// Region is the region of the bucket, defaults to us-east-1 if it isn't
+	// configured either.
+	Region string
+}
+
+// Client reads objects from S3.
+type Client struct {
+	client *s3.Client
+}
+
+// New returns a new S3 client.
+func New(ctx context.Context, c *Config) (*Client, error) {
+	var opts []func(*config.LoadOptions) error
+	if c.Region != "" {
+		opts = append(opts, config.WithRegion(c.Region))
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if cfg.Region == "" {
+		cfg.Region = defaultRegion
+	}
+
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if c.Endpoint != "" {
+			o.BaseEndpoint = aws.String(c.Endpoint)
+		}
+		// S3-compatible services rarely support virtual hosted buckets.
+		o.UsePathStyle = o.BaseEndpoint != nil
+	})
+	return &Client{client: client}, nil
+}
+
+// GetObject returns the contents of an object.
+func (c *Client) GetObject(ctx context.Context, bucket string, key string) ([]byte, error) {
+	out, err := c.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var resp *awshttp.ResponseError
+		if errors.As(err, &resp) {
+			switch resp.HTTPStatusCode() {
+			case http.StatusNotFound:
+				return nil, trace.NotFound("s3://%v/%v not found", bucket, key)
+			case http.StatusForbidden:
+				return nil, trace.AccessDenied("access to s3://%v/%v denied", bucket, key)
+			}
+		}
+		return nil, trace.Wrap(err, "getting s3://%v/%v", bucket, key)
+	}
+	defer out.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(out.Body, maxObjectSize+1))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if len(data) > maxObjectSize {
+		return nil, trace.LimitExceeded("s3://%v/%v is larger than %v bytes", bucket, key, maxObjectSize)
+	}
+	return data, nil
+}
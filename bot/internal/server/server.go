@@ -0,0 +1,316 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package server runs the bot as a long-running GitHub webhook server
+// instead of a GitHub Actions workflow per event.
+package server
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v84/github"
+	"github.com/gravitational/shared-workflows/libs/github/webhook"
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/shared-workflows/bot/internal/bot"
+	"github.com/gravitational/shared-workflows/bot/internal/env"
+)
+
+// defaultTimeout limits how long the flows of an event run, like the
+// timeout of workflow runs.
+const defaultTimeout = 5 * time.Minute
+
+// Config configures the webhook server.
+type Config struct {
+	// Secret is the secret GitHub signs webhook deliveries with.
+	Secret string
+
+	// Organization and Repository are the repository the server handles
+	// events of. Backports run git in the working directory, which is a
+	// clone of this repository, so events of other repositories are
+	// dropped.
+	Organization string
+	Repository   string
+
+	// NewBot returns a bot for the environment of an event. It is called for
+	// every event, so bots don't share state between events.
+	NewBot func(e *env.Environment) (*bot.Bot, error)
+
+	// Timeout limits how long the flows of an event run, defaults to 5
+	// minutes.
+	Timeout time.Duration
+}
+
+// CheckAndSetDefaults checks and sets defaults.
+func (c *Config) CheckAndSetDefaults() error {
+	if c.Secret == "" {
+		return trace.BadParameter("missing parameter Secret")
+	}
+	if c.Organization == "" || c.Repository == "" {
+		return trace.BadParameter("missing parameter Organization or Repository")
+	}
+	if c.NewBot == nil {
+		return trace.BadParameter("missing parameter NewBot")
+	}
+	if c.Timeout == 0 {
+		c.Timeout = defaultTimeout
+	}
+	return nil
+}
+
+// Server receives GitHub webhook events and runs the bot flows they trigger
+// in-process. The flows of a PR run one event at a time, in the order the
+// events were received, while different PRs are handled concurrently.
+type Server struct {
+	c *Config
+
+	handler http.Handler
+
+	// mu protects queues.
+	mu sync.Mutex
+	// queues holds the pending jobs of the PRs that have a running worker.
+	queues map[pullKey][]job
+
+	// git serializes the flows that run git, they share the working
+	// directory and its git config.
+	git sync.Mutex
+
+	// wg tracks the running workers.
+	wg sync.WaitGroup
+}
+
+// pullKey identifies a PR.
+type pullKey struct {
+	organization string
+	repository   string
+	number       int
+}
+
+// job is the flows triggered by an event.
+type job struct {
+	// env is the environment of the event.
+	env *env.Environment
+	// flows run in order.
+	flows []flow
+}
+
+// flow is a bot workflow run for an event.
+type flow struct {
+	// name is the name of the workflow.
+	name string
+	// git is true if the flow runs git in the working directory.
+	git bool
+	// run runs the flow.
+	run func(b *bot.Bot, ctx context.Context) error
+}
+
+var (
+	assignFlow   = flow{name: "assign", run: (*bot.Bot).Assign}
+	checkFlow    = flow{name: "check", run: (*bot.Bot).Check}
+	labelFlow    = flow{name: "label", run: (*bot.Bot).Label}
+	backportFlow = flow{name: "backport", git: true, run: (*bot.Bot).Backport}
+	commandFlow  = flow{name: "command", git: true, run: (*bot.Bot).Command}
+)
+
+// New returns a new webhook server.
+func New(c Config) (*Server, error) {
+	if err := c.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	s := &Server{
+		c:      &c,
+		queues: make(map[pullKey][]job),
+	}
+	handler, err := webhook.NewHandler(s, webhook.WithSecretToken(c.Secret))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	s.handler = handler
+	return s, nil
+}
+
+// ServeHTTP validates and handles a webhook delivery.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.handler.ServeHTTP(w, r)
+}
+
+// HandleEvent queues the flows triggered by an event. It doesn't wait for
+// them as GitHub expects a response within 10 seconds.
+func (s *Server) HandleEvent(ctx context.Context, event any) error {
+	e, flows := route(event)
+	if len(flows) == 0 {
+		return nil
+	}
+	if !strings.EqualFold(e.Organization, s.c.Organization) || !strings.EqualFold(e.Repository, s.c.Repository) {
+		log.Printf("Dropping event of %v/%v, only %v/%v is handled.", e.Organization, e.Repository, s.c.Organization, s.c.Repository)
+		return nil
+	}
+	s.enqueue(job{env: e, flows: flows})
+	return nil
+}
+
+// Wait waits for the queued flows to complete.
+func (s *Server) Wait() {
+	s.wg.Wait()
+}
+
+// enqueue queues a job behind the pending jobs of its PR, starting a worker
+// for the PR if it has none.
+func (s *Server) enqueue(j job) {
+	key := pullKey{
+		organization: j.env.Organization,
+		repository:   j.env.Repository,
+		number:       j.env.Number,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if pending, ok := s.queues[key]; ok {
+		s.queues[key] = append(pending, j)
+		return
+	}
+	s.queues[key] = nil
+	s.wg.Add(1)
+	go s.work(key, j)
+}
+
+// work runs the jobs of a PR until its queue is empty.
+func (s *Server) work(key pullKey, j job) {
+	defer s.wg.Done()
+	for {
+		s.run(j)
+
+		s.mu.Lock()
+		pending := s.queues[key]
+		if len(pending) == 0 {
+			delete(s.queues, key)
+			s.mu.Unlock()
+			return
+		}
+		j, s.queues[key] = pending[0], pending[1:]
+		s.mu.Unlock()
+	}
+}
+
+// run runs the flows of a job. Failures are logged, like failed workflow
+// runs they don't stop the flows of later events.
+func (s *Server) run(j job) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.c.Timeout)
+	defer cancel()
+
+	b, err := s.c.NewBot(j.env)
+	if err != nil {
+		log.Printf("Failed to create bot for %v/%v#%v: %v.", j.env.Organization, j.env.Repository, j.env.Number, err)
+		return
+	}
+	for _, f := range j.flows {
+		log.Printf("Running %v for %v/%v#%v.", f.name, j.env.Organization, j.env.Repository, j.env.Number)
+		if err := s.runFlow(ctx, b, f); err != nil {
+			log.Printf("Workflow %v for %v/%v#%v failed: %v.", f.name, j.env.Organization, j.env.Repository, j.env.Number, err)
+		}
+	}
+}
+
+// runFlow runs a flow, holding the git lock if the flow runs git.
+func (s *Server) runFlow(ctx context.Context, b *bot.Bot, f flow) error {
+	if f.git {
+		s.git.Lock()
+		defer s.git.Unlock()
+	}
+	return trace.Wrap(f.run(b, ctx))
+}
+
+// route returns the environment of an event and the flows it triggers,
+// matching the triggers of the bot workflows in GitHub Actions.
+func route(event any) (*env.Environment, []flow) {
+	switch event := event.(type) {
+	case *github.PullRequestEvent:
+		pull := event.GetPullRequest()
+		e := pullRequestEnvironment(event.GetRepo(), pull)
+		var flows []flow
+		switch event.GetAction() {
+		case "opened", "reopened", "ready_for_review":
+			flows = []flow{assignFlow, labelFlow, checkFlow}
+		case "synchronize", "edited":
+			flows = []flow{labelFlow, checkFlow}
+		case "labeled":
+			// Backport labels can be added after the PR is merged.
+			flows = []flow{checkFlow}
+			if pull.GetMerged() {
+				flows = []flow{backportFlow}
+			}
+		case "unlabeled":
+			flows = []flow{checkFlow}
+		case "closed":
+			if pull.GetMerged() {
+				flows = []flow{backportFlow}
+			}
+		}
+		// Like in the workflows, drafts are only labeled until they are
+		// ready for review.
+		if pull.GetDraft() {
+			flows = slices.DeleteFunc(flows, func(f flow) bool {
+				return f.name != labelFlow.name
+			})
+		}
+		return e, flows
+	case *github.PullRequestReviewEvent:
+		switch event.GetAction() {
+		case "submitted", "edited", "dismissed":
+			return pullRequestEnvironment(event.GetRepo(), event.GetPullRequest()), []flow{checkFlow}
+		}
+	case *github.IssueCommentEvent:
+		// Comments on issues that aren't PRs can't run commands.
+		if event.GetAction() != "created" || !event.GetIssue().IsPullRequest() {
+			return nil, nil
+		}
+		comment := event.GetComment()
+		return &env.Environment{
+			Organization: event.GetRepo().GetOwner().GetLogin(),
+			Repository:   event.GetRepo().GetName(),
+			Number:       event.GetIssue().GetNumber(),
+			Author:       event.GetIssue().GetUser().GetLogin(),
+			Comment: &env.Comment{
+				ID:         comment.GetID(),
+				User:       env.User{Login: comment.GetUser().GetLogin()},
+				UnsafeBody: comment.GetBody(),
+				CreatedAt:  comment.GetCreatedAt().Time,
+				UpdatedAt:  comment.GetUpdatedAt().Time,
+			},
+		}, []flow{commandFlow}
+	}
+	return nil, nil
+}
+
+// pullRequestEnvironment returns the environment of a PR event.
+func pullRequestEnvironment(repo *github.Repository, pull *github.PullRequest) *env.Environment {
+	return &env.Environment{
+		Organization: repo.GetOwner().GetLogin(),
+		Repository:   repo.GetName(),
+		Number:       pull.GetNumber(),
+		Author:       pull.GetUser().GetLogin(),
+		Additions:    pull.GetAdditions(),
+		Deletions:    pull.GetDeletions(),
+		UnsafeHead:   pull.GetHead().GetRef(),
+		UnsafeBase:   pull.GetBase().GetRef(),
+	}
+}
@@ -0,0 +1,281 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v84/github"
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/shared-workflows/bot/internal/bot"
+	"github.com/gravitational/shared-workflows/bot/internal/env"
+)
+
+func TestRoute(t *testing.T) {
+	repo := &github.Repository{
+		Name:  github.Ptr("teleport"),
+		Owner: &github.User{Login: github.Ptr("gravitational")},
+	}
+	pull := func(draft, merged bool) *github.PullRequest {
+		return &github.PullRequest{
+			Number: github.Ptr(42),
+			Draft:  github.Ptr(draft),
+			Merged: github.Ptr(merged),
+			User:   &github.User{Login: github.Ptr("dev")},
+			Head:   &github.PullRequestBranch{Ref: github.Ptr("dev/fix")},
+			Base:   &github.PullRequestBranch{Ref: github.Ptr("master")},
+		}
+	}
+	created := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		desc  string
+		event any
+		flows []string
+	}{
+		{
+			desc:  "opened",
+			event: &github.PullRequestEvent{Action: github.Ptr("opened"), Repo: repo, PullRequest: pull(false, false)},
+			flows: []string{"assign", "label", "check"},
+		},
+		{
+			desc:  "opened draft",
+			event: &github.PullRequestEvent{Action: github.Ptr("opened"), Repo: repo, PullRequest: pull(true, false)},
+			flows: []string{"label"},
+		},
+		{
+			desc:  "synchronize",
+			event: &github.PullRequestEvent{Action: github.Ptr("synchronize"), Repo: repo, PullRequest: pull(false, false)},
+			flows: []string{"label", "check"},
+		},
+		{
+			desc:  "labeled",
+			event: &github.PullRequestEvent{Action: github.Ptr("labeled"), Repo: repo, PullRequest: pull(false, false)},
+			flows: []string{"check"},
+		},
+		{
+			desc:  "labeled after merge",
+			event: &github.PullRequestEvent{Action: github.Ptr("labeled"), Repo: repo, PullRequest: pull(false, true)},
+			flows: []string{"backport"},
+		},
+		{
+			desc:  "merged",
+			event: &github.PullRequestEvent{Action: github.Ptr("closed"), Repo: repo, PullRequest: pull(false, true)},
+			flows: []string{"backport"},
+		},
+		{
+			desc:  "closed",
+			event: &github.PullRequestEvent{Action: github.Ptr("closed"), Repo: repo, PullRequest: pull(false, false)},
+		},
+		{
+			desc:  "review",
+			event: &github.PullRequestReviewEvent{Action: github.Ptr("submitted"), Repo: repo, PullRequest: pull(false, false)},
+			flows: []string{"check"},
+		},
+		{
+			desc:  "review on draft",
+			event: &github.PullRequestReviewEvent{Action: github.Ptr("submitted"), Repo: repo, PullRequest: pull(true, false)},
+			flows: []string{"check"},
+		},
+		{
+			desc: "comment",
+			event: &github.IssueCommentEvent{
+				Action: github.Ptr("created"),
+				Repo:   repo,
+				Issue: &github.Issue{
+					Number:           github.Ptr(42),
+					User:             &github.User{Login: github.Ptr("dev")},
+					PullRequestLinks: &github.PullRequestLinks{},
+				},
+				Comment: &github.IssueComment{
+					ID:        github.Ptr(int64(7)),
+					Body:      github.Ptr("/backport branch/v17"),
+					User:      &github.User{Login: github.Ptr("admin")},
+					CreatedAt: &github.Timestamp{Time: created},
+					UpdatedAt: &github.Timestamp{Time: created},
+				},
+			},
+			flows: []string{"command"},
+		},
+		{
+			desc: "comment on issue",
+			event: &github.IssueCommentEvent{
+				Action:  github.Ptr("created"),
+				Repo:    repo,
+				Issue:   &github.Issue{Number: github.Ptr(42)},
+				Comment: &github.IssueComment{Body: github.Ptr("/backport branch/v17")},
+			},
+		},
+		{
+			desc:  "other event",
+			event: &github.PushEvent{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			e, flows := route(test.event)
+			var names []string
+			for _, f := range flows {
+				names = append(names, f.name)
+			}
+			require.Equal(t, test.flows, names)
+			if len(flows) == 0 {
+				return
+			}
+			require.Equal(t, "gravitational", e.Organization)
+			require.Equal(t, "teleport", e.Repository)
+			require.Equal(t, 42, e.Number)
+			require.Equal(t, "dev", e.Author)
+		})
+	}
+
+	e, _ := route(tests[0].event)
+	require.Equal(t, "dev/fix", e.UnsafeHead)
+	require.Equal(t, "master", e.UnsafeBase)
+
+	e, _ = route(tests[9].event)
+	require.Equal(t, &env.Comment{
+		ID:         7,
+		User:       env.User{Login: "admin"},
+		UnsafeBody: "/backport branch/v17",
+		CreatedAt:  created,
+		UpdatedAt:  created,
+	}, e.Comment)
+}
+
+// TestSerialization checks that the jobs of a PR run one at a time in order,
+// while the jobs of other PRs run concurrently.
+func TestSerialization(t *testing.T) {
+	s, err := New(Config{
+		Secret:       "secret",
+		Organization: "gravitational",
+		Repository:   "teleport",
+		NewBot: func(e *env.Environment) (*bot.Bot, error) {
+			return nil, nil
+		},
+	})
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	order := make(map[int][]string)
+	running := make(map[int]bool)
+	release := make(chan struct{})
+	otherDone := make(chan struct{})
+
+	record := func(number int, name string) flow {
+		return flow{name: name, run: func(*bot.Bot, context.Context) error {
+			mu.Lock()
+			require.False(t, running[number], "jobs of PR %v overlap", number)
+			running[number] = true
+			order[number] = append(order[number], name)
+			mu.Unlock()
+
+			if name == "first" {
+				<-release
+			}
+			if name == "other" {
+				close(otherDone)
+			}
+
+			mu.Lock()
+			running[number] = false
+			mu.Unlock()
+			return trace.BadParameter("failures don't stop later jobs")
+		}}
+	}
+
+	pull := &env.Environment{Organization: "gravitational", Repository: "teleport", Number: 1}
+	s.enqueue(job{env: pull, flows: []flow{record(1, "first")}})
+	s.enqueue(job{env: pull, flows: []flow{record(1, "second")}})
+	s.enqueue(job{env: pull, flows: []flow{record(1, "third")}})
+
+	// Another PR isn't blocked by the running job.
+	other := &env.Environment{Organization: "gravitational", Repository: "teleport", Number: 2}
+	s.enqueue(job{env: other, flows: []flow{record(2, "other")}})
+	select {
+	case <-otherDone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("job of another PR is blocked")
+	}
+
+	close(release)
+	s.Wait()
+	require.Equal(t, []string{"first", "second", "third"}, order[1])
+	require.Equal(t, []string{"other"}, order[2])
+	require.Empty(t, s.queues)
+}
+
+func TestServeHTTP(t *testing.T) {
+	handled := make(chan *env.Environment, 1)
+	s, err := New(Config{
+		Secret:       "secret",
+		Organization: "gravitational",
+		Repository:   "teleport",
+		NewBot: func(e *env.Environment) (*bot.Bot, error) {
+			handled <- e
+			return nil, trace.BadParameter("no bot in tests")
+		},
+	})
+	require.NoError(t, err)
+
+	payload := `{
+		"action": "submitted",
+		"repository": {"name": "teleport", "owner": {"login": "gravitational"}},
+		"pull_request": {"number": 42, "user": {"login": "dev"}}
+	}`
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(payload))
+
+	deliver := func(signature string) int {
+		r := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(payload))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("X-GitHub-Event", "pull_request_review")
+		r.Header.Set("X-Hub-Signature-256", signature)
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	require.Equal(t, http.StatusBadRequest, deliver("sha256="+strings.Repeat("0", 64)))
+	require.Equal(t, http.StatusOK, deliver("sha256="+hex.EncodeToString(mac.Sum(nil))))
+	s.Wait()
+	e := <-handled
+	require.Equal(t, 42, e.Number)
+	require.Equal(t, "dev", e.Author)
+
+	// Events of other repositories are dropped.
+	s.c.Repository = "teleport.e"
+	require.Equal(t, http.StatusOK, deliver("sha256="+hex.EncodeToString(mac.Sum(nil))))
+	s.Wait()
+	require.Empty(t, handled)
+
+	_, err = New(Config{NewBot: func(*env.Environment) (*bot.Bot, error) { return nil, nil }})
+	require.Error(t, err)
+	_, err = New(Config{Secret: "secret", NewBot: func(*env.Environment) (*bot.Bot, error) { return nil, nil }})
+	require.Error(t, err)
+}
@@ -19,26 +19,76 @@ package main
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"flag"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/gravitational/shared-workflows/bot/internal/bot"
 	"github.com/gravitational/shared-workflows/bot/internal/env"
 	"github.com/gravitational/shared-workflows/bot/internal/github"
 	"github.com/gravitational/shared-workflows/bot/internal/review"
+	"github.com/gravitational/shared-workflows/bot/internal/server"
 
 	"github.com/gravitational/trace"
 )
 
+// appPrivateKeyEnv is the environment variable the private key of the GitHub
+// App is read from when no key file is set.
+const appPrivateKeyEnv = "GITHUB_APP_PRIVATE_KEY"
+
+// webhookSecretEnv is the environment variable the secret of the webhook is
+// read from by the serve command.
+const webhookSecretEnv = "GITHUB_WEBHOOK_SECRET"
+
+// serveCommand runs the bot as a webhook server, it is run as "bot serve"
+// or with -workflow=serve.
+const serveCommand = "serve"
+
+// simulateCommand replays reviewer assignment of past PRs with a candidate
+// reviewers config, it is run as "bot simulate" or with -workflow=simulate.
+const simulateCommand = "simulate"
+
+// validateReviewersCommand checks the reviewers config against a
+// repository, it is run as "bot validate-reviewers" or with
+// -workflow=validate-reviewers.
+const validateReviewersCommand = "validate-reviewers"
+
+// dateLayout is the layout of the dates of the simulate command.
+const dateLayout = "2006-01-02"
+
 func main() {
 	flags, err := parseFlags()
 	if err != nil {
 		log.Fatalf("Failed to parse flags: %#v.", err)
 	}
 
+	if flags.workflow == serveCommand {
+		if err := serve(flags); err != nil {
+			log.Fatalf("Server failed: %v.", err)
+		}
+		return
+	}
+	if flags.workflow == simulateCommand {
+		if err := simulate(flags); err != nil {
+			log.Fatalf("Simulation failed: %v.", err)
+		}
+		return
+	}
+	if flags.workflow == validateReviewersCommand {
+		if err := validateReviewers(flags); err != nil {
+			log.Fatalf("Workflow %v failed: %v.", flags.workflow, err)
+		}
+		log.Printf("Workflow %v complete.", flags.workflow)
+		return
+	}
+
 	// Cancel run if it takes longer than 5 minutes.
 	//
 	// To re-run a job go to the Actions tab in the GitHub repo, go to the run
@@ -58,10 +108,16 @@ func main() {
 		err = b.Assign(ctx)
 	case "check":
 		err = b.Check(ctx)
+	case "nudge":
+		err = b.Nudge(ctx, flags.remindAfter, flags.reassignAfter)
 	case "dismiss":
 		err = b.Dismiss(ctx)
 	case "label":
-		err = b.Label(ctx)
+		if flags.dryRun {
+			err = b.LabelDryRun(ctx, os.Stdout)
+		} else {
+			err = b.Label(ctx)
+		}
 	case "backport":
 		if flags.local {
 			err = b.BackportLocal(ctx, flags.branch)
@@ -69,23 +125,35 @@ func main() {
 			err = b.Backport(ctx)
 		}
 	case "verify":
-		err = b.Verify(ctx)
+		err = b.Verify(ctx, os.Stdout)
 	case "exclude-flakes":
-		err = b.ExcludeFlakes(ctx)
+		err = b.ExcludeFlakes(ctx, os.Stdout)
+	case "propose-quarantine":
+		err = b.ProposeQuarantine(ctx, flags.flakeHistory, os.Stdout)
 	case "binary-sizes":
 		out := base64.NewEncoder(base64.StdEncoding, os.Stdout)
 		defer func() { _ = out.Close() }()
 		err = b.CalculateBinarySizes(ctx, flags.buildDir, flags.artifacts, out)
 	case "bloat":
-		err = b.BloatCheck(ctx, flags.baseStats, flags.buildDir, flags.artifacts, os.Stdout)
+		baseStats := flags.baseStats
+		if flags.baseSource != "" {
+			baseStats, err = b.LoadBaseStats(ctx, flags.baseSource)
+		}
+		if err == nil {
+			err = b.BloatCheck(ctx, baseStats, flags.buildDir, flags.artifacts, os.Stdout)
+		}
 	case "changelog":
 		err = b.CheckChangelog(ctx)
 	case "docpaths":
 		err = b.CheckDocsPathsForMissingRedirects(ctx, flags.teleportClonePath)
+	case "doclinks":
+		err = b.CheckDocsLinks(ctx, flags.teleportClonePath, os.Stdout)
 	case "rfd":
 		err = b.ValidateNewRFD(ctx)
 	case "manual-test-plan":
 		err = b.ValidateManualTestPlan(ctx)
+	case "command":
+		err = b.Command(ctx)
 	default:
 		err = trace.BadParameter("unknown workflow: %v", flags.workflow)
 	}
@@ -101,6 +169,16 @@ type flags struct {
 	workflow string
 	// token is the GitHub auth token.
 	token string
+	// appID is the ID of the GitHub App the bot authenticates as instead of
+	// token.
+	appID int64
+	// appInstallationID is the ID of the installation of the GitHub App,
+	// found from the organization when 0.
+	appInstallationID int64
+	// appPrivateKey is the PEM encoded private key of the GitHub App.
+	appPrivateKey []byte
+	// botLogin is the GitHub login the bot comments as.
+	botLogin string
 	// reviewers is the code reviewers map.
 	reviewers string
 	// local is whether workflow runs locally or in GitHub Actions context.
@@ -117,35 +195,132 @@ type flags struct {
 	artifacts []string
 	// the artifact sizes from the base build.
 	baseStats string
+	// baseSource is the location of the artifact sizes from the base build,
+	// used instead of baseStats.
+	baseSource string
 	// buildDir is an absolute path to a directory containing build artifacts.
 	buildDir string
 	// teleportClonePath is a relative path to a gravitational/teleport
 	// repository clone.
 	teleportClonePath string
+	// codeOwners is the path of a CODEOWNERS file in the repository used to
+	// find preferred reviewers and required approvers.
+	codeOwners string
+	// backportDrafts opens draft backport PRs with conflict markers when a
+	// backport fails with conflicts.
+	backportDrafts bool
+	// backportWait makes backports wait for open backports they depend on
+	// to be merged instead of stacking on them.
+	backportWait bool
+	// bloatConfig is a path to a JSON file with thresholds for bloat.
+	bloatConfig string
+	// labelConfig is the path of the labeler config in the repository.
+	labelConfig string
+	// flakeQuarantine is the location of the flaky test quarantine list.
+	flakeQuarantine string
+	// flakeHistory are globs of ci-normalize JSONL files used to propose
+	// flaky tests to quarantine.
+	flakeHistory []string
+	// dryRun prints the changes a workflow would make instead of making
+	// them.
+	dryRun bool
+	// addr is the address the serve command listens on.
+	addr string
+	// remindAfter and reassignAfter are how long review requests wait
+	// before the nudge workflow reminds reviewers and moves the requests to
+	// other reviewers.
+	remindAfter   time.Duration
+	reassignAfter time.Duration
+	// candidateReviewers is a path to the reviewers config the simulate
+	// command compares with reviewers.
+	candidateReviewers string
+	// prs are the PR numbers the simulate command replays.
+	prs []int
+	// since and until limit the PRs the simulate command replays to the ones
+	// opened in between when prs is empty.
+	since time.Time
+	until time.Time
 }
 
 func parseFlags() (flags, error) {
 	var (
-		workflow          = flag.String("workflow", "", "specific workflow to run [assign, check, dismiss, label, backport, verify, exclude-flakes, binary-sizes, bloat, changelog, docpaths, rfd, manual-test-plan]")
+		workflow          = flag.String("workflow", "", "specific workflow to run [assign, check, dismiss, nudge, label, backport, verify, exclude-flakes, propose-quarantine, binary-sizes, bloat, changelog, docpaths, doclinks, rfd, manual-test-plan, command, serve, simulate, validate-reviewers]")
 		token             = flag.String("token", "", "GitHub authentication token")
+		appID             = flag.Int64("app-id", 0, "ID of the GitHub App to authenticate as instead of -token")
+		appInstallationID = flag.Int64("app-installation-id", 0, "ID of the GitHub App installation (default: the installation on the organization)")
+		appPrivateKeyPath = flag.String("app-private-key", "", "path to the PEM private key of the GitHub App (default: the "+appPrivateKeyEnv+" environment variable)")
+		botLogin          = flag.String("bot-login", "", "GitHub login the bot comments as (default github-actions[bot], required with -app-id: <app-slug>[bot])")
 		reviewers         = flag.String("reviewers", "", "reviewer assignments")
 		local             = flag.Bool("local", false, "local workflow dry run")
-		org               = flag.String("org", "", "GitHub organization (local mode only)")
-		repo              = flag.String("repo", "", "GitHub repository (local mode only)")
+		org               = flag.String("org", "", "GitHub organization (local mode, serve, simulate and validate-reviewers, default: from GITHUB_REPOSITORY)")
+		repo              = flag.String("repo", "", "GitHub repository (local mode, serve, simulate and validate-reviewers, default: from GITHUB_REPOSITORY)")
 		prNumber          = flag.Int("pr", 0, "GitHub pull request number (local mode only)")
-		branch            = flag.String("branch", "", "GitHub backport branch name (local mode only)")
+		branch            = flag.String("branch", "", "GitHub backport branch name (local mode only), or the branch validate-reviewers checks paths on (default: the default branch of the repository)")
 		baseStats         = flag.String("base", "", "the artifact sizes as generated by binary-sizes to compare against for bloat")
+		baseSource        = flag.String("base-source", "", "location of the artifact sizes to compare against for bloat instead of -base [file://path, s3://bucket/key, artifact://workflow/name]")
 		buildDir          = flag.String("builddir", "", "an absolute path to a build directory containing artifacts to be checked for bloat")
 		artifacts         = flag.String("artifacts", "", "a comma separated list of compile artifacts to analyze for bloat")
 		teleportClonePath = flag.String("teleport-path", "", "relative path to a gravitational/teleport clone")
+		codeOwners        = flag.String("codeowners", "", "path of a CODEOWNERS file in the repository used for assign and check, read from the base of the PR")
+		backportDrafts    = flag.Bool("backport-drafts", false, "open draft backport PRs with conflict markers when a backport has conflicts")
+		backportWait      = flag.Bool("backport-wait", false, "wait for open backports of referenced PRs to be merged instead of stacking backports on them")
+		bloatConfig       = flag.String("bloat-config", "", "path to a JSON file with per-artifact thresholds for bloat")
+		labelConfig       = flag.String("label-config", "", "path of the labeler config in the repository (default .github/labeler.yaml)")
+		dryRun            = flag.Bool("dry-run", false, "print the changes instead of making them (label only)")
+		flakeQuarantine   = flag.String("flake-quarantine", "", "location of the flaky test quarantine list, a path in the repository or a file:// or s3:// URL (default .github/flaky-tests.yaml)")
+		flakeHistory      = flag.String("flake-history", "", "a comma separated list of globs of ci-normalize JSONL files (propose-quarantine only)")
+		addr              = flag.String("addr", ":8080", "address to listen on for webhook deliveries (serve only)")
+		remindAfter       = flag.Duration("remind-after", 48*time.Hour, "how long review requests wait before reviewers are reminded (nudge only)")
+		reassignAfter     = flag.Duration("reassign-after", 96*time.Hour, "how long review requests wait before they are moved to another reviewer, 0 never moves them (nudge only)")
+		candidate         = flag.String("candidate-reviewers", "", "path to a reviewers JSON file to compare with -reviewers (simulate only)")
+		prs               = flag.String("prs", "", "a comma separated list of PR numbers to replay (simulate only)")
+		since             = flag.String("since", "", "replay the PRs opened on or after this date, YYYY-MM-DD (simulate only)")
+		until             = flag.String("until", "", "replay the PRs opened on or before this date, YYYY-MM-DD (simulate only, default: today)")
 	)
-	flag.Parse()
+
+	// Commands such as "bot serve" are given before the flags.
+	args := os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		*workflow, args = args[0], args[1:]
+	}
+	if err := flag.CommandLine.Parse(args); err != nil {
+		return flags{}, trace.Wrap(err)
+	}
 
 	if *workflow == "" {
 		return flags{}, trace.BadParameter("workflow missing")
 	}
-	if *token == "" {
-		return flags{}, trace.BadParameter("token missing")
+	var appPrivateKey []byte
+	switch {
+	case *appID == 0 && *token == "":
+		return flags{}, trace.BadParameter("token or app-id missing")
+	case *appID != 0 && *appPrivateKeyPath != "":
+		data, err := os.ReadFile(*appPrivateKeyPath)
+		if err != nil {
+			return flags{}, trace.Wrap(err, "reading App private key")
+		}
+		appPrivateKey = data
+	case *appID != 0:
+		appPrivateKey = []byte(os.Getenv(appPrivateKeyEnv))
+		if len(appPrivateKey) == 0 {
+			return flags{}, trace.BadParameter("app-private-key or %v missing", appPrivateKeyEnv)
+		}
+	}
+	if *appID != 0 && *botLogin == "" {
+		return flags{}, trace.BadParameter("bot-login missing, required with app-id")
+	}
+	// Commands without a workflow event act on the repository of the flags,
+	// or of the workflow run they are run in.
+	if workflowNeedsRepository(*workflow) {
+		if *org == "" && *repo == "" {
+			var err error
+			if *org, *repo, err = env.ReadRepository(); err != nil {
+				return flags{}, trace.Wrap(err, "org and repo missing")
+			}
+		}
+		if *org == "" || *repo == "" {
+			return flags{}, trace.BadParameter("org and repo are required for %v", *workflow)
+		}
 	}
 	if !workflowNeedsReviewers(*workflow) && *reviewers == "" {
 		*reviewers = review.EmptyReviewers
@@ -171,9 +346,38 @@ func parseFlags() (flags, error) {
 		return flags{}, trace.Wrap(err)
 	}
 
+	var numbers []int
+	for _, pr := range strings.Split(*prs, ",") {
+		if pr = strings.TrimPrefix(strings.TrimSpace(pr), "#"); pr == "" {
+			continue
+		}
+		number, err := strconv.Atoi(pr)
+		if err != nil {
+			return flags{}, trace.BadParameter("invalid PR number %q", pr)
+		}
+		numbers = append(numbers, number)
+	}
+	var sinceTime, untilTime time.Time
+	if *since != "" {
+		if sinceTime, err = time.Parse(dateLayout, *since); err != nil {
+			return flags{}, trace.BadParameter("invalid since date %q, expected YYYY-MM-DD", *since)
+		}
+	}
+	if *until != "" {
+		if untilTime, err = time.Parse(dateLayout, *until); err != nil {
+			return flags{}, trace.BadParameter("invalid until date %q, expected YYYY-MM-DD", *until)
+		}
+		// Include the PRs opened on the until date.
+		untilTime = untilTime.AddDate(0, 0, 1)
+	}
+
 	return flags{
 		workflow:          *workflow,
 		token:             *token,
+		appID:             *appID,
+		appInstallationID: *appInstallationID,
+		appPrivateKey:     appPrivateKey,
+		botLogin:          *botLogin,
 		reviewers:         decodedReviewers,
 		local:             *local,
 		org:               *org,
@@ -182,8 +386,25 @@ func parseFlags() (flags, error) {
 		branch:            *branch,
 		artifacts:         strings.Split(*artifacts, ","),
 		baseStats:         string(stats),
+		baseSource:        *baseSource,
 		buildDir:          *buildDir,
 		teleportClonePath: *teleportClonePath,
+		codeOwners:        *codeOwners,
+		backportDrafts:    *backportDrafts,
+		backportWait:      *backportWait,
+		bloatConfig:       *bloatConfig,
+		labelConfig:       *labelConfig,
+		dryRun:            *dryRun,
+		flakeQuarantine:   *flakeQuarantine,
+		flakeHistory:      strings.Split(*flakeHistory, ","),
+		addr:              *addr,
+		remindAfter:       *remindAfter,
+		reassignAfter:     *reassignAfter,
+
+		candidateReviewers: *candidate,
+		prs:                numbers,
+		since:              sinceTime,
+		until:              untilTime,
 	}, nil
 }
 
@@ -191,22 +412,35 @@ func createBot(ctx context.Context, flags flags) (*bot.Bot, error) {
 	if flags.local {
 		return createBotLocal(ctx, flags)
 	}
-	gh, err := github.New(ctx, flags.token)
+	environment, err := env.New()
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
-	environment, err := env.New()
+	gh, err := newGitHubClient(ctx, flags, environment.Organization)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
-	reviewer, err := review.FromString(flags.reviewers)
+	reviewer, err := newAssignments(flags)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	bloat, err := loadBloatConfig(flags)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
 	b, err := bot.New(&bot.Config{
 		GitHub:      gh,
+		Login:       flags.botLogin,
 		Environment: environment,
 		Review:      reviewer,
+		Bloat:       bloat,
+
+		LabelConfigPath:             flags.labelConfig,
+		CodeOwnersPath:              flags.codeOwners,
+		FlakeQuarantine:             flags.flakeQuarantine,
+		GitToken:                    gitToken(flags, gh),
+		DraftBackportOnConflict:     flags.backportDrafts,
+		WaitForBackportDependencies: flags.backportWait,
 	})
 	if err != nil {
 		return nil, trace.Wrap(err)
@@ -214,28 +448,251 @@ func createBot(ctx context.Context, flags flags) (*bot.Bot, error) {
 	return b, nil
 }
 
-// createBotLocal creates a local instance of the bot that can be run locally
-// instead of inside GitHub Actions environment.
-func createBotLocal(ctx context.Context, flags flags) (*bot.Bot, error) {
-	gh, err := github.New(ctx, flags.token)
+// newAssignments returns the reviewer assignments of the reviewers flag.
+func newAssignments(flags flags) (*review.Assignments, error) {
+	reviewer, err := review.FromString(flags.reviewers)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
-	return bot.New(&bot.Config{
+	return reviewer, nil
+}
+
+// loadBloatConfig reads the bloat thresholds, nil uses the defaults.
+func loadBloatConfig(flags flags) (*bot.BloatConfig, error) {
+	if flags.bloatConfig == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(flags.bloatConfig)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	bloat, err := bot.ParseBloatConfig(data)
+	if err != nil {
+		return nil, trace.Wrap(err, "loading bloat config from %v", flags.bloatConfig)
+	}
+	return bloat, nil
+}
+
+// serve runs the bot as a webhook server until it is interrupted. Every
+// event gets its own bot, which reports the result of Check as a commit
+// status as there is no workflow run.
+func serve(flags flags) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	gh, err := newGitHubClient(ctx, flags, flags.org)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	// Check the reviewers before receiving events.
+	if _, err := newAssignments(flags); err != nil {
+		return trace.Wrap(err)
+	}
+	srv, err := server.New(server.Config{
+		Secret:       os.Getenv(webhookSecretEnv),
+		Organization: flags.org,
+		Repository:   flags.repo,
+		NewBot: func(e *env.Environment) (*bot.Bot, error) {
+			// Assignments pick reviewers with a random source that can't be
+			// shared between concurrent events.
+			reviewer, err := newAssignments(flags)
+			if err != nil {
+				return nil, trace.Wrap(err)
+			}
+			return bot.New(&bot.Config{
+				GitHub:      gh,
+				Login:       flags.botLogin,
+				Environment: e,
+				Review:      reviewer,
+
+				LabelConfigPath:             flags.labelConfig,
+				CodeOwnersPath:              flags.codeOwners,
+				GitToken:                    gitToken(flags, gh),
+				DraftBackportOnConflict:     flags.backportDrafts,
+				WaitForBackportDependencies: flags.backportWait,
+				ReportCheckStatus:           true,
+			})
+		},
+	})
+	if err != nil {
+		return trace.Wrap(err, "missing %v, -org or -repo?", webhookSecretEnv)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("POST /webhook", srv)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	httpServer := &http.Server{
+		Addr:              flags.addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Failed to shut down server: %v.", err)
+		}
+	}()
+
+	log.Printf("Listening for webhook deliveries on %v.", flags.addr)
+	if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return trace.Wrap(err)
+	}
+	// Let the running flows finish, like workflow runs aren't interrupted.
+	srv.Wait()
+	return nil
+}
+
+// simulate replays reviewer assignment and the approval checks of past PRs
+// with the reviewers and the candidate reviewers, and writes a report of the
+// differences to stdout.
+func simulate(flags flags) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if flags.candidateReviewers == "" {
+		return trace.BadParameter("candidate-reviewers missing")
+	}
+	data, err := os.ReadFile(flags.candidateReviewers)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	current, err := newAssignments(flags)
+	if err != nil {
+		return trace.Wrap(err, "loading reviewers")
+	}
+	candidateFlags := flags
+	candidateFlags.reviewers = string(data)
+	candidate, err := newAssignments(candidateFlags)
+	if err != nil {
+		return trace.Wrap(err, "loading candidate reviewers from %v", flags.candidateReviewers)
+	}
+
+	gh, err := newGitHubClient(ctx, flags, flags.org)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	b, err := bot.New(&bot.Config{
+		GitHub: gh,
+		Login:  flags.botLogin,
+		Environment: &env.Environment{
+			Organization: flags.org,
+			Repository:   flags.repo,
+		},
+		Review:          current,
+		LabelConfigPath: flags.labelConfig,
+		CodeOwnersPath:  flags.codeOwners,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(b.Simulate(ctx, candidate, flags.prs, flags.since, flags.until, os.Stdout))
+}
+
+// validateReviewers checks the reviewers config against the repository
+// reviews are assigned in, which doesn't need a workflow event.
+func validateReviewers(flags flags) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	reviewer, err := newAssignments(flags)
+	if err != nil {
+		return trace.Wrap(err, "loading reviewers")
+	}
+	gh, err := newGitHubClient(ctx, flags, flags.org)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	b, err := bot.New(&bot.Config{
 		GitHub: gh,
+		Login:  flags.botLogin,
 		Environment: &env.Environment{
 			Organization: flags.org,
 			Repository:   flags.repo,
-			Number:       flags.prNumber,
 		},
+		Review: reviewer,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(b.ValidateReviewers(ctx, flags.reviewers, flags.branch, os.Stdout))
+}
+
+// createBotLocal creates a local instance of the bot that can be run locally
+// instead of inside GitHub Actions environment.
+func createBotLocal(ctx context.Context, flags flags) (*bot.Bot, error) {
+	gh, err := newGitHubClient(ctx, flags, flags.org)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	environment := &env.Environment{
+		Organization: flags.org,
+		Repository:   flags.repo,
+		Number:       flags.prNumber,
+	}
+	if flags.prNumber != 0 {
+		pull, err := gh.GetPullRequest(ctx, flags.org, flags.repo, flags.prNumber)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		environment.Author = pull.Author
+		environment.UnsafeBase = pull.UnsafeBase.Ref
+		environment.UnsafeHead = pull.UnsafeHead.Ref
+	}
+	return bot.New(&bot.Config{
+		GitHub:          gh,
+		Login:           flags.botLogin,
+		Environment:     environment,
+		LabelConfigPath: flags.labelConfig,
+		FlakeQuarantine: flags.flakeQuarantine,
 	})
 }
 
+// newGitHubClient returns a GitHub client that authenticates as the GitHub
+// App if one is set, otherwise with the token.
+func newGitHubClient(ctx context.Context, flags flags, organization string) (*github.Client, error) {
+	if flags.appID == 0 {
+		return github.New(ctx, flags.token)
+	}
+	gh, err := github.NewForApp(ctx, github.AppConfig{
+		AppID:          flags.appID,
+		InstallationID: flags.appInstallationID,
+		Organization:   organization,
+		PrivateKey:     flags.appPrivateKey,
+	})
+	return gh, trace.Wrap(err)
+}
+
+// gitToken returns the token git authenticates with. Installation tokens
+// of GitHub Apps expire after an hour, so git uses fresh tokens from the
+// client. With a personal token, git uses the credentials of the checkout.
+func gitToken(flags flags, gh *github.Client) func() (string, error) {
+	if flags.appID == 0 {
+		return nil
+	}
+	return gh.Token
+}
+
+// workflowNeedsRepository checks whether the workflow is one that has no
+// workflow event and reads the repository from the flags: serve, simulate or
+// validate-reviewers.
+func workflowNeedsRepository(workflow string) bool {
+	switch workflow {
+	case serveCommand, simulateCommand, validateReviewersCommand:
+		return true
+	}
+	return false
+}
+
 // workflowRequiresReviewers checks whether the workflow is one that uses the
-// reviewers flag value: assign, bloat, check, exclude-flakes or rfd
+// reviewers flag value: assign, bloat, check, command, exclude-flakes, nudge,
+// rfd, serve, simulate or validate-reviewers
 func workflowNeedsReviewers(workflow string) bool {
 	switch workflow {
-	case "assign", "backport", "bloat", "check", "exclude-flakes", "rfd":
+	case "assign", "backport", "bloat", "check", "command", "exclude-flakes", "nudge", "rfd", serveCommand, simulateCommand, validateReviewersCommand:
 		return true
 	}
 	return false
@@ -79,6 +79,11 @@ func TestParseFlagsWorkflowNeedsReviewers(t *testing.T) {
 			workflow: "check",
 			wantErr:  true,
 		},
+		{
+			desc:     "command without reviewers",
+			workflow: "command",
+			wantErr:  true,
+		},
 		{
 			desc:     "exclude-flakes without reviewers",
 			workflow: "exclude-flakes",
@@ -203,3 +208,57 @@ func TestParseFlagsWorkflowNeedsReviewers(t *testing.T) {
 		})
 	}
 }
+
+func TestParseFlagsWorkflowNeedsRepository(t *testing.T) {
+	const dummyReviewers = `{"core":{}}`
+
+	tests := []struct {
+		desc     string
+		args     []string
+		env      string
+		wantErr  bool
+		wantOrg  string
+		wantRepo string
+	}{
+		{
+			desc:     "flags",
+			args:     []string{"-org", "gravitational", "-repo", "teleport"},
+			env:      "other/repo",
+			wantOrg:  "gravitational",
+			wantRepo: "teleport",
+		},
+		{
+			desc:     "workflow run repository",
+			env:      "gravitational/teleport",
+			wantOrg:  "gravitational",
+			wantRepo: "teleport",
+		},
+		{
+			desc:    "missing repository",
+			wantErr: true,
+		},
+		{
+			desc:    "missing repo flag",
+			args:    []string{"-org", "gravitational"},
+			env:     "gravitational/teleport",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			t.Setenv("GITHUB_REPOSITORY", tt.env)
+			os.Args = append([]string{os.Args[0], "validate-reviewers", "-token", "test-token", "-reviewers", dummyReviewers}, tt.args...)
+
+			got, err := parseFlags()
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOrg, got.org)
+			assert.Equal(t, tt.wantRepo, got.repo)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package changelog holds the changelog conventions shared by the bot, which
+// checks the changelog entries of PRs, and the changelog tool, which renders
+// them.
+package changelog
+
+// Category is a category a changelog entry can be filed under with
+// "changelog(<name>): ".
+type Category struct {
+	// Name is the name of the category in changelog entries.
+	Name string
+	// Title is the title of the section of the category in the rendered
+	// changelog.
+	Title string
+}
+
+// Categories are the changelog categories in the order they are rendered.
+var Categories = []Category{
+	{Name: "security", Title: "Security fixes"},
+	{Name: "breaking", Title: "Breaking changes"},
+	{Name: "feature", Title: "New features"},
+	{Name: "fix", Title: "Fixes"},
+	{Name: "improvement", Title: "Improvements"},
+	{Name: "deprecation", Title: "Deprecations"},
+}
+
+// CategoryNames returns the names of the categories in order.
+func CategoryNames() []string {
+	names := make([]string, 0, len(Categories))
+	for _, c := range Categories {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+// IsCategory returns true if name is a known category.
+func IsCategory(name string) bool {
+	for _, c := range Categories {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
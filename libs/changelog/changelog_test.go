@@ -0,0 +1,30 @@
+/*
+Copyright 2026 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCategories(t *testing.T) {
+	require.Equal(t, []string{"security", "breaking", "feature", "fix", "improvement", "deprecation"}, CategoryNames())
+	require.True(t, IsCategory("fix"))
+	require.False(t, IsCategory("Fix"))
+	require.False(t, IsCategory(""))
+}
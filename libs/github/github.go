@@ -97,6 +97,48 @@ func NewForApp(ctx context.Context, appID, installationID int64, privateKey []by
 	}, nil
 }
 
+// NewInstallationTokenSource returns a token source of the installation access tokens of a GitHub App, for clients
+// that don't use this package, such as git. Tokens are cached and recreated shortly before they expire.
+// When installationID is 0, the installation of the App on organization is used.
+func NewInstallationTokenSource(ctx context.Context, appID, installationID int64, organization string, privateKey []byte) (oauth2.TokenSource, error) {
+	if installationID == 0 {
+		appsClient, err := newJWTClient(appID, privateKey)
+		if err != nil {
+			return nil, fmt.Errorf("creating JWT client: %w", err)
+		}
+		installation, _, err := appsClient.Apps.FindOrganizationInstallation(ctx, organization)
+		if err != nil {
+			return nil, fmt.Errorf("finding the installation of App %d on %s: %w", appID, organization, err)
+		}
+		installationID = installation.GetID()
+	}
+
+	tr, err := newAppTransport(ctx, appID, installationID, privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("creating client transport: %w", err)
+	}
+	return &installationTokenSource{ctx: ctx, tr: tr}, nil
+}
+
+// installationTokenSource implements oauth2.TokenSource with the tokens of an installationAuthTransport.
+type installationTokenSource struct {
+	ctx context.Context
+	tr  *installationAuthTransport
+}
+
+// Token returns the current installation access token.
+func (s *installationTokenSource) Token() (*oauth2.Token, error) {
+	token, err := s.tr.getToken(s.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting access token: %w", err)
+	}
+	return &oauth2.Token{
+		AccessToken: token.GetToken(),
+		TokenType:   "Bearer",
+		Expiry:      token.GetExpiresAt().Time,
+	}, nil
+}
+
 // installationAuthTransport is a middleware that adds GitHub App authentication to HTTP requests.
 // It implements the http.RoundTripper interface, allowing it to be used as a transport for the underlying HTTP client passed to the GitHub client.
 //
@@ -203,6 +245,15 @@ func (a *installationAuthTransport) RoundTrip(orig *http.Request) (*http.Respons
 }
 
 func (a *installationAuthTransport) getAccessToken(ctx context.Context) (string, error) {
+	token, err := a.getToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	return token.GetToken(), nil
+}
+
+// getToken returns the cached installation token, creating a new one if it is missing or about to expire.
+func (a *installationAuthTransport) getToken(ctx context.Context) (*go_github.InstallationToken, error) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
@@ -212,12 +263,12 @@ func (a *installationAuthTransport) getAccessToken(ctx context.Context) (string,
 	if a.token == nil || a.token.GetToken() == "" || time.Now().After(expiryWithBuffer) {
 		newToken, err := a.createAccessToken(ctx)
 		if err != nil {
-			return "", fmt.Errorf("creating access token: %w", err)
+			return nil, fmt.Errorf("creating access token: %w", err)
 		}
 		a.token = newToken
 	}
 
-	return a.token.GetToken(), nil
+	return a.token, nil
 }
 
 func (a *installationAuthTransport) createAccessToken(ctx context.Context) (*go_github.InstallationToken, error) {
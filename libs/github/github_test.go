@@ -0,0 +1,43 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallationTokenSource(t *testing.T) {
+	issued := 0
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /app/installations/42/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		issued++
+		json.NewEncoder(w).Encode(map[string]any{
+			"token":      fmt.Sprintf("token-%d", issued),
+			"expires_at": expiresAt,
+		})
+	})
+	apps, closer := newFakeClient(mux)
+	t.Cleanup(closer)
+
+	ts := &installationTokenSource{
+		ctx: context.Background(),
+		tr:  &installationAuthTransport{appsClient: apps.client, installationID: 42},
+	}
+
+	token, err := ts.Token()
+	require.NoError(t, err)
+	require.Equal(t, "token-1", token.AccessToken)
+	require.True(t, expiresAt.Equal(token.Expiry))
+
+	// The token is reused until it is about to expire.
+	token, err = ts.Token()
+	require.NoError(t, err)
+	require.Equal(t, "token-1", token.AccessToken)
+	require.Equal(t, 1, issued)
+}
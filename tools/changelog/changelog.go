@@ -24,6 +24,7 @@ import (
 	"text/template"
 	"unicode"
 
+	"github.com/gravitational/shared-workflows/libs/changelog"
 	"github.com/gravitational/shared-workflows/libs/github"
 	"github.com/gravitational/trace"
 )
@@ -34,22 +35,51 @@ type entry struct {
 	Summary string
 	Number  int
 	URL     string
+	// Category is the category of the entry, empty if it has none.
+	Category string
 }
 
+// section is a group of entries of the same category in the rendered
+// changelog.
+type section struct {
+	// Title is empty when no entry has a category.
+	Title   string
+	Entries []entry
+}
+
+// otherTitle is the title of the section of entries without a known
+// category.
+const otherTitle = "Other changes"
+
 var (
-	// clPattern matches a "changelog: <summary>" line, capturing the summary.
-	clPattern = regexp.MustCompile(`[Cc]hangelog: +(.*)`)
+	// clPattern matches a "changelog(<category>)[<scope>]: <summary>" line,
+	// capturing the optional category and scope and the summary.
+	clPattern = regexp.MustCompile(`[Cc]hangelog(?:\(([^)]*)\))?(?:\[([^\]]*)\])?: +(.*)`)
 
 	// tmplLinks renders entries with a link to the PR; tmplNoLinks without.
 	tmplLinks = template.Must(template.New("cl").Parse(`
-{{- range . -}}
+{{- range $i, $s := . -}}
+{{- if $i }}
+{{ end -}}
+{{- with .Title }}### {{ . }}
+
+{{ end -}}
+{{- range .Entries -}}
 * {{.Summary}} [#{{.Number}}]({{.URL}})
 {{ end -}}
+{{- end -}}
 `))
 	tmplNoLinks = template.Must(template.New("cl").Parse(`
-{{- range . -}}
+{{- range $i, $s := . -}}
+{{- if $i }}
+{{ end -}}
+{{- with .Title }}### {{ . }}
+
+{{ end -}}
+{{- range .Entries -}}
 * {{.Summary}}
 {{ end -}}
+{{- end -}}
 `))
 )
 
@@ -79,7 +109,7 @@ func (g *generator) render(prs []github.PullRequest) (string, error) {
 	}
 
 	var buf bytes.Buffer
-	if err := g.tmpl.Execute(&buf, entries); err != nil {
+	if err := g.tmpl.Execute(&buf, groupEntries(entries)); err != nil {
 		return "", trace.Wrap(err)
 	}
 
@@ -90,15 +120,55 @@ func (g *generator) render(prs []github.PullRequest) (string, error) {
 func entriesFromPR(pr github.PullRequest) []entry {
 	var entries []entry
 	for _, m := range clPattern.FindAllStringSubmatch(pr.Body, -1) {
+		summary := formatSummary(m[3])
+		if scope := strings.TrimSpace(m[2]); scope != "" {
+			summary = scope + ": " + summary
+		}
 		entries = append(entries, entry{
-			Summary: formatSummary(m[1]),
-			Number:  pr.Number,
-			URL:     pr.URL,
+			Summary:  summary,
+			Number:   pr.Number,
+			URL:      pr.URL,
+			Category: strings.ToLower(strings.TrimSpace(m[1])),
 		})
 	}
 	return entries
 }
 
+// groupEntries groups the entries into sections by category, keeping the
+// order of the entries within a section. Entries without a known category
+// go last. If no entry has a category, a single untitled section is
+// returned.
+func groupEntries(entries []entry) []section {
+	var sections []section
+	grouped := 0
+	for _, category := range changelog.Categories {
+		s := section{Title: category.Title}
+		for _, e := range entries {
+			if e.Category == category.Name {
+				s.Entries = append(s.Entries, e)
+			}
+		}
+		if len(s.Entries) > 0 {
+			sections = append(sections, s)
+			grouped += len(s.Entries)
+		}
+	}
+	if grouped == len(entries) {
+		return sections
+	}
+
+	other := section{}
+	if grouped > 0 {
+		other.Title = otherTitle
+	}
+	for _, e := range entries {
+		if !changelog.IsCategory(e.Category) {
+			other.Entries = append(other.Entries, e)
+		}
+	}
+	return append(sections, other)
+}
+
 func formatSummary(s string) string {
 	s = strings.TrimSpace(s)
 	// Appending the period first guarantees s is non-empty for r[0] below.
@@ -39,24 +39,33 @@ func decodeTestData(t *testing.T, data []byte) []github.PullRequest {
 func TestRender(t *testing.T) {
 	testCases := []struct {
 		name         string
+		prsFile      string
 		expectedFile string
 		tmpl         *template.Template
 	}{
 		{
 			name:         "include-links",
+			prsFile:      "listed-prs.json",
 			expectedFile: "expected-cl.md",
 			tmpl:         tmplLinks,
 		},
 		{
 			name:         "exclude-links",
+			prsFile:      "listed-prs.json",
 			expectedFile: "expected-cl-no-links.md",
 			tmpl:         tmplNoLinks,
 		},
+		{
+			name:         "categories",
+			prsFile:      "categorized-prs.json",
+			expectedFile: "expected-cl-categorized.md",
+			tmpl:         tmplLinks,
+		},
 	}
 
 	for _, tt := range testCases {
 		t.Run(tt.name, func(t *testing.T) {
-			prsText, err := os.ReadFile(filepath.Join("testdata", "listed-prs.json"))
+			prsText, err := os.ReadFile(filepath.Join("testdata", tt.prsFile))
 			require.NoError(t, err)
 			expectedCL, err := os.ReadFile(filepath.Join("testdata", tt.expectedFile))
 			require.NoError(t, err)